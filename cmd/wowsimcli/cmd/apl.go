@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/cata/sim/core/apltext"
+	"github.com/wowsims/cata/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var aplCmd = &cobra.Command{
+	Use:   "apl <file>",
+	Short: "convert a rotation between the text APL syntax and JSON",
+	Long:  "convert a rotation between the text APL syntax and JSON. Files ending in .json (APLRotation in protojson format) are printed as text, anything else is parsed as text and printed as JSON",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.HasSuffix(args[0], ".json") {
+			rotation, err := loadTextAPL(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(protojson.Format(rotation))
+			return nil
+		}
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		rotation := &proto.APLRotation{}
+		if err := protojson.Unmarshal(data, rotation); err != nil {
+			return fmt.Errorf("failed to load APL json file: %w", err)
+		}
+		fmt.Print(apltext.Format(rotation))
+		return nil
+	},
+}
+
+func loadTextAPL(filename string) (*proto.APLRotation, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load APL file %q: %w", filename, err)
+	}
+	rotation, err := apltext.Parse(string(data))
+	if err != nil {
+		// Parse errors start with line:col, so this reads like a compiler error.
+		return nil, fmt.Errorf("%s:%w", filename, err)
+	}
+	return rotation, nil
+}
+
+func firstPlayer(raid *proto.Raid) *proto.Player {
+	for _, party := range raid.GetParties() {
+		for _, player := range party.GetPlayers() {
+			if player.GetClass() != proto.Class_ClassUnknown {
+				return player
+			}
+		}
+	}
+	return nil
+}
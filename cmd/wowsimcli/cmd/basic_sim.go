@@ -8,9 +8,18 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/wowsims/cata/sim/core"
 	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/simsettings"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+var (
+	replaySeed int64
+	logfile    string
+	link       string
+	batchfile  string
+	aplfile    string
+)
+
 var simCmd = &cobra.Command{
 	Use:   "sim",
 	Short: "simulate items & settings",
@@ -21,37 +30,73 @@ func init() {
 	simCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
 	simCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
 	simCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
-	simCmd.MarkFlagRequired("infile")
+	simCmd.Flags().Int64Var(&replaySeed, "replay-seed", 0, "run only the iteration with this seed (e.g. a min_seed / max_seed from a previous result) with debug logging")
+	simCmd.Flags().StringVar(&logfile, "logfile", "", "location to write the debug log to, in addition to the output")
+	simCmd.Flags().StringVar(&link, "link", "", "wowsims share link to sim instead of an input file")
+	simCmd.Flags().StringVar(&batchfile, "batch", "", "location of a file with one share link per line. Writes a CSV summary of each sim instead of JSON")
+	simCmd.Flags().StringVar(&aplfile, "apl", "", "location of a rotation in the text APL syntax, replacing the rotation of the first player in the raid")
+	simCmd.MarkFlagsMutuallyExclusive("infile", "link", "batch")
+	simCmd.MarkFlagsMutuallyExclusive("batch", "replay-seed")
+	simCmd.MarkFlagsMutuallyExclusive("batch", "logfile")
+	simCmd.MarkFlagsMutuallyExclusive("batch", "apl")
 }
 
 func simMain(cmd *cobra.Command, args []string) {
-	data, err := os.ReadFile(infile)
-	if err != nil {
-		log.Fatalf("failed to load input json file %q: %v", infile, err)
+	if batchfile != "" {
+		batchSimMain()
+		return
 	}
-	input := &proto.RaidSimRequest{}
 
-	err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, input)
-	if err != nil {
-		log.Fatalf("failed to load input json file: %s", err)
-	}
+	var input *proto.RaidSimRequest
+	if link != "" {
+		settings, err := simsettings.DecodeLink(link)
+		if err != nil {
+			log.Fatalf("failed to load link: %s", err)
+		}
+		input, err = simsettings.ToRequest(settings)
+		if err != nil {
+			log.Fatalf("failed to load link: %s", err)
+		}
+	} else {
+		data, err := os.ReadFile(infile)
+		if err != nil {
+			log.Fatalf("failed to load input json file %q: %v", infile, err)
+		}
+		input = &proto.RaidSimRequest{}
 
-	var output []byte
-	reporter := make(chan *proto.ProgressMetrics, 10)
-	core.RunRaidSimAsync(input, reporter)
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, input)
+		if err != nil {
+			log.Fatalf("failed to load input json file: %s", err)
+		}
+	}
 
-	var finalResult *proto.RaidSimResult
-	for v := range reporter {
-		if v.FinalRaidResult != nil {
-			finalResult = v.FinalRaidResult
-			break
+	if aplfile != "" {
+		rotation, err := loadTextAPL(aplfile)
+		if err != nil {
+			log.Fatal(err)
 		}
-		if verbose {
-			fmt.Printf("Sim Progress: %d / %d\n", v.CompletedIterations, v.TotalIterations)
+		player := firstPlayer(input.Raid)
+		if player == nil {
+			log.Fatalf("--apl requires a player in the raid")
+		}
+		player.Rotation = rotation
+	}
+
+	if cmd.Flags().Changed("replay-seed") {
+		input.SimOptions.ReplayIteration = true
+		input.SimOptions.ReplaySeed = replaySeed
+	}
+
+	finalResult := runSim(input)
+
+	if logfile != "" {
+		err := os.WriteFile(logfile, []byte(finalResult.Logs), 0666)
+		if err != nil {
+			log.Fatalf("failed to write log file: %s", err)
 		}
 	}
 
-	output, err = protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(finalResult)
+	output, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(finalResult)
 	if err != nil {
 		log.Fatalf("failed to marshal final results: %s", err)
 	}
@@ -68,3 +113,18 @@ func simMain(cmd *cobra.Command, args []string) {
 		}
 	}
 }
+
+func runSim(input *proto.RaidSimRequest) *proto.RaidSimResult {
+	reporter := make(chan *proto.ProgressMetrics, 10)
+	core.RunRaidSimAsync(input, reporter)
+
+	for v := range reporter {
+		if v.FinalRaidResult != nil {
+			return v.FinalRaidResult
+		}
+		if verbose {
+			fmt.Printf("Sim Progress: %d / %d\n", v.CompletedIterations, v.TotalIterations)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/simsettings"
+)
+
+var batchHeader = []string{"line", "name", "dps", "dps_stdev", "hps", "hps_stdev", "tps", "tps_stdev", "error"}
+
+func batchSimMain() {
+	data, err := os.ReadFile(batchfile)
+	if err != nil {
+		log.Fatalf("failed to load batch file %q: %v", batchfile, err)
+	}
+
+	out := io.Writer(os.Stdout)
+	if outfile != "" {
+		f, err := os.Create(outfile)
+		if err != nil {
+			log.Fatalf("failed to create output file: %s", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	w := csv.NewWriter(out)
+	w.Write(batchHeader)
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Simming line %d\n", i+1)
+		}
+		w.Write(batchSimLink(i+1, line))
+		w.Flush()
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalf("failed to write output file: %s", err)
+	}
+	if verbose && outfile != "" {
+		fmt.Printf("Wrote output file: `%s` successfully.\n", outfile)
+	}
+}
+
+// Sims a single link and returns its CSV row. Failures are reported in the
+// error column so one bad link doesn't stop the batch.
+func batchSimLink(lineNum int, link string) []string {
+	row := make([]string, len(batchHeader))
+	row[0] = strconv.Itoa(lineNum)
+
+	settings, err := simsettings.DecodeLink(link)
+	if err != nil {
+		row[len(row)-1] = err.Error()
+		return row
+	}
+	input, err := simsettings.ToRequest(settings)
+	if err != nil {
+		row[len(row)-1] = err.Error()
+		return row
+	}
+
+	var names []string
+	for _, party := range input.Raid.Parties {
+		for _, player := range party.Players {
+			if player.Class != proto.Class_ClassUnknown {
+				names = append(names, player.Name)
+			}
+		}
+	}
+	row[1] = strings.Join(names, ";")
+
+	result := runSim(input)
+	if result.ErrorResult != "" {
+		// Only keep the message, not the stack trace.
+		row[len(row)-1], _, _ = strings.Cut(result.ErrorResult, "\n")
+		return row
+	}
+
+	// Threat isn't aggregated per raid, so sum each player's. The stdev is
+	// only reported for a single player.
+	var playerMetrics []*proto.UnitMetrics
+	for _, party := range result.RaidMetrics.Parties {
+		playerMetrics = append(playerMetrics, party.Players...)
+	}
+	var tps, tpsStdev float64
+	for _, player := range playerMetrics {
+		tps += player.Threat.GetAvg()
+	}
+	if len(playerMetrics) == 1 {
+		tpsStdev = playerMetrics[0].Threat.GetStdev()
+	}
+
+	formatFloat := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	row[2] = formatFloat(result.RaidMetrics.Dps.GetAvg())
+	row[3] = formatFloat(result.RaidMetrics.Dps.GetStdev())
+	row[4] = formatFloat(result.RaidMetrics.Hps.GetAvg())
+	row[5] = formatFloat(result.RaidMetrics.Hps.GetStdev())
+	row[6] = formatFloat(tps)
+	row[7] = formatFloat(tpsStdev)
+	return row
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var coverageJSON bool
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "list items, enchants and set bonuses whose effects are not simulated",
+	Long:  "list items, meta gems, enchants without stats and set bonuses in the database that have no registered effect in the sim",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result := core.EffectCoverage(&proto.EffectCoverageRequest{})
+		if coverageJSON {
+			fmt.Println(protojson.Format(result))
+			return nil
+		}
+
+		for _, effect := range result.Unimplemented {
+			source := strings.TrimPrefix(effect.Source.String(), "EffectSource")
+			if effect.Source == proto.EffectSource_EffectSourceItemSet {
+				fmt.Printf("%s\t%s (%dpc)\t%v\n", source, effect.Name, effect.NumPieces, effect.ItemIds)
+			} else {
+				fmt.Printf("%s\t%d\t%s\n", source, effect.Id, effect.Name)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	coverageCmd.Flags().BoolVar(&coverageJSON, "json", false, "print the report as EffectCoverageResult in protojson format")
+}
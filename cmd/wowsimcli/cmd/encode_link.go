@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/simsettings"
+	"google.golang.org/protobuf/encoding/protojson"
+	goproto "google.golang.org/protobuf/proto"
+)
+
+var baseURL string
+
+var encodeLinkCmd = &cobra.Command{
+	Use:   "encodelink",
+	Short: "encode a sim request as a wowsims link/url",
+	Long:  "encode a sim request as a wowsims link/url. Requests with a single player produce an individual sim link, anything else a raid sim link",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(infile)
+		if err != nil {
+			return fmt.Errorf("failed to load input json file %q: %w", infile, err)
+		}
+		input := &proto.RaidSimRequest{}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, input); err != nil {
+			return fmt.Errorf("failed to load input json file: %w", err)
+		}
+
+		link, err := encodeLink(simsettings.RequestToSettings(input), baseURL)
+		if err != nil {
+			return err
+		}
+		fmt.Println(link)
+		return nil
+	},
+}
+
+func init() {
+	encodeLinkCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
+	encodeLinkCmd.Flags().StringVar(&baseURL, "base-url", "https://wowsims.github.io/cata/", "url of the sim site, the sim's path is appended to it")
+}
+
+// Encodes settings the same way the UI's link exporters do, so the link
+// opens in the matching sim.
+func encodeLink(settings goproto.Message, baseURL string) (string, error) {
+	path, err := linkPath(settings)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := simsettings.Encode(settings)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return baseURL + path + "#" + encoded, nil
+}
+
+var camelCaseBoundary = regexp.MustCompile("([a-z])([A-Z])")
+
+func snakeCase(name string) string {
+	return strings.ToLower(camelCaseBoundary.ReplaceAllString(name, "${1}_${2}"))
+}
+
+// Returns the path of the sim that loads these settings, e.g.
+// "death_knight/blood/" or "raid/".
+func linkPath(settings goproto.Message) (string, error) {
+	switch settings := settings.(type) {
+	case *proto.IndividualSimSettings:
+		player := settings.GetPlayer()
+		if player.GetSpec() == nil {
+			return "", fmt.Errorf("player has no spec")
+		}
+		className := strings.TrimPrefix(player.Class.String(), "Class")
+		specName := strings.TrimSuffix(strings.TrimPrefix(simsettings.PlayerSpec(player).String(), "Spec"), className)
+		return snakeCase(className) + "/" + snakeCase(specName) + "/", nil
+	case *proto.RaidSimSettings:
+		return "raid/", nil
+	}
+	return "", fmt.Errorf("unsupported settings type %T", settings)
+}
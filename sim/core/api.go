@@ -60,3 +60,11 @@ func RunBulkSim(request *proto.BulkSimRequest) *proto.BulkSimResult {
 func RunBulkSimAsync(ctx context.Context, request *proto.BulkSimRequest, progress chan *proto.ProgressMetrics) {
 	go BulkSim(ctx, request, progress)
 }
+
+func RunGearSearch(request *proto.GearSearchRequest) *proto.GearSearchResult {
+	return GearSearch(context.Background(), request, nil)
+}
+
+func RunBossLoot(request *proto.BossLootRequest) *proto.BossLootResult {
+	return BossLoot(context.Background(), request, nil)
+}
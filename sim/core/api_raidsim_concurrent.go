@@ -43,6 +43,39 @@ func (rsrc *raidSimResultCombiner) newUnitMetrics(baseUnit *proto.UnitMetrics) *
 		Auras:     make([]*proto.AuraMetrics, len(baseUnit.Auras)),
 		Resources: make([]*proto.ResourceMetrics, 0, len(baseUnit.Resources)),
 		Pets:      make([]*proto.UnitMetrics, len(baseUnit.Pets)),
+
+		DamageTaken:        make([]*proto.DamageTakenMetrics, 0, len(baseUnit.DamageTaken)),
+		CooldownMitigation: make([]*proto.CooldownMitigationMetrics, len(baseUnit.CooldownMitigation)),
+	}
+
+	for i, cm := range baseUnit.CooldownMitigation {
+		newUm.CooldownMitigation[i] = &proto.CooldownMitigationMetrics{
+			Id: cm.Id,
+		}
+	}
+
+	// Decisions come from the first iteration of the first result.
+	if baseUnit.AplTrace != nil {
+		newUm.AplTrace = &proto.APLTrace{
+			Decisions: baseUnit.AplTrace.Decisions,
+			Items:     make([]*proto.APLTraceItemSummary, len(baseUnit.AplTrace.Items)),
+		}
+		for i, item := range baseUnit.AplTrace.Items {
+			newUm.AplTrace.Items[i] = &proto.APLTraceItemSummary{
+				ListName:         item.ListName,
+				ItemIdx:          item.ItemIdx,
+				Action:           item.Action,
+				SkipReasonCounts: make([]int32, len(item.SkipReasonCounts)),
+			}
+		}
+	}
+
+	if baseUnit.RotationStats != nil {
+		newUm.RotationStats = &proto.APLStats{
+			PriorityList: MapSlice(baseUnit.RotationStats.PriorityList, newAPLActionStats),
+			Variables:    baseUnit.RotationStats.Variables,
+			ActionLists:  MapSlice(baseUnit.RotationStats.ActionLists, newAPLActionStats),
+		}
 	}
 
 	for i, aura := range baseUnit.Auras {
@@ -59,6 +92,28 @@ func (rsrc *raidSimResultCombiner) newUnitMetrics(baseUnit *proto.UnitMetrics) *
 	return newUm
 }
 
+// Copies the static analysis results of APL item stats, without any coverage.
+func newAPLActionStats(base *proto.APLActionStats) *proto.APLActionStats {
+	return &proto.APLActionStats{
+		Warnings:    base.Warnings,
+		Unreachable: base.Unreachable,
+		Items:       MapSlice(base.Items, newAPLActionStats),
+	}
+}
+
+func combineAPLActionStats(base *proto.APLActionStats, add *proto.APLActionStats) {
+	iterationsExecuted := base.IterationsExecuted + add.IterationsExecuted
+	if iterationsExecuted > 0 {
+		base.FirstExecutedSecondsAvg = (base.FirstExecutedSecondsAvg*float64(base.IterationsExecuted) + add.FirstExecutedSecondsAvg*float64(add.IterationsExecuted)) / float64(iterationsExecuted)
+	}
+	base.IterationsExecuted = iterationsExecuted
+	base.Executions += add.Executions
+
+	for i, addItem := range add.Items {
+		combineAPLActionStats(base.Items[i], addItem)
+	}
+}
+
 func (rsrc *raidSimResultCombiner) newPartyMetrics(baseParty *proto.PartyMetrics) *proto.PartyMetrics {
 	newPm := &proto.PartyMetrics{
 		Dps:     rsrc.newDistMetrics(),
@@ -185,6 +240,39 @@ func (rsrc *raidSimResultCombiner) addResourceMetrics(unit *proto.UnitMetrics, a
 	rm.ActualGain += add.ActualGain
 }
 
+func (rsrc *raidSimResultCombiner) addDamageTakenMetrics(unit *proto.UnitMetrics, add *proto.DamageTakenMetrics) {
+	var dtm *proto.DamageTakenMetrics
+
+	for _, baseDamageTaken := range unit.DamageTaken {
+		if baseDamageTaken.SourceUnitIndex == add.SourceUnitIndex && baseDamageTaken.Id.String() == add.Id.String() {
+			dtm = baseDamageTaken
+			break
+		}
+	}
+
+	if dtm == nil {
+		dtm = &proto.DamageTakenMetrics{
+			Id:              add.Id,
+			SourceUnitIndex: add.SourceUnitIndex,
+			School:          add.School,
+		}
+		unit.DamageTaken = append(unit.DamageTaken, dtm)
+	}
+
+	dtm.Hits += add.Hits
+	dtm.Crits += add.Crits
+	dtm.Misses += add.Misses
+	dtm.Dodges += add.Dodges
+	dtm.Parries += add.Parries
+	dtm.Blocks += add.Blocks
+	dtm.Damage += add.Damage
+	dtm.ResistedDamage += add.ResistedDamage
+	dtm.ReducedDamage += add.ReducedDamage
+	dtm.AvoidedDamage += add.AvoidedDamage
+	dtm.BlockedDamage += add.BlockedDamage
+	dtm.AbsorbedDamage += add.AbsorbedDamage
+}
+
 func (rsrc *raidSimResultCombiner) combineUnitMetrics(base *proto.UnitMetrics, add *proto.UnitMetrics, isLast bool, weight float64) {
 	rsrc.combineDistMetrics(base.Dps, add.Dps, isLast, weight)
 	rsrc.combineDistMetrics(base.Dpasp, add.Dpasp, isLast, weight)
@@ -209,6 +297,38 @@ func (rsrc *raidSimResultCombiner) combineUnitMetrics(base *proto.UnitMetrics, a
 		rsrc.addResourceMetrics(base, addResource)
 	}
 
+	for _, addDamageTaken := range add.DamageTaken {
+		rsrc.addDamageTakenMetrics(base, addDamageTaken)
+	}
+
+	for i, addMitigation := range add.CooldownMitigation {
+		baseMitigation := base.CooldownMitigation[i]
+		baseMitigation.Hits += addMitigation.Hits
+		baseMitigation.DamageTaken += addMitigation.DamageTaken
+		baseMitigation.DamageMitigated += addMitigation.DamageMitigated
+	}
+
+	if add.AplTrace != nil {
+		for i, addItem := range add.AplTrace.Items {
+			baseItem := base.AplTrace.Items[i]
+			baseItem.TimesConsidered += addItem.TimesConsidered
+			baseItem.TimesExecuted += addItem.TimesExecuted
+			for reason, count := range addItem.SkipReasonCounts {
+				baseItem.SkipReasonCounts[reason] += count
+			}
+			baseItem.MostCommonSkipReason = mostCommonSkipReason(baseItem.SkipReasonCounts)
+		}
+	}
+
+	if add.RotationStats != nil {
+		for i, addItem := range add.RotationStats.PriorityList {
+			combineAPLActionStats(base.RotationStats.PriorityList[i], addItem)
+		}
+		for i, addList := range add.RotationStats.ActionLists {
+			combineAPLActionStats(base.RotationStats.ActionLists[i], addList)
+		}
+	}
+
 	for i, addPet := range add.Pets {
 		rsrc.combineUnitMetrics(base.Pets[i], addPet, isLast, weight)
 	}
@@ -348,6 +468,9 @@ func RunConcurrentRaidSimAsync(request *proto.RaidSimRequest, progress chan *pro
 	if concurrency > int(request.SimOptions.Iterations) {
 		concurrency = int(request.SimOptions.Iterations)
 	}
+	if request.SimOptions.ReplayIteration {
+		concurrency = 1
+	}
 
 	substituteChannels := make([]chan *proto.ProgressMetrics, concurrency)
 	substituteCases := make([]reflect.SelectCase, concurrency)
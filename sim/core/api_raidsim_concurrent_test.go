@@ -2,6 +2,7 @@ package core_test
 
 import (
 	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/wowsims/cata/sim/core"
@@ -11,6 +12,14 @@ import (
 	"github.com/wowsims/cata/sim/hunter/marksmanship"
 )
 
+// Registering a spec twice panics, and other tests in this package reuse these
+// players.
+var (
+	registerMMOnce       sync.Once
+	registerBloodDkOnce  sync.Once
+	registerFeralCatOnce sync.Once
+)
+
 func getTestPlayerMM() *proto.Player {
 	var FullConsumes = &proto.Consumes{
 		Flask:         proto.Flask_FlaskOfTheWinds,
@@ -48,7 +57,7 @@ func getTestPlayerMM() *proto.Player {
 		},
 	}
 
-	marksmanship.RegisterMarksmanshipHunter()
+	registerMMOnce.Do(marksmanship.RegisterMarksmanshipHunter)
 
 	return &proto.Player{
 		Race:           proto.Race_RaceOrc,
@@ -90,7 +99,7 @@ func getTestPlayerBloodDk() *proto.Player {
 		Food:          proto.Food_FoodBeerBasedCrocolisk,
 	}
 
-	blood.RegisterBloodDeathKnight()
+	registerBloodDkOnce.Do(blood.RegisterBloodDeathKnight)
 
 	return &proto.Player{
 		Race:           proto.Race_RaceWorgen,
@@ -132,7 +141,7 @@ func getTestPlayerFeralCat() *proto.Player {
 		PrepopPotion:  proto.Potions_PotionOfTheTolvir,
 	}
 
-	feral.RegisterFeralDruid()
+	registerFeralCatOnce.Do(feral.RegisterFeralDruid)
 
 	return &proto.Player{
 		Race:           proto.Race_RaceTauren,
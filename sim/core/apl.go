@@ -2,7 +2,7 @@ package core
 
 import (
 	"fmt"
-	"time"
+	"slices"
 
 	"github.com/wowsims/cata/sim/core/proto"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -13,6 +13,21 @@ type APLRotation struct {
 	prepullActions []*APLAction
 	priorityList   []*APLAction
 
+	priorityListIdxs []int // Config index of each entry in priorityList.
+
+	// Static analysis results and coverage for each list item, indexed by config index.
+	priorityListItemStats []*aplListItemStats
+	actionListItemStats   [][]*aplListItemStats
+
+	// Named variables and action lists, which can be referenced from any list.
+	variables      map[string]*aplVariable
+	actionLists    []*aplActionList
+	actionListIdxs []int // Config index of each entry in actionLists.
+
+	// Incremented each time the rotation picks its next action, so variables
+	// can be computed once per decision.
+	decisionID int
+
 	// Action currently controlling this rotation (only used for certain actions, such as StrictSequence).
 	controllingActions []APLActionImpl
 
@@ -26,17 +41,31 @@ type APLRotation struct {
 	// Used inside of actions/value to determine whether they will occur during the prepull or regular rotation.
 	parsingPrepull bool
 
+	// Item swap set worn when combat starts, after any prepull item swaps.
+	prepullItemSwapSet int
+
 	// Used to avoid recursive APL loops.
 	inLoop bool
 
 	// Used to override MCD restrictions within sequences.
 	inSequence bool
 
+	// Target being evaluated by a target_if, referenced by the Candidate unit reference.
+	targetCandidate *Unit
+
 	// Validation warnings that occur during proto parsing.
 	// We return these back to the user for display in the UI.
 	curWarnings          []string
 	prepullWarnings      [][]string
 	priorityListWarnings [][]string
+	variableWarnings     [][]string
+	actionListWarnings   [][]string
+
+	// When each prepull action is performed, reported back for display in the UI.
+	prepullSchedule []*proto.APLPrepullScheduleEntry
+
+	// Decision trace, only set when enabled in the sim options.
+	trace *aplTrace
 }
 
 func (rot *APLRotation) ValidationWarning(message string, vals ...interface{}) {
@@ -78,50 +107,91 @@ func (unit *Unit) newAPLRotation(config *proto.APLRotation) *APLRotation {
 		unit:                 unit,
 		prepullWarnings:      make([][]string, len(config.PrepullActions)),
 		priorityListWarnings: make([][]string, len(config.PriorityList)),
+		variableWarnings:     make([][]string, len(config.Variables)),
+		actionListWarnings:   make([][]string, len(config.ActionLists)),
+		variables:            make(map[string]*aplVariable, len(config.Variables)),
+
+		priorityListItemStats: newAPLListItemStats(len(config.PriorityList)),
+		actionListItemStats: MapSlice(config.ActionLists, func(list *proto.APLActionList) []*aplListItemStats {
+			return newAPLListItemStats(len(list.Items))
+		}),
 	}
 
+	// Variables are parsed on first use, so register them before any actions.
+	rotation.registerVariables(config.Variables)
+
 	// Parse prepull actions
+	var autoTimedActions, fixedTimeActions []*aplPrepullPlanItem
 	for i, prepullItem := range config.PrepullActions {
 		prepullIdx := i // Save to local variable for correct lambda capture behavior
 		rotation.doAndRecordWarnings(&rotation.prepullWarnings[prepullIdx], true, func() {
-			if !prepullItem.Hide {
-				doAtVal := rotation.newAPLValue(prepullItem.DoAtValue)
-				if doAtVal != nil {
-					doAt := doAtVal.GetDuration(nil)
-					if doAt > 0 {
-						rotation.ValidationWarning("Invalid time for 'Do At', ignoring this Prepull Action")
-					} else {
-						action := rotation.newAPLAction(prepullItem.Action)
-						if action != nil {
-							rotation.prepullActions = append(rotation.prepullActions, action)
-							unit.RegisterPrepullAction(doAt, func(sim *Simulation) {
-								// Warnings for prepull cast failure are detected by running a fake prepull,
-								// so this action.Execute needs to record warnings.
-								rotation.doAndRecordWarnings(&rotation.prepullWarnings[prepullIdx], true, func() {
-									action.Execute(sim)
-								})
-							})
-						}
+			if prepullItem.Hide {
+				return
+			}
+			if config.AutoPrepullTiming && isEmptyDoAt(prepullItem.DoAtValue) {
+				action := rotation.newAPLAction(prepullItem.Action)
+				if action != nil {
+					rotation.prepullActions = append(rotation.prepullActions, action)
+					item := newPrepullPlanItem(prepullIdx, action)
+					item.autoTimed = true
+					autoTimedActions = append(autoTimedActions, item)
+				}
+				return
+			}
+
+			doAtVal := rotation.newAPLValue(prepullItem.DoAtValue)
+			if doAtVal != nil {
+				doAt := doAtVal.GetDuration(nil)
+				if doAt > 0 {
+					rotation.ValidationWarning("Invalid time for 'Do At', ignoring this Prepull Action")
+				} else {
+					action := rotation.newAPLAction(prepullItem.Action)
+					if action != nil {
+						rotation.prepullActions = append(rotation.prepullActions, action)
+						item := newPrepullPlanItem(prepullIdx, action)
+						item.doAt = doAt
+						fixedTimeActions = append(fixedTimeActions, item)
 					}
 				}
 			}
 		})
 	}
+	planPrepullActions(autoTimedActions, fixedTimeActions)
+	scheduledActions := append(fixedTimeActions, autoTimedActions...)
+	slices.SortStableFunc(scheduledActions, comparePrepullPlanItems)
+	for _, item := range scheduledActions {
+		if itemSwap, ok := item.action.impl.(*APLActionItemSwap); ok {
+			rotation.prepullItemSwapSet = itemSwap.setIdx
+		}
+		rotation.registerPrepullAction(item.prepullIdx, item.action, item.doAt, item.autoTimed)
+	}
 
 	// Parse priority list
-	var configIdxs []int
 	for i, aplItem := range config.PriorityList {
 		rotation.doAndRecordWarnings(&rotation.priorityListWarnings[i], false, func() {
 			if !aplItem.Hide {
 				action := rotation.newAPLAction(aplItem.Action)
 				if action != nil {
+					action.listItemStats = rotation.priorityListItemStats[i]
 					rotation.priorityList = append(rotation.priorityList, action)
-					configIdxs = append(configIdxs, i)
+					rotation.priorityListIdxs = append(rotation.priorityListIdxs, i)
+				} else {
+					rotation.priorityListItemStats[i].unreachable = true
 				}
 			}
 		})
 	}
 
+	// Parse action lists
+	rotation.parseActionLists(config.ActionLists)
+
+	// Parse any variables which were not referenced by an action, so their warnings are reported.
+	for _, variableConfig := range config.Variables {
+		if variable := rotation.variables[variableConfig.Name]; variable != nil && !variable.parsed {
+			rotation.getVariable(variable.name)
+		}
+	}
+
 	// Finalize
 	for i, action := range rotation.prepullActions {
 		rotation.doAndRecordWarnings(&rotation.prepullWarnings[i], true, func() {
@@ -133,6 +203,30 @@ func (unit *Unit) newAPLRotation(config *proto.APLRotation) *APLRotation {
 			action.Finalize(rotation)
 		})
 	}
+	for i, list := range rotation.actionLists {
+		rotation.doAndRecordWarnings(&rotation.actionListWarnings[rotation.actionListIdxs[i]], false, func() {
+			for _, action := range list.actions {
+				action.Finalize(rotation)
+			}
+		})
+	}
+	for i, variableConfig := range config.Variables {
+		variable := rotation.variables[variableConfig.Name]
+		if variable == nil || variable.configIdx != i || variable.value == nil {
+			continue
+		}
+		rotation.doAndRecordWarnings(&rotation.variableWarnings[i], false, func() {
+			for _, value := range expandAPLValues([]APLValue{variable.value}) {
+				value.Finalize(rotation)
+			}
+		})
+	}
+	rotation.breakActionListCycles()
+
+	rotation.analyzeListItems(rotation.priorityList)
+	for _, list := range rotation.actionLists {
+		rotation.analyzeListItems(list.actions)
+	}
 
 	// Remove MCDs that are referenced by APL actions, so that the Autocast Other Cooldowns
 	// action does not include them.
@@ -161,7 +255,7 @@ func (unit *Unit) newAPLRotation(config *proto.APLRotation) *APLRotation {
 				}
 			}
 			if !found {
-				unit.RegisterPrepullAction(-1*time.Second, func(sim *Simulation) {
+				unit.RegisterPrepullAction(defaultPrepotTime, func(sim *Simulation) {
 					prepotSpell.Cast(sim, nil)
 				})
 			}
@@ -171,18 +265,36 @@ func (unit *Unit) newAPLRotation(config *proto.APLRotation) *APLRotation {
 	return rotation
 }
 func (rot *APLRotation) getStats() *proto.APLStats {
-	return &proto.APLStats{
-		PrepullActions: MapSlice(rot.prepullWarnings, func(warnings []string) *proto.APLActionStats { return &proto.APLActionStats{Warnings: warnings} }),
-		PriorityList:   MapSlice(rot.priorityListWarnings, func(warnings []string) *proto.APLActionStats { return &proto.APLActionStats{Warnings: warnings} }),
+	stats := &proto.APLStats{
+		PrepullActions:  MapSlice(rot.prepullWarnings, func(warnings []string) *proto.APLActionStats { return &proto.APLActionStats{Warnings: warnings} }),
+		PriorityList:    make([]*proto.APLActionStats, len(rot.priorityListWarnings)),
+		Variables:       MapSlice(rot.variableWarnings, func(warnings []string) *proto.APLActionStats { return &proto.APLActionStats{Warnings: warnings} }),
+		ActionLists:     make([]*proto.APLActionStats, len(rot.actionListWarnings)),
+		PrepullSchedule: rot.prepullSchedule,
 	}
+	for i, warnings := range rot.priorityListWarnings {
+		stats.PriorityList[i] = rot.priorityListItemStats[i].toProto(warnings)
+	}
+	for i, warnings := range rot.actionListWarnings {
+		stats.ActionLists[i] = &proto.APLActionStats{
+			Warnings: warnings,
+			Items:    MapSlice(rot.actionListItemStats[i], func(itemStats *aplListItemStats) *proto.APLActionStats { return itemStats.toProto(nil) }),
+		}
+	}
+	return stats
 }
 
 func (rot *APLRotation) allAPLActions() []*APLAction {
-	if rot == nil || rot.priorityList == nil {
+	if rot == nil || (rot.priorityList == nil && rot.actionLists == nil) {
 		return []*APLAction{}
 	}
 
-	return Flatten(MapSlice(rot.priorityList, func(action *APLAction) []*APLAction {
+	actions := rot.priorityList
+	for _, list := range rot.actionLists {
+		actions = append(actions[:len(actions):len(actions)], list.actions...)
+	}
+
+	return Flatten(MapSlice(actions, func(action *APLAction) []*APLAction {
 		// Check if action is nil before calling GetAllActions
 		if action == nil {
 			return []*APLAction{}
@@ -200,10 +312,33 @@ func (rot *APLRotation) reset(sim *Simulation) {
 	rot.controllingActions = nil
 	rot.inLoop = false
 	rot.interruptChannelIf = nil
+	rot.targetCandidate = nil
 	rot.allowChannelRecastOnInterrupt = false
+	for _, variable := range rot.variables {
+		variable.reset()
+	}
 	for _, action := range rot.allAPLActions() {
 		action.impl.Reset(sim)
 	}
+	for _, stats := range rot.allListItemStats() {
+		stats.executedThisIteration = false
+	}
+
+	if sim.Options.TraceApl {
+		if rot.trace == nil {
+			rot.trace = rot.newAPLTrace()
+		}
+		rot.trace.reset()
+	}
+}
+
+// Clears what was recorded over previous iterations, for when the environment
+// is reused.
+func (rot *APLRotation) clearMetrics() {
+	for _, stats := range rot.allListItemStats() {
+		stats.clearExecutions()
+	}
+	rot.trace = nil
 }
 
 // We intentionally try to mimic the behavior of simc APL to avoid confusion
@@ -235,6 +370,12 @@ func (apl *APLRotation) DoNextAction(sim *Simulation) {
 			panic(fmt.Sprintf("[USER_ERROR] Infinite loop detected, current action:\n%s", nextAction))
 		}
 
+		if apl.trace != nil {
+			apl.trace.execute(nextAction)
+		}
+		if nextAction.listItemStats != nil {
+			nextAction.listItemStats.recordExecution(sim)
+		}
 		nextAction.Execute(sim)
 	}
 	apl.inLoop = false
@@ -256,17 +397,18 @@ func (apl *APLRotation) DoNextAction(sim *Simulation) {
 }
 
 func (apl *APLRotation) getNextAction(sim *Simulation) *APLAction {
+	// Each action within a sequence is a new decision too, so cached
+	// variables see the effects of the previous action.
+	apl.decisionID++
 	if len(apl.controllingActions) != 0 {
 		return apl.controllingActions[len(apl.controllingActions)-1].GetNextAction(sim)
 	}
 
-	for _, action := range apl.priorityList {
-		if action.IsReady(sim) {
-			return action
-		}
+	if apl.trace != nil {
+		apl.trace.startDecision(sim)
 	}
-
-	return nil
+	nextAction, _ := apl.nextReadyAction(sim, apl.priorityList)
+	return nextAction
 }
 
 func (apl *APLRotation) pushControllingAction(ca APLActionImpl) {
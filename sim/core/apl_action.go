@@ -9,6 +9,9 @@ import (
 type APLAction struct {
 	condition APLValue
 	impl      APLActionImpl
+
+	// Set for priority list and action list items.
+	listItemStats *aplListItemStats
 }
 
 func (action *APLAction) Finalize(rot *APLRotation) {
@@ -41,15 +44,23 @@ func (action *APLAction) GetAllAPLValues() []APLValue {
 		if a.condition != nil {
 			unprocessed = append(unprocessed, a.condition)
 		}
+		values = append(values, expandAPLValues(unprocessed)...)
+	}
+	return FilterSlice(values, func(val APLValue) bool { return val != nil })
+}
 
-		for len(unprocessed) > 0 {
-			next := unprocessed[len(unprocessed)-1]
-			unprocessed = unprocessed[:len(unprocessed)-1]
-			values = append(values, next)
+// Returns the given values along with all of their inner values.
+func expandAPLValues(unprocessed []APLValue) []APLValue {
+	var values []APLValue
+	for len(unprocessed) > 0 {
+		next := unprocessed[len(unprocessed)-1]
+		unprocessed = unprocessed[:len(unprocessed)-1]
+		values = append(values, next)
+		if next != nil {
 			unprocessed = append(unprocessed, next.GetInnerValues()...)
 		}
 	}
-	return FilterSlice(values, func(val APLValue) bool { return val != nil })
+	return values
 }
 
 func (action *APLAction) GetAllSpells() []*Spell {
@@ -66,6 +77,19 @@ func (action *APLAction) GetAllSpells() []*Spell {
 	return spells
 }
 
+// Returns the spell cast by this action and its target, if it casts one.
+func (action *APLAction) getSpell() (*Spell, *Unit) {
+	switch impl := action.impl.(type) {
+	case *APLActionCastSpell:
+		return impl.spell, impl.target.Get()
+	case *APLActionCastFriendlySpell:
+		return impl.spell, impl.target.Get()
+	case *APLActionChannelSpell:
+		return impl.spell, impl.target.Get()
+	}
+	return nil, nil
+}
+
 func (action *APLAction) String() string {
 	if action.condition == nil {
 		return fmt.Sprintf("ACTION = %s", action.impl)
@@ -168,6 +192,12 @@ func (rot *APLRotation) newAPLActionImpl(config *proto.APLAction) APLActionImpl
 	case *proto.APLAction_StrictSequence:
 		return rot.newActionStrictSequence(config.GetStrictSequence())
 
+	// Action lists
+	case *proto.APLAction_CallActionList:
+		return rot.newActionCallActionList(config.GetCallActionList())
+	case *proto.APLAction_RunActionList:
+		return rot.newActionRunActionList(config.GetRunActionList())
+
 	// Misc
 	case *proto.APLAction_ChangeTarget:
 		return rot.newActionChangeTarget(config.GetChangeTarget())
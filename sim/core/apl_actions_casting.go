@@ -8,8 +8,11 @@ import (
 
 type APLActionCastSpell struct {
 	defaultAPLActionImpl
-	spell  *Spell
-	target UnitReference
+	spell    *Spell
+	target   UnitReference
+	targetIf *aplTargetIf
+
+	nextTarget *Unit
 }
 
 func (rot *APLRotation) newActionCastSpell(config *proto.APLActionCastSpell) APLActionImpl {
@@ -22,14 +25,36 @@ func (rot *APLRotation) newActionCastSpell(config *proto.APLActionCastSpell) APL
 		return nil
 	}
 	return &APLActionCastSpell{
-		spell:  spell,
-		target: target,
+		spell:    spell,
+		target:   target,
+		targetIf: rot.newTargetIf(config.TargetIf, false),
+	}
+}
+func (action *APLActionCastSpell) GetAPLValues() []APLValue {
+	if action.targetIf == nil {
+		return nil
 	}
+	return []APLValue{action.targetIf.value}
+}
+func (action *APLActionCastSpell) Reset(*Simulation) {
+	action.nextTarget = nil
 }
 func (action *APLActionCastSpell) IsReady(sim *Simulation) bool {
-	return action.spell.CanCastOrQueue(sim, action.target.Get()) && (!action.spell.Flags.Matches(SpellFlagMCD) || action.spell.Unit.GCD.IsReady(sim) || action.spell.Unit.Rotation.inSequence)
+	mcdReady := !action.spell.Flags.Matches(SpellFlagMCD) || action.spell.Unit.GCD.IsReady(sim) || action.spell.Unit.Rotation.inSequence
+	if action.targetIf != nil {
+		if !mcdReady {
+			return false
+		}
+		action.nextTarget = action.targetIf.selectTarget(sim, func(target *Unit) bool { return action.spell.CanCastOrQueue(sim, target) })
+		return action.nextTarget != nil
+	}
+	return action.spell.CanCastOrQueue(sim, action.target.Get()) && mcdReady
 }
 func (action *APLActionCastSpell) Execute(sim *Simulation) {
+	if action.targetIf != nil {
+		action.spell.CastOrQueue(sim, action.nextTarget)
+		return
+	}
 	action.spell.CastOrQueue(sim, action.target.Get())
 }
 func (action *APLActionCastSpell) String() string {
@@ -38,8 +63,11 @@ func (action *APLActionCastSpell) String() string {
 
 type APLActionCastFriendlySpell struct {
 	defaultAPLActionImpl
-	spell  *Spell
-	target UnitReference
+	spell    *Spell
+	target   UnitReference
+	targetIf *aplTargetIf
+
+	nextTarget *Unit
 }
 
 func (rot *APLRotation) newActionCastFriendlySpell(config *proto.APLActionCastFriendlySpell) APLActionImpl {
@@ -52,14 +80,36 @@ func (rot *APLRotation) newActionCastFriendlySpell(config *proto.APLActionCastFr
 		return nil
 	}
 	return &APLActionCastFriendlySpell{
-		spell:  spell,
-		target: target,
+		spell:    spell,
+		target:   target,
+		targetIf: rot.newTargetIf(config.TargetIf, true),
+	}
+}
+func (action *APLActionCastFriendlySpell) GetAPLValues() []APLValue {
+	if action.targetIf == nil {
+		return nil
 	}
+	return []APLValue{action.targetIf.value}
+}
+func (action *APLActionCastFriendlySpell) Reset(*Simulation) {
+	action.nextTarget = nil
 }
 func (action *APLActionCastFriendlySpell) IsReady(sim *Simulation) bool {
-	return action.spell.CanCastOrQueue(sim, action.target.Get()) && (!action.spell.Flags.Matches(SpellFlagMCD) || action.spell.Unit.GCD.IsReady(sim) || action.spell.Unit.Rotation.inSequence)
+	mcdReady := !action.spell.Flags.Matches(SpellFlagMCD) || action.spell.Unit.GCD.IsReady(sim) || action.spell.Unit.Rotation.inSequence
+	if action.targetIf != nil {
+		if !mcdReady {
+			return false
+		}
+		action.nextTarget = action.targetIf.selectTarget(sim, func(target *Unit) bool { return action.spell.CanCastOrQueue(sim, target) })
+		return action.nextTarget != nil
+	}
+	return action.spell.CanCastOrQueue(sim, action.target.Get()) && mcdReady
 }
 func (action *APLActionCastFriendlySpell) Execute(sim *Simulation) {
+	if action.targetIf != nil {
+		action.spell.CastOrQueue(sim, action.nextTarget)
+		return
+	}
 	action.spell.CastOrQueue(sim, action.target.Get())
 }
 func (action *APLActionCastFriendlySpell) String() string {
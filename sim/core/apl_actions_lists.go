@@ -0,0 +1,238 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// A named list of actions, which can be invoked from other lists with the
+// Call Action List and Run Action List actions.
+type aplActionList struct {
+	name       string
+	actions    []*APLAction
+	actionIdxs []int // Config index of each entry in actions.
+}
+
+func (rot *APLRotation) getActionList(name string) *aplActionList {
+	for _, list := range rot.actionLists {
+		if list.name == name {
+			return list
+		}
+	}
+	return nil
+}
+
+func (rot *APLRotation) parseActionLists(configs []*proto.APLActionList) {
+	for i, config := range configs {
+		rot.doAndRecordWarnings(&rot.actionListWarnings[i], false, func() {
+			if config.Name == "" {
+				rot.ValidationWarning("Action lists must have a name")
+				return
+			}
+			if rot.getActionList(config.Name) != nil {
+				rot.ValidationWarning("Duplicate action list name: '%s'", config.Name)
+				return
+			}
+
+			list := &aplActionList{name: config.Name}
+			for j, item := range config.Items {
+				if item.Hide {
+					continue
+				}
+				if action := rot.newAPLAction(item.Action); action != nil {
+					action.listItemStats = rot.actionListItemStats[i][j]
+					list.actions = append(list.actions, action)
+					list.actionIdxs = append(list.actionIdxs, j)
+				} else {
+					rot.actionListItemStats[i][j].unreachable = true
+				}
+			}
+			rot.actionLists = append(rot.actionLists, list)
+			rot.actionListIdxs = append(rot.actionListIdxs, i)
+		})
+	}
+}
+
+// Returns the Call/Run Action List actions directly or indirectly contained in
+// the given actions.
+func actionListReferences(actions []*APLAction) []*aplActionListReference {
+	var refs []*aplActionListReference
+	for _, action := range actions {
+		for _, inner := range action.GetAllActions() {
+			switch impl := inner.impl.(type) {
+			case *APLActionCallActionList:
+				refs = append(refs, &impl.aplActionListReference)
+			case *APLActionRunActionList:
+				refs = append(refs, &impl.aplActionListReference)
+			}
+		}
+	}
+	return refs
+}
+
+// Detects lists which (directly or indirectly) invoke themselves, and disables
+// the references which close each cycle.
+func (rot *APLRotation) breakActionListCycles() {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make(map[*aplActionList]int, len(rot.actionLists))
+
+	var visit func(listIdx int)
+	visit = func(listIdx int) {
+		list := rot.actionLists[listIdx]
+		states[list] = visiting
+		for _, ref := range actionListReferences(list.actions) {
+			if ref.list == nil {
+				continue
+			}
+			switch states[ref.list] {
+			case visiting:
+				rot.doAndRecordWarnings(&rot.actionListWarnings[rot.actionListIdxs[listIdx]], false, func() {
+					rot.ValidationWarning("Action list '%s' is part of a cycle through '%s'", list.name, ref.list.name)
+				})
+				ref.list = nil
+			case unvisited:
+				for i, other := range rot.actionLists {
+					if other == ref.list {
+						visit(i)
+					}
+				}
+			}
+		}
+		states[list] = visited
+	}
+
+	for i, list := range rot.actionLists {
+		if states[list] == unvisited {
+			visit(i)
+		}
+	}
+}
+
+// Returns the first action which is ready to be executed, descending into any
+// action lists invoked along the way. The second return value is true if a
+// Run Action List was entered, in which case evaluation must not continue
+// past it even if no action was found.
+func (rot *APLRotation) nextReadyAction(sim *Simulation, actions []*APLAction) (*APLAction, bool) {
+	for _, action := range actions {
+		switch impl := action.impl.(type) {
+		case *APLActionCallActionList:
+			if rot.shouldEnterActionList(sim, action, impl.list) {
+				if nextAction, stop := rot.nextReadyAction(sim, impl.list.actions); nextAction != nil || stop {
+					return nextAction, stop
+				}
+			}
+			continue
+		case *APLActionRunActionList:
+			if rot.shouldEnterActionList(sim, action, impl.list) {
+				nextAction, _ := rot.nextReadyAction(sim, impl.list.actions)
+				return nextAction, true
+			}
+			continue
+		}
+
+		if rot.trace != nil {
+			if rot.traceIsReady(sim, action) {
+				return action, false
+			}
+		} else if action.IsReady(sim) {
+			return action, false
+		}
+	}
+	return nil, false
+}
+
+// Whether a Call/Run Action List action should descend into its list.
+func (rot *APLRotation) shouldEnterActionList(sim *Simulation, action *APLAction, list *aplActionList) bool {
+	if list == nil {
+		if rot.trace != nil {
+			rot.trace.consider(action, true, false, proto.APLSkipReason_SkipReasonNotReady)
+		}
+		return false
+	}
+	conditionMet := action.condition == nil || action.condition.GetBool(sim)
+	if rot.trace != nil {
+		skipReason := proto.APLSkipReason_SkipReasonNone
+		if !conditionMet {
+			skipReason = proto.APLSkipReason_SkipReasonConditionFalse
+		}
+		rot.trace.consider(action, conditionMet, false, skipReason)
+	}
+	return conditionMet
+}
+
+type aplActionListReference struct {
+	defaultAPLActionImpl
+	rot      *APLRotation
+	listName string
+	list     *aplActionList
+}
+
+func (ref *aplActionListReference) Finalize(rot *APLRotation) {
+	ref.list = rot.getActionList(ref.listName)
+	if ref.list == nil {
+		rot.ValidationWarning("No action list with name: '%s'", ref.listName)
+	}
+}
+func (ref *aplActionListReference) nextAction(sim *Simulation) *APLAction {
+	if ref.list == nil {
+		return nil
+	}
+	nextAction, _ := ref.rot.nextReadyAction(sim, ref.list.actions)
+	return nextAction
+}
+
+// Only used when the action is nested inside another action, e.g. a sequence.
+// Top-level list invocations are handled by APLRotation.nextReadyAction.
+func (ref *aplActionListReference) IsReady(sim *Simulation) bool {
+	return ref.nextAction(sim) != nil
+}
+func (ref *aplActionListReference) Execute(sim *Simulation) {
+	if nextAction := ref.nextAction(sim); nextAction != nil {
+		nextAction.Execute(sim)
+	}
+}
+
+type APLActionCallActionList struct {
+	aplActionListReference
+}
+
+func (rot *APLRotation) newActionCallActionList(config *proto.APLActionCallActionList) APLActionImpl {
+	if config.ListName == "" {
+		rot.ValidationWarning("Call Action List must provide a list name")
+		return nil
+	}
+	return &APLActionCallActionList{
+		aplActionListReference: aplActionListReference{
+			rot:      rot,
+			listName: config.ListName,
+		},
+	}
+}
+func (action *APLActionCallActionList) String() string {
+	return fmt.Sprintf("Call Action List(name = '%s')", action.listName)
+}
+
+type APLActionRunActionList struct {
+	aplActionListReference
+}
+
+func (rot *APLRotation) newActionRunActionList(config *proto.APLActionRunActionList) APLActionImpl {
+	if config.ListName == "" {
+		rot.ValidationWarning("Run Action List must provide a list name")
+		return nil
+	}
+	return &APLActionRunActionList{
+		aplActionListReference: aplActionListReference{
+			rot:      rot,
+			listName: config.ListName,
+		},
+	}
+}
+func (action *APLActionRunActionList) String() string {
+	return fmt.Sprintf("Run Action List(name = '%s')", action.listName)
+}
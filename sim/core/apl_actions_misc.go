@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/wowsims/cata/sim/core/proto"
@@ -123,40 +124,71 @@ func (action *APLActionTriggerICD) String() string {
 type APLActionItemSwap struct {
 	defaultAPLActionImpl
 	character *Character
-	swapSet   proto.APLActionItemSwap_SwapSet
+	setName   string
+	setIdx    int
 }
 
 func (rot *APLRotation) newActionItemSwap(config *proto.APLActionItemSwap) APLActionImpl {
-	if config.SwapSet == proto.APLActionItemSwap_Unknown {
+	if config.SwapSet == proto.APLActionItemSwap_Unknown && config.SetName == "" {
 		rot.ValidationWarning("Unknown item swap set")
 		return nil
 	}
 
+	setName := config.SetName
+	if setName == "" {
+		setName = config.SwapSet.String()
+	}
+
 	character := rot.unit.Env.Raid.GetPlayerFromUnit(rot.unit).GetCharacter()
 	if !character.ItemSwap.IsEnabled() {
-		if config.SwapSet != proto.APLActionItemSwap_Main {
+		if setName != proto.APLActionItemSwap_Main.String() {
 			rot.ValidationWarning("No swap set configured in Settings.")
 		}
 		return nil
 	}
 
+	setIdx := character.ItemSwap.findSet(setName)
+	if setIdx == -1 {
+		rot.ValidationWarning("No item swap set named %s", setName)
+		return nil
+	}
+	set := character.ItemSwap.sets[setIdx]
+	if len(set.slots) == 0 {
+		rot.ValidationWarning("No swap set configured in Settings.")
+		return nil
+	}
+	swapsNonWeapons := func(set *itemSwapSet) bool {
+		return slices.ContainsFunc(set.slots, func(slot proto.ItemSlot) bool { return !isWeaponSlot(slot) })
+	}
+	if !rot.parsingPrepull && setIdx != mainSwapSet && swapsNonWeapons(set) {
+		rot.ValidationWarning("Item swap set %s swaps items other than weapons, which can only be done before combat", setName)
+		return nil
+	}
+	// Items other than weapons put on before combat can't be taken off again.
+	prepullSet := character.ItemSwap.sets[rot.prepullItemSwapSet]
+	if !rot.parsingPrepull && setIdx == mainSwapSet && rot.prepullItemSwapSet != mainSwapSet && swapsNonWeapons(prepullSet) {
+		rot.ValidationWarning("Item swap set %s is equipped before combat and swaps items other than weapons, so the Main set can't be swapped back to in combat", prepullSet.name)
+		return nil
+	}
+
 	return &APLActionItemSwap{
 		character: character,
-		swapSet:   config.SwapSet,
+		setName:   setName,
+		setIdx:    setIdx,
 	}
 }
 func (action *APLActionItemSwap) IsReady(sim *Simulation) bool {
-	return (action.swapSet == proto.APLActionItemSwap_Main) == action.character.ItemSwap.IsSwapped()
+	return action.character.ItemSwap.canSwapTo(sim, action.setIdx)
 }
 func (action *APLActionItemSwap) Execute(sim *Simulation) {
 	if sim.Log != nil {
-		action.character.Log(sim, "Item Swap to set %s", action.swapSet)
+		action.character.Log(sim, "Item Swap to set %s", action.setName)
 	}
 
-	action.character.ItemSwap.SwapItems(sim, action.character.ItemSwap.slots)
+	action.character.ItemSwap.swapTo(sim, action.setIdx)
 }
 func (action *APLActionItemSwap) String() string {
-	return fmt.Sprintf("Item Swap(%s)", action.swapSet)
+	return fmt.Sprintf("Item Swap(%s)", action.setName)
 }
 
 type APLActionMove struct {
@@ -0,0 +1,192 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// Static analysis results and coverage for a priority list or action list item.
+type aplListItemStats struct {
+	warnings    []string
+	unreachable bool
+
+	executions            int32
+	iterationsExecuted    int32
+	firstExecutedSum      time.Duration
+	executedThisIteration bool
+}
+
+func newAPLListItemStats(numItems int) []*aplListItemStats {
+	stats := make([]*aplListItemStats, numItems)
+	for i := range stats {
+		stats[i] = &aplListItemStats{}
+	}
+	return stats
+}
+
+func (stats *aplListItemStats) recordExecution(sim *Simulation) {
+	stats.executions++
+	if !stats.executedThisIteration {
+		stats.executedThisIteration = true
+		stats.iterationsExecuted++
+		stats.firstExecutedSum += sim.CurrentTime
+	}
+}
+
+// Clears the execution counts, but keeps the static analysis results.
+func (stats *aplListItemStats) clearExecutions() {
+	stats.executions = 0
+	stats.iterationsExecuted = 0
+	stats.firstExecutedSum = 0
+	stats.executedThisIteration = false
+}
+
+func (stats *aplListItemStats) toProto(warnings []string) *proto.APLActionStats {
+	actionStats := &proto.APLActionStats{
+		Warnings:           append(warnings[:len(warnings):len(warnings)], stats.warnings...),
+		Unreachable:        stats.unreachable,
+		Executions:         stats.executions,
+		IterationsExecuted: stats.iterationsExecuted,
+	}
+	if stats.iterationsExecuted > 0 {
+		actionStats.FirstExecutedSecondsAvg = stats.firstExecutedSum.Seconds() / float64(stats.iterationsExecuted)
+	}
+	return actionStats
+}
+
+func (rot *APLRotation) allListItemStats() []*aplListItemStats {
+	return append(rot.priorityListItemStats[:len(rot.priorityListItemStats):len(rot.priorityListItemStats)], Flatten(rot.actionListItemStats)...)
+}
+
+// Returns the rotation stats reported with sim results. Prepull warnings are
+// left out, since they are collected by the fake prepull run before the sim.
+func (rot *APLRotation) getCoverageStats() *proto.APLStats {
+	stats := rot.getStats()
+	stats.PrepullActions = nil
+	stats.PrepullSchedule = nil
+	return stats
+}
+
+// Identifies cast actions which are ready under exactly the same circumstances.
+type aplCastKey struct {
+	spell   *Spell
+	target  UnitReference
+	channel bool
+}
+
+func aplCastKeyOf(action *APLAction) (aplCastKey, bool) {
+	switch impl := action.impl.(type) {
+	case *APLActionCastSpell:
+		return aplCastKey{spell: impl.spell, target: impl.target}, impl.targetIf == nil
+	case *APLActionCastFriendlySpell:
+		return aplCastKey{spell: impl.spell, target: impl.target}, impl.targetIf == nil
+	case *APLActionChannelSpell:
+		return aplCastKey{spell: impl.spell, target: impl.target, channel: true}, true
+	}
+	return aplCastKey{}, false
+}
+
+// Flags items in a list which can never be used: items whose condition is
+// always false, items after a Run Action List which always runs, and casts
+// shadowed by the same cast without a condition earlier in the list.
+func (rot *APLRotation) analyzeListItems(actions []*APLAction) {
+	alwaysRunsList := false
+	unconditionalCasts := make(map[aplCastKey]bool)
+
+	for _, action := range actions {
+		stats := action.listItemStats
+		rot.doAndRecordWarnings(&stats.warnings, false, func() {
+			if alwaysRunsList {
+				rot.ValidationWarning("Never reached, because an earlier Run Action List always runs")
+				stats.unreachable = true
+				return
+			}
+
+			alwaysTrue := action.condition == nil
+			if action.condition != nil {
+				if val, ok := aplConstantBool(action.condition); ok {
+					if !val {
+						rot.ValidationWarning("Condition is always false, so this action is never used")
+						stats.unreachable = true
+						return
+					}
+					alwaysTrue = true
+					if _, isConst := action.condition.(*APLValueConst); !isConst {
+						rot.ValidationWarning("Condition is always true")
+					}
+				} else {
+					for _, value := range expandAPLValues([]APLValue{action.condition}) {
+						if compare, isCompare := value.(*APLValueCompare); isCompare {
+							if val, ok := aplConstantBool(compare); ok {
+								rot.ValidationWarning("Comparison '%s' is always %t", compare, val)
+							}
+						}
+					}
+				}
+			}
+
+			castKey, isCast := aplCastKeyOf(action)
+			if isCast && unconditionalCasts[castKey] {
+				rot.ValidationWarning("Never used, because the same action earlier in the list has no condition")
+				stats.unreachable = true
+				return
+			}
+
+			if alwaysTrue {
+				if isCast {
+					unconditionalCasts[castKey] = true
+				}
+				if impl, ok := action.impl.(*APLActionRunActionList); ok && impl.list != nil {
+					alwaysRunsList = true
+				}
+			}
+		})
+	}
+}
+
+// Whether a value is the same at every point in every iteration.
+func isConstantAPLValue(value APLValue) bool {
+	switch value.(type) {
+	case *APLValueConst, *APLValueSpellIsKnown:
+		return true
+	case *APLValueCompare, *APLValueMath, *APLValueMax, *APLValueMin, *APLValueAnd, *APLValueOr, *APLValueNot, *APLValueCoerced:
+		for _, inner := range value.GetInnerValues() {
+			if inner == nil || !isConstantAPLValue(inner) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Returns the value of a boolean which is the same at every point in every
+// iteration, or false if it isn't constant. And/Or are constant if any of
+// their inputs decide the result on their own.
+func aplConstantBool(value APLValue) (bool, bool) {
+	switch value := value.(type) {
+	case *APLValueAnd:
+		for _, inner := range value.vals {
+			if val, ok := aplConstantBool(inner); ok && !val {
+				return false, true
+			}
+		}
+	case *APLValueOr:
+		for _, inner := range value.vals {
+			if val, ok := aplConstantBool(inner); ok && val {
+				return true, true
+			}
+		}
+	case *APLValueNot:
+		if val, ok := aplConstantBool(value.val); ok {
+			return !val, true
+		}
+		return false, false
+	}
+
+	if !isConstantAPLValue(value) {
+		return false, false
+	}
+	return value.GetBool(nil), true
+}
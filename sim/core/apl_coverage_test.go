@@ -0,0 +1,119 @@
+package core_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+func getTestPlayerCoverage() *proto.Player {
+	player := getTestPlayerMM()
+	player.DistanceFromTarget = 20
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"actionLists": [
+			{"name": "filler", "items": [
+				{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+			]}
+		],
+		"priorityList": [
+			{"action": {"castSpell": {"spellId": {"spellId": 3044}}}},
+			{"action": {"condition": {"cmp": {"op": "OpGt", "lhs": {"currentTime": {}}, "rhs": {"const": {"val": "5s"}}}}, "castSpell": {"spellId": {"spellId": 3044}}}},
+			{"action": {"condition": {"cmp": {"op": "OpGt", "lhs": {"const": {"val": "1"}}, "rhs": {"const": {"val": "2"}}}}, "castSpell": {"spellId": {"spellId": 56641}}}},
+			{"action": {"condition": {"and": {"vals": [{"spellIsKnown": {"spellId": {"spellId": 1}}}, {"cmp": {"op": "OpGt", "lhs": {"currentTime": {}}, "rhs": {"const": {"val": "5s"}}}}]}}, "castSpell": {"spellId": {"spellId": 56641}}}},
+			{"action": {"condition": {"and": {"vals": [{"cmp": {"op": "OpGt", "lhs": {"currentTime": {}}, "rhs": {"const": {"val": "500s"}}}}, {"cmp": {"op": "OpGt", "lhs": {"const": {"val": "3"}}, "rhs": {"const": {"val": "2"}}}}]}}, "castSpell": {"spellId": {"spellId": 56641}}}},
+			{"action": {"runActionList": {"listName": "filler"}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+		]
+	}`)
+	return player
+}
+
+func TestAPLDeadRuleAnalysis(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerCoverage())
+	result := core.ComputeStats(&proto.ComputeStatsRequest{Raid: rsr.Raid, Encounter: rsr.Encounter})
+	if result.ErrorResult != "" {
+		t.Fatalf("compute stats failed: %s", result.ErrorResult)
+	}
+
+	priorityList := result.RaidStats.Parties[0].Players[0].RotationStats.PriorityList
+	expectedUnreachable := []bool{
+		false, // Unconditional Arcane Shot.
+		true,  // Shadowed by the unconditional Arcane Shot.
+		true,  // Always false comparison.
+		true,  // Unknown spell.
+		false, // Always true comparison, but the other one isn't constant.
+		false, // Unconditional Run Action List.
+		true,  // After the Run Action List.
+	}
+	for i, unreachable := range expectedUnreachable {
+		if priorityList[i].Unreachable != unreachable {
+			t.Errorf("expected item %d to have unreachable = %t, got warnings %v", i, unreachable, priorityList[i].Warnings)
+		}
+	}
+
+	if !slices.ContainsFunc(priorityList[4].Warnings, func(warning string) bool { return strings.Contains(warning, "is always true") }) {
+		t.Errorf("expected a warning for an always true comparison, got %v", priorityList[4].Warnings)
+	}
+	if len(priorityList[5].Warnings) != 0 {
+		t.Errorf("expected no warnings for the Run Action List, got %v", priorityList[5].Warnings)
+	}
+}
+
+func TestAPLCoverage(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerCoverage())
+	rsr.SimOptions.Iterations = 5
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+
+	rotationStats := result.RaidMetrics.Parties[0].Players[0].RotationStats
+	arcaneShot := rotationStats.PriorityList[0]
+	if arcaneShot.Executions == 0 || arcaneShot.IterationsExecuted != 5 || arcaneShot.FirstExecutedSecondsAvg != 0 {
+		t.Errorf("expected Arcane Shot to be used from the start of every iteration, got %v", arcaneShot)
+	}
+	for i, item := range rotationStats.PriorityList {
+		if item.Unreachable && item.Executions != 0 {
+			t.Errorf("expected unreachable item %d to never be executed, got %d executions", i, item.Executions)
+		}
+	}
+	steadyShot := rotationStats.ActionLists[0].Items[0]
+	if steadyShot.Executions == 0 || steadyShot.FirstExecutedSecondsAvg <= 0 {
+		t.Errorf("expected Steady Shot to be used from the filler list after Arcane Shot, got %v", steadyShot)
+	}
+
+	mtResult := core.RunConcurrentRaidSimSync(rsr)
+	if mtResult.ErrorResult != "" {
+		t.Fatalf("concurrent sim failed: %s", mtResult.ErrorResult)
+	}
+	core.CompareConcurrentSimResultsTest(t, "coverage", result, mtResult, 0.00001)
+}
+
+func TestAPLCoverageReusedEnvironment(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerCoverage())
+	rsr.SimOptions.Iterations = 5
+
+	env := &core.Environment{}
+	first := core.RunSimWithEnv(env, rsr, nil, false, nil)
+	if first.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", first.ErrorResult)
+	}
+	second := core.RunSimWithEnv(env, rsr, nil, false, nil)
+	if second.ErrorResult != "" {
+		t.Fatalf("sim in the reused environment failed: %s", second.ErrorResult)
+	}
+
+	// Coverage from a reused environment only counts its own iterations.
+	firstStats := first.RaidMetrics.Parties[0].Players[0].RotationStats
+	secondStats := second.RaidMetrics.Parties[0].Players[0].RotationStats
+	for i, item := range firstStats.PriorityList {
+		secondItem := secondStats.PriorityList[i]
+		if secondItem.Executions != item.Executions || secondItem.IterationsExecuted != item.IterationsExecuted || secondItem.FirstExecutedSecondsAvg != item.FirstExecutedSecondsAvg {
+			t.Errorf("expected the coverage %v of item %d from the reused environment to match %v", secondItem, i, item)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 type UnitReference struct {
 	fixedUnit       *Unit
 	curTargetSource *Unit
+	candidateSource *Unit
 }
 
 func (ur UnitReference) Get() *Unit {
@@ -16,6 +17,8 @@ func (ur UnitReference) Get() *Unit {
 		return ur.fixedUnit
 	} else if ur.curTargetSource != nil {
 		return ur.curTargetSource.CurrentTarget
+	} else if ur.candidateSource != nil {
+		return ur.candidateSource.targetCandidate()
 	} else {
 		return nil
 	}
@@ -32,6 +35,10 @@ func NewUnitReference(ref *proto.UnitReference, contextUnit *Unit) UnitReference
 		return UnitReference{
 			curTargetSource: contextUnit,
 		}
+	} else if ref.Type == proto.UnitReference_Candidate {
+		return UnitReference{
+			candidateSource: contextUnit,
+		}
 	} else {
 		return UnitReference{
 			fixedUnit: contextUnit.GetUnit(ref),
@@ -60,15 +67,15 @@ func (rot *APLRotation) GetTargetUnit(ref *proto.UnitReference) UnitReference {
 type AuraReference struct {
 	fixedAura *Aura
 
-	curTargetSource *Unit
-	curTargetAuras  AuraArray
+	dynamicSource UnitReference
+	dynamicAuras  AuraArray
 }
 
 func (ar *AuraReference) Get() *Aura {
 	if ar.fixedAura != nil {
 		return ar.fixedAura
-	} else if ar.curTargetSource != nil {
-		return ar.curTargetAuras.Get(ar.curTargetSource.CurrentTarget)
+	} else if ar.dynamicAuras != nil {
+		return ar.dynamicAuras.Get(ar.dynamicSource.Get())
 	} else {
 		return nil
 	}
@@ -91,8 +98,8 @@ func newAuraReferenceHelper(sourceUnit UnitReference, auraId *proto.ActionID, au
 			auras[unit.UnitIndex] = auraGetter(unit, ProtoToActionID(auraId))
 		}
 		return AuraReference{
-			curTargetSource: sourceUnit.curTargetSource,
-			curTargetAuras:  auras,
+			dynamicSource: sourceUnit,
+			dynamicAuras:  auras,
 		}
 	}
 }
@@ -180,19 +187,43 @@ func (rot *APLRotation) GetTargetAPLSpell(spellId *proto.ActionID, targetUnit Un
 	return spell
 }
 
-func (rot *APLRotation) GetAPLDot(targetUnit UnitReference, spellId *proto.ActionID) *Dot {
+// Struct for handling dot references, to account for targets that can change
+// dynamically (e.g. Candidate).
+type DotReference struct {
+	fixedDot *Dot
+
+	spell         *Spell
+	dynamicTarget UnitReference
+}
+
+func (dr *DotReference) Get() *Dot {
+	if dr.fixedDot != nil {
+		return dr.fixedDot
+	} else if dr.spell != nil {
+		return dr.spell.Dot(dr.dynamicTarget.Get())
+	} else {
+		return nil
+	}
+}
+
+func (rot *APLRotation) GetAPLDot(targetUnit UnitReference, spellId *proto.ActionID) DotReference {
 	spell := rot.GetAPLSpell(spellId)
 
 	if spell == nil {
-		return nil
+		return DotReference{}
 	} else if spell.AOEDot() != nil {
-		return spell.AOEDot()
+		return DotReference{fixedDot: spell.AOEDot()}
+	} else if targetUnit.candidateSource != nil {
+		if spell.CurDot() == nil {
+			return DotReference{}
+		}
+		return DotReference{spell: spell, dynamicTarget: targetUnit}
 	} else {
 		target := targetUnit.Get()
 		if target != nil {
-			return spell.Dot(target)
+			return DotReference{fixedDot: spell.Dot(target)}
 		} else {
-			return spell.CurDot()
+			return DotReference{fixedDot: spell.CurDot()}
 		}
 	}
 }
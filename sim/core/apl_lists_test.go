@@ -0,0 +1,155 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+const (
+	steadyShotID = 56641
+	arcaneShotID = 3044
+	rapidFireID  = 3045
+)
+
+func castsBySpell(result *proto.RaidSimResult) map[int32]int32 {
+	casts := map[int32]int32{}
+	for _, action := range result.RaidMetrics.Parties[0].Players[0].Actions {
+		for _, target := range action.Targets {
+			casts[action.Id.GetSpellId()] += target.Casts
+		}
+	}
+	return casts
+}
+
+func TestAPLVariablesAndActionLists(t *testing.T) {
+	player := getTestPlayerMM()
+	player.DistanceFromTarget = 20
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"variables": [
+			{"name": "always", "value": {"const": {"val": "true"}}},
+			{"name": "alsoAlways", "value": {"variable": {"name": "always"}}}
+		],
+		"actionLists": [
+			{"name": "empty", "items": []},
+			{"name": "main", "items": [
+				{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+			]}
+		],
+		"priorityList": [
+			{"action": {"condition": {"variable": {"name": "alsoAlways"}}, "callActionList": {"listName": "empty"}}},
+			{"action": {"condition": {"not": {"val": {"variable": {"name": "always"}}}}, "runActionList": {"listName": "main"}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 3044}}}},
+			{"action": {"runActionList": {"listName": "main"}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	rsr.SimOptions.Iterations = 5
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+
+	// The empty called list falls through, the disabled run is skipped, so
+	// Arcane Shot is used whenever it is ready and Steady Shot fills.
+	casts := castsBySpell(result)
+	if casts[arcaneShotID] == 0 || casts[steadyShotID] == 0 {
+		t.Fatalf("expected both Arcane Shot and Steady Shot casts, got %v", casts)
+	}
+
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"actionLists": [
+			{"name": "empty", "items": []}
+		],
+		"priorityList": [
+			{"action": {"runActionList": {"listName": "empty"}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 3044}}}}
+		]
+	}`)
+	result = core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+
+	// Running a list never continues past it.
+	if casts := castsBySpell(result); casts[arcaneShotID] != 0 {
+		t.Fatalf("expected no Arcane Shot casts after running an empty list, got %d", casts[arcaneShotID])
+	}
+}
+
+func TestAPLVariablesAndActionListsWarnings(t *testing.T) {
+	player := getTestPlayerMM()
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"variables": [
+			{"name": "a", "value": {"variable": {"name": "b"}}},
+			{"name": "b", "value": {"variable": {"name": "a"}}}
+		],
+		"actionLists": [
+			{"name": "loop", "items": [
+				{"action": {"callActionList": {"listName": "loop"}}}
+			]}
+		],
+		"priorityList": [
+			{"action": {"condition": {"variable": {"name": "missing"}}, "castSpell": {"spellId": {"spellId": 3044}}}},
+			{"action": {"callActionList": {"listName": "missing"}}},
+			{"action": {"callActionList": {"listName": "loop"}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	result := core.ComputeStats(&proto.ComputeStatsRequest{Raid: rsr.Raid, Encounter: rsr.Encounter})
+	if result.ErrorResult != "" {
+		t.Fatalf("compute stats failed: %s", result.ErrorResult)
+	}
+	stats := result.RaidStats.Parties[0].Players[0].RotationStats
+
+	for i, item := range stats.PriorityList[:2] {
+		if len(item.Warnings) == 0 {
+			t.Errorf("expected a warning for undefined name in priority list item %d", i)
+		}
+	}
+	if len(stats.Variables[1].Warnings) == 0 {
+		t.Errorf("expected a warning for the variable cycle")
+	}
+	if len(stats.ActionLists[0].Warnings) == 0 {
+		t.Errorf("expected a warning for the action list cycle")
+	}
+}
+
+func TestAPLVariableCaching(t *testing.T) {
+	player := getTestPlayerMM()
+	player.DistanceFromTarget = 20
+	// The first item reads the variable before Rapid Fire is used, so a value
+	// cached for the whole decision would block Arcane Shot in the sequence.
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"variables": [
+			{"name": "rapidFire", "value": {"auraIsActive": {"auraId": {"spellId": 3045}}}}
+		],
+		"priorityList": [
+			{"action": {"condition": {"variable": {"name": "rapidFire"}}, "castSpell": {"spellId": {"spellId": 3045}}}},
+			{"action": {"strictSequence": {"actions": [
+				{"castSpell": {"spellId": {"spellId": 3045}}},
+				{"condition": {"variable": {"name": "rapidFire"}}, "castSpell": {"spellId": {"spellId": 3044}}}
+			]}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	rsr.SimOptions.Iterations = 5
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+
+	casts := castsBySpell(result)
+	if casts[rapidFireID] == 0 || casts[arcaneShotID] != casts[rapidFireID] {
+		t.Fatalf("expected an Arcane Shot after every Rapid Fire, got %v", casts)
+	}
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// Time at which a prepull potion is taken, unless the APL says otherwise.
+const defaultPrepotTime = -time.Second
+
+// A prepull action, with the timings used by auto prepull timing.
+type aplPrepullPlanItem struct {
+	prepullIdx int
+	action     *APLAction
+	doAt       time.Duration
+	autoTimed  bool
+	isPotion   bool
+
+	castTime    time.Duration // Time from starting the action until it goes off.
+	executeTime time.Duration // Time from starting the action until the next one can start.
+	travelTime  time.Duration // Time from the action going off until it lands.
+}
+
+func newPrepullPlanItem(prepullIdx int, action *APLAction) *aplPrepullPlanItem {
+	item := &aplPrepullPlanItem{
+		prepullIdx: prepullIdx,
+		action:     action,
+	}
+
+	spell, target := action.getSpell()
+	if spell == nil {
+		return item
+	}
+
+	// Combat auras aren't active yet, so this uses the character's stats from
+	// before the encounter starts.
+	hastedCast := spell.DefaultCast
+	hastedCast.GCD = spell.Unit.ApplyCastSpeed(hastedCast.GCD)
+	hastedCast.CastTime = spell.CastTime()
+
+	item.castTime = hastedCast.CastTime
+	item.executeTime = hastedCast.EffectiveTime()
+	if spell.Flags.Matches(SpellFlagChanneled) && target != nil {
+		item.executeTime = max(item.executeTime, spell.ExpectedExecuteTime(target))
+	}
+	item.travelTime = spell.TravelTime()
+	item.isPotion = spell.Flags.Matches(SpellFlagPotion)
+	return item
+}
+
+// Whether the two actions can't both be done at their times. Actions which
+// take no time only conflict with a cast in progress, because actions at the
+// same time are done in schedule order, which puts them first.
+func (item *aplPrepullPlanItem) conflictsWith(other *aplPrepullPlanItem) bool {
+	if item.executeTime == 0 {
+		return other.doAt < item.doAt && item.doAt < other.doAt+other.castTime
+	}
+	if other.executeTime == 0 {
+		return other.conflictsWith(item)
+	}
+	return item.doAt < other.doAt+other.executeTime && other.doAt < item.doAt+item.executeTime
+}
+
+// Moves the action earlier until it doesn't conflict with any of the others.
+func (item *aplPrepullPlanItem) moveBefore(others []*aplPrepullPlanItem) {
+	for moved := true; moved; {
+		moved = false
+		for _, other := range others {
+			if item.conflictsWith(other) {
+				item.doAt = other.doAt - item.executeTime
+				moved = true
+			}
+		}
+	}
+}
+
+// Back-computes when to start each auto timed prepull action, so that they
+// happen back to back in order and the last one lands at pull. Actions are
+// moved earlier where they would overlap an action with a fixed time.
+//
+// Potions are off the GCD and their buff should last as far into the fight as
+// possible, so they are left out of the order and taken at the default prepot
+// time instead, or just before whatever is being cast then.
+func planPrepullActions(items []*aplPrepullPlanItem, fixedItems []*aplPrepullPlanItem) {
+	planned := slices.Clone(fixedItems)
+	var potions []*aplPrepullPlanItem
+	var next *aplPrepullPlanItem
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if item.isPotion {
+			potions = append(potions, item)
+			continue
+		}
+		if next == nil {
+			item.doAt = -(item.castTime + item.travelTime)
+		} else {
+			item.doAt = next.doAt - item.executeTime
+		}
+		item.moveBefore(planned)
+		planned = append(planned, item)
+		next = item
+	}
+
+	for _, potion := range potions {
+		potion.doAt = defaultPrepotTime
+		potion.moveBefore(planned)
+		planned = append(planned, potion)
+	}
+}
+
+// Orders prepull actions by time, with actions that take no time first among
+// those at the same time, so they aren't blocked by a cast starting then.
+func comparePrepullPlanItems(item1, item2 *aplPrepullPlanItem) int {
+	if c := cmp.Compare(item1.doAt, item2.doAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(TernaryInt(item1.executeTime > 0, 1, 0), TernaryInt(item2.executeTime > 0, 1, 0))
+}
+
+// Returns whether a prepull 'Do At' value was left empty.
+func isEmptyDoAt(config *proto.APLValue) bool {
+	if config == nil || config.Value == nil {
+		return true
+	}
+	constValue, ok := config.Value.(*proto.APLValue_Const)
+	return ok && constValue.Const.Val == ""
+}
+
+func (rot *APLRotation) registerPrepullAction(prepullIdx int, action *APLAction, doAt time.Duration, autoTimed bool) {
+	rot.prepullSchedule = append(rot.prepullSchedule, &proto.APLPrepullScheduleEntry{
+		PrepullActionIdx: int32(prepullIdx),
+		DoAtSeconds:      doAt.Seconds(),
+		AutoTimed:        autoTimed,
+	})
+	rot.unit.RegisterPrepullAction(doAt, func(sim *Simulation) {
+		// Warnings for prepull cast failure are detected by running a fake prepull,
+		// so this action.Execute needs to record warnings.
+		rot.doAndRecordWarnings(&rot.prepullWarnings[prepullIdx], true, func() {
+			action.Execute(sim)
+		})
+	})
+}
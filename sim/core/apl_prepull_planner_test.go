@@ -0,0 +1,134 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+const aimedShotID = 19434
+
+func TestAPLAutoPrepullTiming(t *testing.T) {
+	player := getTestPlayerMM()
+	player.DistanceFromTarget = 20
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"autoPrepullTiming": true,
+		"prepullActions": [
+			{"action": {"castSpell": {"spellId": {"spellId": 3045}}}, "doAtValue": {"const": {"val": "-3s"}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 19434}}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}, "doAtValue": {"const": {"val": ""}}}
+		],
+		"priorityList": [
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	result := core.ComputeStats(&proto.ComputeStatsRequest{Raid: rsr.Raid, Encounter: rsr.Encounter})
+	if result.ErrorResult != "" {
+		t.Fatalf("compute stats failed: %s", result.ErrorResult)
+	}
+
+	rotationStats := result.RaidStats.Parties[0].Players[0].RotationStats
+	for i, item := range rotationStats.PrepullActions {
+		if len(item.Warnings) != 0 {
+			t.Errorf("expected no warnings for prepull action %d, got %v", i, item.Warnings)
+		}
+	}
+
+	schedule := map[int32]*proto.APLPrepullScheduleEntry{}
+	for _, entry := range rotationStats.PrepullSchedule {
+		schedule[entry.PrepullActionIdx] = entry
+	}
+	if len(schedule) != 3 {
+		t.Fatalf("expected all 3 prepull actions to be scheduled, got %v", rotationStats.PrepullSchedule)
+	}
+
+	rapidFire, aimedShot, steadyShot := schedule[0], schedule[1], schedule[2]
+	if rapidFire.AutoTimed || rapidFire.DoAtSeconds != -3 {
+		t.Errorf("expected Rapid Fire to keep its 'Do At' value, got %v", rapidFire)
+	}
+	if !aimedShot.AutoTimed || !steadyShot.AutoTimed {
+		t.Fatalf("expected actions without a 'Do At' value to be auto timed")
+	}
+	if steadyShot.DoAtSeconds >= 0 {
+		t.Errorf("expected Steady Shot to start before pull so its cast finishes at pull, got %0.2fs", steadyShot.DoAtSeconds)
+	}
+	// Aimed Shot has a longer cast than the GCD, so Steady Shot starts once it finishes.
+	if gap := steadyShot.DoAtSeconds - aimedShot.DoAtSeconds; gap < 1 {
+		t.Errorf("expected Aimed Shot to finish casting before Steady Shot starts, got a gap of %0.2fs", gap)
+	}
+
+	rsr.SimOptions.Iterations = 5
+	simResult := core.RunRaidSim(rsr)
+	if simResult.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", simResult.ErrorResult)
+	}
+	if casts := castsBySpell(simResult); casts[aimedShotID] != 5 {
+		t.Errorf("expected one prepull Aimed Shot per iteration, got %d", casts[aimedShotID])
+	}
+}
+
+func TestAPLAutoPrepullTimingAroundFixedActions(t *testing.T) {
+	player := getTestPlayerMM()
+	player.DistanceFromTarget = 20
+	player.Consumes.PrepopPotion = proto.Potions_PotionOfTheTolvir
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"autoPrepullTiming": true,
+		"prepullActions": [
+			{"action": {"castSpell": {"spellId": {"otherId": "OtherActionPotion"}}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 19434}}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}, "doAtValue": {"const": {"val": "-5s"}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+		],
+		"priorityList": [
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	result := core.ComputeStats(&proto.ComputeStatsRequest{Raid: rsr.Raid, Encounter: rsr.Encounter})
+	if result.ErrorResult != "" {
+		t.Fatalf("compute stats failed: %s", result.ErrorResult)
+	}
+
+	rotationStats := result.RaidStats.Parties[0].Players[0].RotationStats
+	for i, item := range rotationStats.PrepullActions {
+		if len(item.Warnings) != 0 {
+			t.Errorf("expected no warnings for prepull action %d, got %v", i, item.Warnings)
+		}
+	}
+
+	schedule := map[int32]*proto.APLPrepullScheduleEntry{}
+	for _, entry := range rotationStats.PrepullSchedule {
+		schedule[entry.PrepullActionIdx] = entry
+	}
+	if len(schedule) != 4 {
+		t.Fatalf("expected all 4 prepull actions to be scheduled, got %v", rotationStats.PrepullSchedule)
+	}
+
+	potion, aimedShot, fixedSteadyShot, steadyShot := schedule[0], schedule[1], schedule[2], schedule[3]
+	if fixedSteadyShot.DoAtSeconds != -5 {
+		t.Errorf("expected the fixed Steady Shot to keep its 'Do At' value, got %v", fixedSteadyShot)
+	}
+	// Aimed Shot would finish after the fixed Steady Shot starts if it went right before the last Steady Shot.
+	if aimedShot.DoAtSeconds >= fixedSteadyShot.DoAtSeconds {
+		t.Errorf("expected Aimed Shot to be moved before the fixed Steady Shot, got %0.2fs", aimedShot.DoAtSeconds)
+	}
+	// The potion is listed first, but is taken as late as possible.
+	if potion.DoAtSeconds < steadyShot.DoAtSeconds || potion.DoAtSeconds >= 0 {
+		t.Errorf("expected the potion to be taken during the last cast before pull, got %0.2fs", potion.DoAtSeconds)
+	}
+
+	rsr.SimOptions.Iterations = 5
+	simResult := core.RunRaidSim(rsr)
+	if simResult.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", simResult.ErrorResult)
+	}
+	if casts := castsBySpell(simResult); casts[aimedShotID] != 5 {
+		t.Errorf("expected one prepull Aimed Shot per iteration, got %d", casts[aimedShotID])
+	}
+}
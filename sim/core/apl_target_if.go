@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// Chooses the target of a cast action by evaluating a value for each candidate.
+type aplTargetIf struct {
+	rot      *APLRotation
+	mode     proto.APLTargetIf_Mode
+	value    APLValue
+	friendly bool
+}
+
+func (rot *APLRotation) newTargetIf(config *proto.APLTargetIf, friendly bool) *aplTargetIf {
+	if config == nil {
+		return nil
+	}
+
+	valueType := proto.APLValueType_ValueTypeFloat
+	if config.Mode == proto.APLTargetIf_ModeFirst {
+		valueType = proto.APLValueType_ValueTypeBool
+	}
+	value := rot.coerceTo(rot.newAPLValue(config.Value), valueType)
+	if value == nil {
+		rot.ValidationWarning("Target If must provide a value, ignoring it")
+		return nil
+	}
+
+	return &aplTargetIf{
+		rot:      rot,
+		mode:     config.Mode,
+		value:    value,
+		friendly: friendly,
+	}
+}
+
+func (targetIf *aplTargetIf) candidates(sim *Simulation) []*Unit {
+	if targetIf.friendly {
+		return FilterSlice(sim.Raid.AllPlayerUnits, func(unit *Unit) bool { return unit.IsActive() })
+	}
+	return MapSlice(sim.Encounter.ActiveTargets, func(target *Target) *Unit { return &target.Unit })
+}
+
+// Returns the chosen target among the candidates for which canCast is true, or
+// nil if there is none.
+func (targetIf *aplTargetIf) selectTarget(sim *Simulation, canCast func(*Unit) bool) *Unit {
+	rot := targetIf.rot
+	prevCandidate := rot.targetCandidate
+	defer func() {
+		rot.targetCandidate = prevCandidate
+	}()
+
+	var best *Unit
+	var bestValue float64
+	for _, candidate := range targetIf.candidates(sim) {
+		if !canCast(candidate) {
+			continue
+		}
+
+		rot.targetCandidate = candidate
+		switch targetIf.mode {
+		case proto.APLTargetIf_ModeMin, proto.APLTargetIf_ModeMax:
+			value := targetIf.value.GetFloat(sim)
+			if best == nil || (targetIf.mode == proto.APLTargetIf_ModeMin && value < bestValue) || (targetIf.mode == proto.APLTargetIf_ModeMax && value > bestValue) {
+				best = candidate
+				bestValue = value
+			}
+		default:
+			if targetIf.value.GetBool(sim) {
+				return candidate
+			}
+		}
+	}
+	return best
+}
+
+func (targetIf *aplTargetIf) String() string {
+	return fmt.Sprintf("Target If(%s, %s)", targetIf.mode, targetIf.value)
+}
+
+// Returns the target being evaluated by a target_if in this unit's rotation,
+// or the current target outside of one.
+func (unit *Unit) targetCandidate() *Unit {
+	if unit.Rotation != nil && unit.Rotation.targetCandidate != nil {
+		return unit.Rotation.targetCandidate
+	}
+	return unit.CurrentTarget
+}
@@ -0,0 +1,71 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+const serpentStingID = 1978
+
+func castsByTarget(result *proto.RaidSimResult, spellID int32) map[int32]int32 {
+	casts := map[int32]int32{}
+	for _, action := range result.RaidMetrics.Parties[0].Players[0].Actions {
+		if action.Id.GetSpellId() != spellID {
+			continue
+		}
+		for _, target := range action.Targets {
+			casts[target.UnitIndex] += target.Casts
+		}
+	}
+	return casts
+}
+
+func TestAPLTargetIf(t *testing.T) {
+	player := getTestPlayerMM()
+	player.DistanceFromTarget = 20
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"priorityList": [
+			{"action": {"castSpell": {
+				"spellId": {"spellId": 1978},
+				"targetIf": {"mode": "ModeFirst", "value": {"not": {"val": {"dotIsActive": {"spellId": {"spellId": 1978}, "targetUnit": {"type": "Candidate"}}}}}}
+			}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	rsr.Encounter.Targets = append(rsr.Encounter.Targets, core.NewDefaultTarget())
+	rsr.SimOptions.Iterations = 5
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+
+	// Serpent Sting is kept up on every target, not just the current one.
+	casts := castsByTarget(result, serpentStingID)
+	if casts[0] == 0 || casts[1] == 0 {
+		t.Fatalf("expected Serpent Sting casts on both targets, got %v", casts)
+	}
+}
+
+func TestAPLTargetIfWarnings(t *testing.T) {
+	player := getTestPlayerMM()
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"priorityList": [
+			{"action": {"castSpell": {"spellId": {"spellId": 1978}, "targetIf": {"mode": "ModeMax"}}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	result := core.ComputeStats(&proto.ComputeStatsRequest{Raid: rsr.Raid, Encounter: rsr.Encounter})
+	if result.ErrorResult != "" {
+		t.Fatalf("compute stats failed: %s", result.ErrorResult)
+	}
+	if warnings := result.RaidStats.Parties[0].Players[0].RotationStats.PriorityList[0].Warnings; len(warnings) == 0 {
+		t.Errorf("expected a warning for a target_if without a value")
+	}
+}
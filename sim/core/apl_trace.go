@@ -0,0 +1,162 @@
+package core
+
+import (
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// Per-item stats for a priority list or action list item, summed over all iterations.
+type aplTraceItem struct {
+	listName string // Empty for the priority list.
+	itemIdx  int
+	action   *APLAction
+
+	timesConsidered  int32
+	timesExecuted    int32
+	skipReasonCounts []int32 // Indexed by proto.APLSkipReason.
+}
+
+// Records what the rotation evaluated at each decision point, so users can
+// see why an action wasn't used. Only created when SimOptions.TraceApl is set.
+type aplTrace struct {
+	items      []*aplTraceItem
+	itemsByAPL map[*APLAction]*aplTraceItem
+
+	// Decisions are only recorded for the first iteration, to keep the
+	// result size reasonable.
+	started   bool
+	recording bool
+	decisions []*proto.APLTraceDecision
+}
+
+func (rot *APLRotation) newAPLTrace() *aplTrace {
+	trace := &aplTrace{
+		itemsByAPL: make(map[*APLAction]*aplTraceItem),
+	}
+	addItems := func(listName string, actions []*APLAction, configIdxs []int) {
+		for i, action := range actions {
+			item := &aplTraceItem{
+				listName:         listName,
+				itemIdx:          configIdxs[i],
+				action:           action,
+				skipReasonCounts: make([]int32, len(proto.APLSkipReason_name)),
+			}
+			trace.items = append(trace.items, item)
+			trace.itemsByAPL[action] = item
+		}
+	}
+
+	addItems("", rot.priorityList, rot.priorityListIdxs)
+	for _, list := range rot.actionLists {
+		addItems(list.name, list.actions, list.actionIdxs)
+	}
+	return trace
+}
+
+func (trace *aplTrace) reset() {
+	trace.recording = !trace.started
+	trace.started = true
+}
+
+// Starts a new decision point, if decisions are being recorded.
+func (trace *aplTrace) startDecision(sim *Simulation) {
+	if trace.recording {
+		trace.decisions = append(trace.decisions, &proto.APLTraceDecision{
+			TimeSeconds: sim.CurrentTime.Seconds(),
+		})
+	}
+}
+
+// Records that an item was considered at the current decision point.
+func (trace *aplTrace) consider(action *APLAction, conditionMet bool, selected bool, skipReason proto.APLSkipReason) {
+	item := trace.itemsByAPL[action]
+	if item == nil {
+		return
+	}
+
+	item.timesConsidered++
+	if skipReason != proto.APLSkipReason_SkipReasonNone {
+		item.skipReasonCounts[skipReason]++
+	}
+
+	if trace.recording && len(trace.decisions) > 0 {
+		decision := trace.decisions[len(trace.decisions)-1]
+		decision.Items = append(decision.Items, &proto.APLTraceItem{
+			ListName:     item.listName,
+			ItemIdx:      int32(item.itemIdx),
+			ConditionMet: conditionMet,
+			Selected:     selected,
+			SkipReason:   skipReason,
+		})
+	}
+}
+
+func (trace *aplTrace) execute(action *APLAction) {
+	if item := trace.itemsByAPL[action]; item != nil {
+		item.timesExecuted++
+	}
+}
+
+// Checks whether an action is ready like APLAction.IsReady, recording why
+// it was skipped if it isn't.
+func (rot *APLRotation) traceIsReady(sim *Simulation, action *APLAction) bool {
+	if action.condition != nil && !action.condition.GetBool(sim) {
+		rot.trace.consider(action, false, false, proto.APLSkipReason_SkipReasonConditionFalse)
+		return false
+	}
+	if !action.impl.IsReady(sim) {
+		rot.trace.consider(action, true, false, aplSkipReason(sim, action))
+		return false
+	}
+	rot.trace.consider(action, true, true, proto.APLSkipReason_SkipReasonNone)
+	return true
+}
+
+// Returns why an action whose condition passed isn't ready.
+func aplSkipReason(sim *Simulation, action *APLAction) proto.APLSkipReason {
+	spell, _ := action.getSpell()
+	if spell == nil || !BothTimersReady(spell.CD.Timer, spell.SharedCD.Timer, sim) {
+		return proto.APLSkipReason_SkipReasonNotReady
+	}
+	if spell.outOfRange() {
+		return proto.APLSkipReason_SkipReasonOutOfRange
+	}
+	if spell.Cost != nil {
+		// Same as in CanCast, costs are checked against the default cast.
+		spell.CurCast.Cost = spell.DefaultCast.Cost
+		if !spell.Cost.MeetsRequirement(sim, spell) {
+			return proto.APLSkipReason_SkipReasonNoResource
+		}
+	}
+	return proto.APLSkipReason_SkipReasonNotReady
+}
+
+// Returns the skip reason with the highest count, or None if there are no skips.
+func mostCommonSkipReason(counts []int32) proto.APLSkipReason {
+	reason := proto.APLSkipReason_SkipReasonNone
+	for i, count := range counts {
+		if count > 0 && (reason == proto.APLSkipReason_SkipReasonNone || count > counts[reason]) {
+			reason = proto.APLSkipReason(i)
+		}
+	}
+	return reason
+}
+
+func (trace *aplTrace) toProto() *proto.APLTrace {
+	if trace == nil {
+		return nil
+	}
+	return &proto.APLTrace{
+		Decisions: trace.decisions,
+		Items: MapSlice(trace.items, func(item *aplTraceItem) *proto.APLTraceItemSummary {
+			return &proto.APLTraceItemSummary{
+				ListName:             item.listName,
+				ItemIdx:              int32(item.itemIdx),
+				Action:               item.action.impl.String(),
+				TimesConsidered:      item.timesConsidered,
+				TimesExecuted:        item.timesExecuted,
+				SkipReasonCounts:     item.skipReasonCounts,
+				MostCommonSkipReason: mostCommonSkipReason(item.skipReasonCounts),
+			}
+		}),
+	}
+}
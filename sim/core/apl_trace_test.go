@@ -0,0 +1,132 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+func TestAPLTrace(t *testing.T) {
+	player := getTestPlayerMM()
+	player.DistanceFromTarget = 20
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"actionLists": [
+			{"name": "filler", "items": [
+				{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+			]}
+		],
+		"priorityList": [
+			{"action": {"condition": {"const": {"val": "false"}}, "castSpell": {"spellId": {"spellId": 3044}}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 3044}}}},
+			{"action": {"callActionList": {"listName": "filler"}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	rsr.SimOptions.Iterations = 5
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+	if trace := result.RaidMetrics.Parties[0].Players[0].AplTrace; trace != nil {
+		t.Fatalf("expected no trace unless enabled")
+	}
+
+	rsr.SimOptions.TraceApl = true
+	result = core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+	trace := result.RaidMetrics.Parties[0].Players[0].AplTrace
+	if trace == nil || len(trace.Decisions) == 0 {
+		t.Fatalf("expected a decision trace")
+	}
+
+	for _, decision := range trace.Decisions {
+		if decision.TimeSeconds < 0 || decision.TimeSeconds > float64(rsr.Encounter.Duration) {
+			t.Fatalf("expected decisions from the first iteration only, got one at %0.2fs", decision.TimeSeconds)
+		}
+		first := decision.Items[0]
+		if first.ListName != "" || first.ItemIdx != 0 || first.ConditionMet || first.SkipReason != proto.APLSkipReason_SkipReasonConditionFalse {
+			t.Fatalf("expected the first item to be skipped for its condition, got %v", first)
+		}
+	}
+
+	if len(trace.Items) != 4 {
+		t.Fatalf("expected a summary for each item, got %d", len(trace.Items))
+	}
+	disabled, arcaneShot, callFiller, steadyShot := trace.Items[0], trace.Items[1], trace.Items[2], trace.Items[3]
+	if disabled.TimesExecuted != 0 || disabled.MostCommonSkipReason != proto.APLSkipReason_SkipReasonConditionFalse {
+		t.Errorf("expected the disabled item to never be used, got %v", disabled)
+	}
+	if arcaneShot.TimesExecuted == 0 || arcaneShot.MostCommonSkipReason != proto.APLSkipReason_SkipReasonNoResource {
+		t.Errorf("expected Arcane Shot to be used and otherwise skipped for focus, got %v", arcaneShot)
+	}
+	if callFiller.TimesConsidered == 0 || callFiller.TimesExecuted != 0 {
+		t.Errorf("expected the filler list to be entered but not executed itself, got %v", callFiller)
+	}
+	if steadyShot.ListName != "filler" || steadyShot.TimesExecuted == 0 {
+		t.Errorf("expected Steady Shot to be used from the filler list, got %v", steadyShot)
+	}
+	// Queued casts can be executed again before they go off, so there may be more executions than casts.
+	if casts := castsBySpell(result); arcaneShot.TimesExecuted < casts[arcaneShotID] {
+		t.Errorf("expected at least %d Arcane Shot executions, got %d", casts[arcaneShotID], arcaneShot.TimesExecuted)
+	}
+
+	// Summaries from concurrent sims are summed over all of their iterations.
+	mtResult := core.RunConcurrentRaidSimSync(rsr)
+	if mtResult.ErrorResult != "" {
+		t.Fatalf("concurrent sim failed: %s", mtResult.ErrorResult)
+	}
+	mtTrace := mtResult.RaidMetrics.Parties[0].Players[0].AplTrace
+	if mtTrace == nil || len(mtTrace.Decisions) == 0 {
+		t.Fatalf("expected a decision trace from the concurrent sim")
+	}
+	for i, item := range trace.Items {
+		mtItem := mtTrace.Items[i]
+		if mtItem.TimesConsidered != item.TimesConsidered || mtItem.TimesExecuted != item.TimesExecuted || mtItem.MostCommonSkipReason != item.MostCommonSkipReason {
+			t.Errorf("expected concurrent summary %v to match %v", mtItem, item)
+		}
+	}
+}
+
+func TestAPLTraceReusedEnvironment(t *testing.T) {
+	player := getTestPlayerMM()
+	player.DistanceFromTarget = 20
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"priorityList": [
+			{"action": {"castSpell": {"spellId": {"spellId": 3044}}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	rsr.SimOptions.Iterations = 5
+	rsr.SimOptions.TraceApl = true
+
+	env := &core.Environment{}
+	first := core.RunSimWithEnv(env, rsr, nil, false, nil)
+	if first.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", first.ErrorResult)
+	}
+	second := core.RunSimWithEnv(env, rsr, nil, false, nil)
+	if second.ErrorResult != "" {
+		t.Fatalf("sim in the reused environment failed: %s", second.ErrorResult)
+	}
+
+	// A reused environment starts a new trace, instead of adding to the previous one.
+	firstTrace := first.RaidMetrics.Parties[0].Players[0].AplTrace
+	secondTrace := second.RaidMetrics.Parties[0].Players[0].AplTrace
+	if len(secondTrace.Decisions) != len(firstTrace.Decisions) {
+		t.Errorf("expected %d decisions from the reused environment, got %d", len(firstTrace.Decisions), len(secondTrace.Decisions))
+	}
+	for i, item := range firstTrace.Items {
+		secondItem := secondTrace.Items[i]
+		if secondItem.TimesConsidered != item.TimesConsidered || secondItem.TimesExecuted != item.TimesExecuted {
+			t.Errorf("expected the summary %v from the reused environment to match %v", secondItem, item)
+		}
+	}
+}
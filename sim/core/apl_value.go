@@ -91,6 +91,10 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 		return rot.newValueRemainingTimePercent(config.GetRemainingTimePercent())
 	case *proto.APLValue_IsExecutePhase:
 		return rot.newValueIsExecutePhase(config.GetIsExecutePhase())
+	case *proto.APLValue_IsExecutePhaseCustom:
+		return rot.newValueIsExecutePhaseCustom(config.GetIsExecutePhaseCustom())
+	case *proto.APLValue_TimeToDie:
+		return rot.newValueTimeToDie(config.GetTimeToDie())
 	case *proto.APLValue_NumberTargets:
 		return rot.newValueNumberTargets(config.GetNumberTargets())
 
@@ -119,6 +123,12 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 		return rot.newValueCurrentComboPoints(config.GetCurrentComboPoints())
 	case *proto.APLValue_CurrentRunicPower:
 		return rot.newValueCurrentRunicPower(config.GetCurrentRunicPower())
+	case *proto.APLValue_ResourceTimeToAmount:
+		return rot.newValueResourceTimeToAmount(config.GetResourceTimeToAmount())
+	case *proto.APLValue_ResourceAtTime:
+		return rot.newValueResourceAtTime(config.GetResourceAtTime())
+	case *proto.APLValue_ResourceDeficit:
+		return rot.newValueResourceDeficit(config.GetResourceDeficit())
 
 	// Resources Runes
 	case *proto.APLValue_CurrentRuneCount:
@@ -171,6 +181,10 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 		return rot.newValueSpellChanneledTicks(config.GetSpellChanneledTicks())
 	case *proto.APLValue_SpellCurrentCost:
 		return rot.newValueSpellCurrentCost(config.GetSpellCurrentCost())
+	case *proto.APLValue_SpellExpectedDamage:
+		return rot.newValueSpellExpectedDamage(config.GetSpellExpectedDamage())
+	case *proto.APLValue_SpellDpet:
+		return rot.newValueSpellDPET(config.GetSpellDpet())
 
 	// Auras
 	case *proto.APLValue_AuraIsKnown:
@@ -206,6 +220,10 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 	case *proto.APLValue_SequenceTimeToReady:
 		return rot.newValueSequenceTimeToReady(config.GetSequenceTimeToReady())
 
+	// Variable values
+	case *proto.APLValue_Variable:
+		return rot.newValueVariable(config.GetVariable())
+
 	// Properties
 	case *proto.APLValue_ChannelClipDelay:
 		return rot.newValueChannelClipDelay(config.GetChannelClipDelay())
@@ -124,3 +124,87 @@ func (value *APLValueIsExecutePhase) GetBool(sim *Simulation) bool {
 func (value *APLValueIsExecutePhase) String() string {
 	return "Is Execute Phase"
 }
+
+type APLValueIsExecutePhaseCustom struct {
+	DefaultAPLValueImpl
+	target    UnitReference
+	threshold float64
+}
+
+func (rot *APLRotation) newValueIsExecutePhaseCustom(config *proto.APLValueIsExecutePhaseCustom) APLValue {
+	target := rot.GetTargetUnit(config.TargetUnit)
+	if target.Get() == nil {
+		return nil
+	}
+	if target.Get().Type != EnemyUnit {
+		rot.ValidationWarning("%s is not an enemy target", target.Get().Label)
+		return nil
+	}
+	if config.Threshold <= 0 || config.Threshold > 100 {
+		rot.ValidationWarning("Execute phase threshold must be between 0 and 100, got %0.1f", config.Threshold)
+		return nil
+	}
+	return &APLValueIsExecutePhaseCustom{
+		target:    target,
+		threshold: config.Threshold / 100,
+	}
+}
+func (value *APLValueIsExecutePhaseCustom) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeBool
+}
+func (value *APLValueIsExecutePhaseCustom) GetBool(sim *Simulation) bool {
+	return sim.GetTargetHealthPercent(value.target.Get()) <= value.threshold
+}
+func (value *APLValueIsExecutePhaseCustom) String() string {
+	return fmt.Sprintf("Is Execute Phase(%s, %0.1f%%)", value.target.String(), value.threshold*100)
+}
+
+type APLValueTimeToDie struct {
+	DefaultAPLValueImpl
+	target    UnitReference
+	useOracle bool
+}
+
+func (rot *APLRotation) newValueTimeToDie(config *proto.APLValueTimeToDie) APLValue {
+	target := rot.GetTargetUnit(config.TargetUnit)
+	if target.Get() == nil {
+		return nil
+	}
+	if target.Get().Type != EnemyUnit {
+		rot.ValidationWarning("%s is not an enemy target", target.Get().Label)
+		return nil
+	}
+	if !config.UseOracle {
+		targets := rot.unit.Env.Encounter.Targets
+		if target.fixedUnit != nil {
+			targets[target.fixedUnit.Index].enableTimeToDie()
+		} else {
+			for _, t := range targets {
+				t.enableTimeToDie()
+			}
+		}
+	}
+	return &APLValueTimeToDie{
+		target:    target,
+		useOracle: config.UseOracle,
+	}
+}
+func (value *APLValueTimeToDie) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeDuration
+}
+func (value *APLValueTimeToDie) GetDuration(sim *Simulation) time.Duration {
+	// Dynamic references are only validated against their unit at the start,
+	// and unit indices are only target indices for enemies.
+	unit := value.target.Get()
+	if unit == nil || unit.Type != EnemyUnit {
+		return sim.GetRemainingDuration()
+	}
+	target := sim.Encounter.Targets[unit.Index]
+	if value.useOracle {
+		return target.OracleTimeToDie(sim)
+	}
+	return target.TimeToDie(sim)
+}
+func (value *APLValueTimeToDie) String() string {
+	return fmt.Sprintf("Time To Die(%s)", value.target.String())
+}
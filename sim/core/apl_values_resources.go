@@ -2,6 +2,8 @@ package core
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/wowsims/cata/sim/core/proto"
 )
@@ -44,7 +46,7 @@ func (rot *APLRotation) newValueCurrentHealthPercent(config *proto.APLValueCurre
 	if unit.Get() == nil {
 		return nil
 	}
-	if !unit.Get().HasHealthBar() {
+	if unit.Get().Type != EnemyUnit && !unit.Get().HasHealthBar() {
 		rot.ValidationWarning("%s does not use Health", unit.Get().Label)
 		return nil
 	}
@@ -56,7 +58,11 @@ func (value *APLValueCurrentHealthPercent) Type() proto.APLValueType {
 	return proto.APLValueType_ValueTypeFloat
 }
 func (value *APLValueCurrentHealthPercent) GetFloat(sim *Simulation) float64 {
-	return value.unit.Get().CurrentHealthPercent()
+	unit := value.unit.Get()
+	if unit.Type == EnemyUnit {
+		return sim.GetTargetHealthPercent(unit)
+	}
+	return unit.CurrentHealthPercent()
 }
 func (value *APLValueCurrentHealthPercent) String() string {
 	return fmt.Sprintf("Current Health %%")
@@ -245,3 +251,158 @@ func (value *APLValueCurrentRunicPower) GetInt(sim *Simulation) int32 {
 func (value *APLValueCurrentRunicPower) String() string {
 	return "Current Runic Power"
 }
+
+// Forecasts one of the rotation unit's resources from its regen.
+type resourceForecast struct {
+	resource  proto.APLValueResourceType
+	timeUntil func(sim *Simulation, amount float64) time.Duration
+	at        func(sim *Simulation, at time.Duration) float64
+	deficit   func() float64
+}
+
+func (rot *APLRotation) newResourceForecast(resource proto.APLValueResourceType) *resourceForecast {
+	unit := rot.unit
+	runes := func(firstSlot int8) *resourceForecast {
+		if !unit.HasRunicPowerBar() {
+			rot.ValidationWarning("%s does not use Runes", unit.Label)
+			return nil
+		}
+		return &resourceForecast{
+			resource: resource,
+			timeUntil: func(sim *Simulation, amount float64) time.Duration {
+				return unit.TimeUntilRunes(sim, firstSlot, int32(math.Ceil(amount)))
+			},
+			at: func(sim *Simulation, at time.Duration) float64 {
+				return float64(unit.RunesAt(sim, firstSlot, at))
+			},
+			deficit: func() float64 {
+				return float64(unit.RuneDeficit(firstSlot))
+			},
+		}
+	}
+
+	switch resource {
+	case proto.APLValueResourceType_ResourceMana:
+		if !unit.HasManaBar() {
+			rot.ValidationWarning("%s does not use Mana", unit.Label)
+			return nil
+		}
+		return &resourceForecast{resource, unit.TimeUntilMana, unit.ManaAt, unit.ManaDeficit}
+	case proto.APLValueResourceType_ResourceEnergy:
+		if !unit.HasEnergyBar() {
+			rot.ValidationWarning("%s does not use Energy", unit.Label)
+			return nil
+		}
+		return &resourceForecast{resource, unit.TimeUntilEnergy, unit.EnergyAt, unit.EnergyDeficit}
+	case proto.APLValueResourceType_ResourceRage:
+		if !unit.HasRageBar() {
+			rot.ValidationWarning("%s does not use Rage", unit.Label)
+			return nil
+		}
+		return &resourceForecast{resource, unit.TimeUntilRage, unit.RageAt, unit.RageDeficit}
+	case proto.APLValueResourceType_ResourceFocus:
+		if !unit.HasFocusBar() {
+			rot.ValidationWarning("%s does not use Focus", unit.Label)
+			return nil
+		}
+		return &resourceForecast{resource, unit.TimeUntilFocus, unit.FocusAt, unit.FocusDeficit}
+	case proto.APLValueResourceType_ResourceRunicPower:
+		if !unit.HasRunicPowerBar() {
+			rot.ValidationWarning("%s does not use Runic Power", unit.Label)
+			return nil
+		}
+		return &resourceForecast{resource, unit.TimeUntilRunicPower, unit.RunicPowerAt, unit.RunicPowerDeficit}
+	case proto.APLValueResourceType_ResourceBloodRunes:
+		return runes(0)
+	case proto.APLValueResourceType_ResourceFrostRunes:
+		return runes(2)
+	case proto.APLValueResourceType_ResourceUnholyRunes:
+		return runes(4)
+	}
+	rot.ValidationWarning("Missing resource type")
+	return nil
+}
+
+type APLValueResourceTimeToAmount struct {
+	DefaultAPLValueImpl
+	forecast *resourceForecast
+	amount   APLValue
+}
+
+func (rot *APLRotation) newValueResourceTimeToAmount(config *proto.APLValueResourceTimeToAmount) APLValue {
+	forecast := rot.newResourceForecast(config.Resource)
+	amount := rot.coerceTo(rot.newAPLValue(config.Amount), proto.APLValueType_ValueTypeFloat)
+	if forecast == nil || amount == nil {
+		return nil
+	}
+	return &APLValueResourceTimeToAmount{
+		forecast: forecast,
+		amount:   amount,
+	}
+}
+func (value *APLValueResourceTimeToAmount) GetInnerValues() []APLValue {
+	return []APLValue{value.amount}
+}
+func (value *APLValueResourceTimeToAmount) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeDuration
+}
+func (value *APLValueResourceTimeToAmount) GetDuration(sim *Simulation) time.Duration {
+	return value.forecast.timeUntil(sim, value.amount.GetFloat(sim))
+}
+func (value *APLValueResourceTimeToAmount) String() string {
+	return fmt.Sprintf("Time To Resource(%s, %s)", value.forecast.resource, value.amount)
+}
+
+type APLValueResourceAtTime struct {
+	DefaultAPLValueImpl
+	forecast *resourceForecast
+	time     APLValue
+}
+
+func (rot *APLRotation) newValueResourceAtTime(config *proto.APLValueResourceAtTime) APLValue {
+	forecast := rot.newResourceForecast(config.Resource)
+	timeValue := rot.coerceTo(rot.newAPLValue(config.Time), proto.APLValueType_ValueTypeDuration)
+	if forecast == nil || timeValue == nil {
+		return nil
+	}
+	return &APLValueResourceAtTime{
+		forecast: forecast,
+		time:     timeValue,
+	}
+}
+func (value *APLValueResourceAtTime) GetInnerValues() []APLValue {
+	return []APLValue{value.time}
+}
+func (value *APLValueResourceAtTime) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeFloat
+}
+func (value *APLValueResourceAtTime) GetFloat(sim *Simulation) float64 {
+	return value.forecast.at(sim, sim.CurrentTime+max(value.time.GetDuration(sim), 0))
+}
+func (value *APLValueResourceAtTime) String() string {
+	return fmt.Sprintf("Resource At Time(%s, %s)", value.forecast.resource, value.time)
+}
+
+type APLValueResourceDeficit struct {
+	DefaultAPLValueImpl
+	forecast *resourceForecast
+}
+
+func (rot *APLRotation) newValueResourceDeficit(config *proto.APLValueResourceDeficit) APLValue {
+	forecast := rot.newResourceForecast(config.Resource)
+	if forecast == nil {
+		return nil
+	}
+	return &APLValueResourceDeficit{
+		forecast: forecast,
+	}
+}
+func (value *APLValueResourceDeficit) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeFloat
+}
+func (value *APLValueResourceDeficit) GetFloat(sim *Simulation) float64 {
+	return value.forecast.deficit()
+}
+func (value *APLValueResourceDeficit) String() string {
+	return fmt.Sprintf("Resource Deficit(%s)", value.forecast.resource)
+}
@@ -9,13 +9,15 @@ import (
 
 type APLValueSpellIsKnown struct {
 	DefaultAPLValueImpl
-	spell *Spell
+	spellID ActionID
+	spell   *Spell
 }
 
 func (rot *APLRotation) newValueSpellIsKnown(config *proto.APLValueSpellIsKnown) APLValue {
 	spell := rot.GetAPLSpell(config.SpellId)
 	return &APLValueSpellIsKnown{
-		spell: spell,
+		spellID: ProtoToActionID(config.SpellId),
+		spell:   spell,
 	}
 }
 func (value *APLValueSpellIsKnown) Type() proto.APLValueType {
@@ -25,7 +27,7 @@ func (value *APLValueSpellIsKnown) GetBool(sim *Simulation) bool {
 	return value.spell != nil
 }
 func (value *APLValueSpellIsKnown) String() string {
-	return fmt.Sprintf("Is Known(%s)", value.spell.ActionID)
+	return fmt.Sprintf("Is Known(%s)", value.spellID)
 }
 
 type APLValueSpellCanCast struct {
@@ -249,3 +251,79 @@ func (value *APLValueSpellCurrentCost) GetFloat(_ *Simulation) float64 {
 func (value *APLValueSpellCurrentCost) String() string {
 	return fmt.Sprintf("CurrentCost(%s)", value.spell.ActionID)
 }
+
+type APLValueSpellExpectedDamage struct {
+	DefaultAPLValueImpl
+	spell  *Spell
+	target UnitReference
+}
+
+func (rot *APLRotation) newValueSpellExpectedDamage(config *proto.APLValueSpellExpectedDamage) APLValue {
+	spell := rot.GetAPLSpell(config.SpellId)
+	if spell == nil {
+		return nil
+	}
+	if !spell.HasExpectedDamage() {
+		rot.ValidationWarning("%s does not support expected damage", spell.ActionID)
+		return nil
+	}
+	target := rot.GetTargetUnit(config.TargetUnit)
+	if target.Get() == nil {
+		return nil
+	}
+	return &APLValueSpellExpectedDamage{
+		spell:  spell,
+		target: target,
+	}
+}
+func (value *APLValueSpellExpectedDamage) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeFloat
+}
+func (value *APLValueSpellExpectedDamage) GetFloat(sim *Simulation) float64 {
+	return value.spell.ExpectedDamage(sim, value.target.Get())
+}
+func (value *APLValueSpellExpectedDamage) String() string {
+	return fmt.Sprintf("ExpectedDamage(%s, %s)", value.spell.ActionID, value.target.String())
+}
+
+type APLValueSpellDPET struct {
+	DefaultAPLValueImpl
+	spell  *Spell
+	target UnitReference
+}
+
+func (rot *APLRotation) newValueSpellDPET(config *proto.APLValueSpellDpet) APLValue {
+	spell := rot.GetAPLSpell(config.SpellId)
+	if spell == nil {
+		return nil
+	}
+	if !spell.HasExpectedDamage() {
+		rot.ValidationWarning("%s does not support expected damage", spell.ActionID)
+		return nil
+	}
+	target := rot.GetTargetUnit(config.TargetUnit)
+	if target.Get() == nil {
+		return nil
+	}
+	return &APLValueSpellDPET{
+		spell:  spell,
+		target: target,
+	}
+}
+func (value *APLValueSpellDPET) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeFloat
+}
+func (value *APLValueSpellDPET) GetFloat(sim *Simulation) float64 {
+	target := value.target.Get()
+	damage := value.spell.ExpectedDamage(sim, target)
+
+	// Off-GCD instants take no time, so fall back to the minimum GCD rather than dividing by 0.
+	executeTime := value.spell.ExpectedExecuteTime(target)
+	if executeTime <= 0 {
+		executeTime = GCDMin
+	}
+	return damage / executeTime.Seconds()
+}
+func (value *APLValueSpellDPET) String() string {
+	return fmt.Sprintf("DPET(%s, %s)", value.spell.ActionID, value.target.String())
+}
@@ -1,10 +1,12 @@
 package core
 
 import (
+	"math"
 	"testing"
 	"time"
 
 	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/stats"
 )
 
 func TestValueConst(t *testing.T) {
@@ -42,3 +44,108 @@ func TestValueConst(t *testing.T) {
 		t.Fatalf("Unexpected coerced duration value %s", coercedDurVal.GetDuration(sim))
 	}
 }
+
+type countingValue struct {
+	DefaultAPLValueImpl
+	count int32
+}
+
+func (value *countingValue) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeInt
+}
+func (value *countingValue) GetInt(sim *Simulation) int32 {
+	value.count++
+	return value.count
+}
+func (value *countingValue) String() string {
+	return "Counter"
+}
+
+func TestValueVariableCaching(t *testing.T) {
+	sim := &Simulation{}
+	rot := &APLRotation{}
+	counter := &countingValue{}
+	variable := &APLValueVariable{
+		rot:      rot,
+		variable: &aplVariable{name: "counter", value: counter},
+	}
+
+	if variable.GetInt(sim) != 1 || variable.GetInt(sim) != 1 {
+		t.Fatalf("expected the variable to be computed once per decision")
+	}
+
+	rot.decisionID++
+	if variable.GetInt(sim) != 2 {
+		t.Fatalf("expected the variable to be recomputed for a new decision")
+	}
+
+	sim.CurrentTime += time.Second
+	if variable.GetInt(sim) != 3 {
+		t.Fatalf("expected the variable to be recomputed at a new time")
+	}
+
+	variable.variable.reset()
+	if variable.GetInt(sim) != 4 {
+		t.Fatalf("expected the variable to be recomputed after a reset")
+	}
+}
+
+func TestValueRageForecast(t *testing.T) {
+	sim := &Simulation{}
+	target := &Unit{Type: EnemyUnit}
+
+	// Any agent works for looking up the rotation unit, so host it on a target.
+	agent := &Target{}
+	unit := &agent.Unit
+	unit.Env = &Environment{Raid: &Raid{}, Encounter: Encounter{Targets: []*Target{agent}}}
+	unit.CurrentTarget = target
+	unit.currentPowerBar = RageBar
+	unit.rageBar = rageBar{unit: unit, currentRage: 20, mhSwingRage: 10}
+	unit.AttackTables = []*AttackTable{{Attacker: unit, Defender: target, BaseMissChance: 0.15, BaseDodgeChance: 0.1}}
+	unit.AutoAttacks.mh = WeaponAttack{
+		unit:             unit,
+		spell:            &Spell{Unit: unit},
+		enabled:          true,
+		swingAt:          time.Second,
+		curSwingDuration: time.Second * 2,
+	}
+	rot := &APLRotation{unit: unit}
+
+	constValue := func(val string) *proto.APLValue {
+		return &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: val}}}
+	}
+
+	// A quarter of swings are missed or dodged, so each swing at 1s, 3s, 5s, ...
+	// is expected to give 7.5 rage.
+	rageAt := rot.newValueResourceAtTime(&proto.APLValueResourceAtTime{
+		Resource: proto.APLValueResourceType_ResourceRage,
+		Time:     constValue("3s"),
+	})
+	if at := rageAt.GetFloat(sim); math.Abs(at-35) > 1e-9 {
+		t.Fatalf("Expected 35 rage after 2 swings, got %0.2f", at)
+	}
+
+	timeUntil := rot.newValueResourceTimeToAmount(&proto.APLValueResourceTimeToAmount{
+		Resource: proto.APLValueResourceType_ResourceRage,
+		Amount:   constValue("40"),
+	})
+	if ttr := timeUntil.GetDuration(sim); ttr != time.Second*5 {
+		t.Fatalf("Expected 3 swings to reach 40 rage at 5s, got %s", ttr)
+	}
+
+	deficit := rot.newValueResourceDeficit(&proto.APLValueResourceDeficit{
+		Resource: proto.APLValueResourceType_ResourceRage,
+	})
+	if d := deficit.GetFloat(sim); d != 80 {
+		t.Fatalf("Expected a deficit of 80 rage, got %0.2f", d)
+	}
+
+	// Expertise removes the dodge chance.
+	unit.stats[stats.Expertise] = 0.1 * ExpertisePerQuarterPercentReduction * 400
+	if ttr := timeUntil.GetDuration(sim); ttr != time.Second*5 {
+		t.Fatalf("Expected 3 swings to reach 40 rage at 5s, got %s", ttr)
+	}
+	if at := rageAt.GetFloat(sim); math.Abs(at-37) > 1e-9 {
+		t.Fatalf("Expected 37 rage after 2 swings without dodges, got %0.2f", at)
+	}
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// A named value shared between APL actions. The value is computed at most once
+// per rotation decision, no matter how many actions reference it.
+type aplVariable struct {
+	name      string
+	configIdx int
+	config    *proto.APLValue
+	value     APLValue
+
+	parsing bool
+	parsed  bool
+
+	// Cache of the most recently computed value.
+	cachedDecision int
+	cachedAt       time.Duration
+	hasCache       bool
+	boolVal        bool
+	intVal         int32
+	floatVal       float64
+	durationVal    time.Duration
+	stringVal      string
+}
+
+func (rot *APLRotation) registerVariables(configs []*proto.APLVariable) {
+	for i, config := range configs {
+		rot.doAndRecordWarnings(&rot.variableWarnings[i], false, func() {
+			if config.Name == "" {
+				rot.ValidationWarning("Variables must have a name")
+				return
+			}
+			if rot.variables[config.Name] != nil {
+				rot.ValidationWarning("Duplicate variable name: '%s'", config.Name)
+				return
+			}
+			rot.variables[config.Name] = &aplVariable{
+				name:      config.Name,
+				configIdx: i,
+				config:    config.Value,
+			}
+		})
+	}
+}
+
+// Parses the value of the named variable if it hasn't been parsed yet. Variables
+// are parsed on first use, because referencing values need to know their type.
+func (rot *APLRotation) getVariable(name string) *aplVariable {
+	variable := rot.variables[name]
+	if variable == nil {
+		rot.ValidationWarning("No variable with name: '%s'", name)
+		return nil
+	}
+	if variable.parsing {
+		rot.ValidationWarning("Variable '%s' is part of a reference cycle", name)
+		return nil
+	}
+	if !variable.parsed {
+		// Attribute warnings from the variable's value to the variable itself,
+		// rather than to whichever action happened to reference it first.
+		outerWarnings, outerPrepull := rot.curWarnings, rot.parsingPrepull
+		rot.curWarnings = nil
+
+		variable.parsing = true
+		rot.doAndRecordWarnings(&rot.variableWarnings[variable.configIdx], false, func() {
+			variable.value = rot.newAPLValue(variable.config)
+			if variable.value == nil {
+				rot.ValidationWarning("Variable '%s' has no valid value", name)
+			}
+		})
+		variable.parsing = false
+		variable.parsed = true
+
+		rot.curWarnings, rot.parsingPrepull = outerWarnings, outerPrepull
+	}
+	if variable.value == nil {
+		return nil
+	}
+	return variable
+}
+
+func (variable *aplVariable) isCached(sim *Simulation, rot *APLRotation) bool {
+	if sim == nil {
+		return false
+	}
+	if variable.hasCache && variable.cachedDecision == rot.decisionID && variable.cachedAt == sim.CurrentTime {
+		return true
+	}
+	variable.hasCache = true
+	variable.cachedDecision = rot.decisionID
+	variable.cachedAt = sim.CurrentTime
+	return false
+}
+
+func (variable *aplVariable) reset() {
+	variable.hasCache = false
+}
+
+type APLValueVariable struct {
+	DefaultAPLValueImpl
+	rot      *APLRotation
+	variable *aplVariable
+}
+
+func (rot *APLRotation) newValueVariable(config *proto.APLValueVariable) APLValue {
+	if config.Name == "" {
+		rot.ValidationWarning("Variable() must provide a variable name")
+		return nil
+	}
+	variable := rot.getVariable(config.Name)
+	if variable == nil {
+		return nil
+	}
+	return &APLValueVariable{
+		rot:      rot,
+		variable: variable,
+	}
+}
+func (value *APLValueVariable) Type() proto.APLValueType {
+	return value.variable.value.Type()
+}
+func (value *APLValueVariable) GetBool(sim *Simulation) bool {
+	if !value.variable.isCached(sim, value.rot) {
+		value.variable.boolVal = value.variable.value.GetBool(sim)
+	}
+	return value.variable.boolVal
+}
+func (value *APLValueVariable) GetInt(sim *Simulation) int32 {
+	if !value.variable.isCached(sim, value.rot) {
+		value.variable.intVal = value.variable.value.GetInt(sim)
+	}
+	return value.variable.intVal
+}
+func (value *APLValueVariable) GetFloat(sim *Simulation) float64 {
+	if !value.variable.isCached(sim, value.rot) {
+		value.variable.floatVal = value.variable.value.GetFloat(sim)
+	}
+	return value.variable.floatVal
+}
+func (value *APLValueVariable) GetDuration(sim *Simulation) time.Duration {
+	if !value.variable.isCached(sim, value.rot) {
+		value.variable.durationVal = value.variable.value.GetDuration(sim)
+	}
+	return value.variable.durationVal
+}
+func (value *APLValueVariable) GetString(sim *Simulation) string {
+	if !value.variable.isCached(sim, value.rot) {
+		value.variable.stringVal = value.variable.value.GetString(sim)
+	}
+	return value.variable.stringVal
+}
+func (value *APLValueVariable) String() string {
+	return fmt.Sprintf("Variable(%s)", value.variable.name)
+}
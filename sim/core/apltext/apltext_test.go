@@ -0,0 +1,192 @@
+package apltext
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	goproto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+func TestParse(t *testing.T) {
+	rotation, err := Parse(`# Comments and blank lines are ignored.
+
+variable.execute = is_execute_phase(threshold=E20)
+prepull+=/cast_spell(spell_id=other:OtherActionPotion) at -1.5s
+actions+=/call_action_list(list_name="aoe") if number_targets() > 2 && !variable(name="execute")
+actions+=/hidden cast_spell(spell_id=spell:2825:-1) # Bloodlust
+actions.aoe+=/multidot(spell_id=spell:1978, max_dots=3, max_overlap=0.5 - current_time() * 2)
+actions.empty=
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &proto.APLRotation{
+		Type: proto.APLRotation_TypeAPL,
+		Variables: []*proto.APLVariable{{
+			Name:  "execute",
+			Value: &proto.APLValue{Value: &proto.APLValue_IsExecutePhase{IsExecutePhase: &proto.APLValueIsExecutePhase{Threshold: proto.APLValueIsExecutePhase_E20}}},
+		}},
+		PrepullActions: []*proto.APLPrepullAction{{
+			Action: &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+				SpellId: &proto.ActionID{RawId: &proto.ActionID_OtherId{OtherId: proto.OtherAction_OtherActionPotion}},
+			}}},
+			DoAtValue: constValue("-1.5s"),
+		}},
+		PriorityList: []*proto.APLListItem{
+			{
+				Action: &proto.APLAction{
+					Condition: &proto.APLValue{Value: &proto.APLValue_And{And: &proto.APLValueAnd{Vals: []*proto.APLValue{
+						{Value: &proto.APLValue_Cmp{Cmp: &proto.APLValueCompare{
+							Op:  proto.APLValueCompare_OpGt,
+							Lhs: &proto.APLValue{Value: &proto.APLValue_NumberTargets{NumberTargets: &proto.APLValueNumberTargets{}}},
+							Rhs: constValue("2"),
+						}}},
+						{Value: &proto.APLValue_Not{Not: &proto.APLValueNot{
+							Val: &proto.APLValue{Value: &proto.APLValue_Variable{Variable: &proto.APLValueVariable{Name: "execute"}}},
+						}}},
+					}}}},
+					Action: &proto.APLAction_CallActionList{CallActionList: &proto.APLActionCallActionList{ListName: "aoe"}},
+				},
+			},
+			{
+				Hide:  true,
+				Notes: "Bloodlust",
+				Action: &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+					SpellId: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: 2825}, Tag: -1},
+				}}},
+			},
+		},
+		ActionLists: []*proto.APLActionList{
+			{
+				Name: "aoe",
+				Items: []*proto.APLListItem{{
+					Action: &proto.APLAction{Action: &proto.APLAction_Multidot{Multidot: &proto.APLActionMultidot{
+						SpellId: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: 1978}},
+						MaxDots: 3,
+						MaxOverlap: &proto.APLValue{Value: &proto.APLValue_Math{Math: &proto.APLValueMath{
+							Op:  proto.APLValueMath_OpSub,
+							Lhs: constValue("0.5"),
+							Rhs: &proto.APLValue{Value: &proto.APLValue_Math{Math: &proto.APLValueMath{
+								Op:  proto.APLValueMath_OpMul,
+								Lhs: &proto.APLValue{Value: &proto.APLValue_CurrentTime{CurrentTime: &proto.APLValueCurrentTime{}}},
+								Rhs: constValue("2"),
+							}}},
+						}}},
+					}}},
+				}},
+			},
+			{Name: "empty"},
+		},
+	}
+	if !goproto.Equal(rotation, expected) {
+		t.Fatalf("unexpected rotation:\n%s", protojson.Format(rotation))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{"actions+=/cast_spel(spell_id=spell:1)", "1:11: unknown action 'cast_spel'"},
+		{"\nactions+=/cast_spell(spell=spell:1)", "2:22: APLActionCastSpell has no field 'spell'"},
+		{"actions+=/wait(duration=1 < 2 < 3)", "1:31: comparisons cannot be chained, use parentheses"},
+		{"actions+=/wait(duration=-current_time())", "1:25: '-' can only negate numbers, use 0 - x instead"},
+		{"prepull+=/cast_spell(spell_id=spell:1) at", "1:42: expected a value, found end of line"},
+		{"variable.x = \"unterminated", "1:14: unterminated string"},
+		{"action+=/wait()", "1:1: expected 'actions', 'prepull' or 'variable', found 'action'"},
+	}
+
+	for _, testCase := range testCases {
+		_, err := Parse(testCase.text)
+		if err == nil || err.Error() != testCase.expected {
+			t.Errorf("parsing %q: expected error %q, got %v", testCase.text, testCase.expected, err)
+		}
+	}
+}
+
+func TestFormatParenthesizes(t *testing.T) {
+	for _, text := range []string{
+		"variable.a = (1 || 2) && 3",
+		"variable.b = !(1 && 2) || (3 || 4)",
+		"variable.c = 1 - (2 - 3) * (4 + 5) / 6",
+		"variable.d = (1 > 2) == (3 < 4)",
+		"variable.e = and(vals=[1]) && or()",
+	} {
+		rotation, err := Parse(text)
+		if err != nil {
+			t.Fatalf("parsing %q: %s", text, err)
+		}
+		if formatted := Format(rotation); formatted != text+"\n" {
+			t.Errorf("expected %q, got %q", text, formatted)
+		}
+	}
+}
+
+func clearEmptyValues(msg protoreflect.Message) {
+	var empty []protoreflect.FieldDescriptor
+	msg.Range(func(fd protoreflect.FieldDescriptor, val protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.MessageKind {
+			return true
+		}
+		if fd.IsList() {
+			for i := 0; i < val.List().Len(); i++ {
+				clearEmptyValues(val.List().Get(i).Message())
+			}
+		} else if value, ok := val.Message().Interface().(*proto.APLValue); ok && value.Value == nil {
+			empty = append(empty, fd)
+		} else {
+			clearEmptyValues(val.Message())
+		}
+		return true
+	})
+	for _, fd := range empty {
+		msg.Clear(fd)
+	}
+}
+
+// Every APL shipped with the UI must survive a round trip through the text syntax.
+func TestRoundTripPresets(t *testing.T) {
+	files, err := filepath.Glob("../../../ui/*/*/apls/*.apl.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no preset APLs found")
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rotation := &proto.APLRotation{}
+		if err := protojson.Unmarshal(data, rotation); err != nil {
+			t.Fatalf("%s: %s", file, err)
+		}
+
+		text := Format(rotation)
+		parsed, err := Parse(text)
+		if err != nil {
+			t.Fatalf("%s: %s\n%s", file, err, text)
+		}
+
+		// The type and simple rotation settings aren't part of the syntax, and
+		// empty values and actions do nothing in the sim, so they aren't formatted.
+		rotation.Type = proto.APLRotation_TypeAPL
+		rotation.Simple = nil
+		clearEmptyValues(rotation.ProtoReflect())
+		rotation.PrepullActions = slices.DeleteFunc(rotation.PrepullActions, func(item *proto.APLPrepullAction) bool { return item.Action.GetAction() == nil })
+		rotation.PriorityList = slices.DeleteFunc(rotation.PriorityList, func(item *proto.APLListItem) bool { return item.Action.GetAction() == nil })
+		if !goproto.Equal(rotation, parsed) {
+			t.Errorf("%s did not round trip:\n%s", file, text)
+		}
+	}
+}
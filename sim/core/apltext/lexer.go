@@ -0,0 +1,116 @@
+package apltext
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOL     tokenKind = iota
+	tokenIdent             // cast_spell, OpGt, true
+	tokenLiteral           // 12, 1.5s, 100ms
+	tokenString            // "text"
+	tokenPunct             // ( ) [ ] { } , = : . / + - * ! && || == != < <= > >= +=
+	tokenComment           // # text
+)
+
+type token struct {
+	kind tokenKind
+	text string // For strings, the unquoted value. For comments, the trimmed text after '#'.
+	line int
+	col  int
+}
+
+// A syntax error, with the 1-based position where it was found.
+type ParseError struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", err.Line, err.Col, err.Msg)
+}
+
+// Punctuation, longest first so that e.g. "<=" wins over "<".
+var puncts = []string{"+=", "&&", "||", "==", "!=", "<=", ">=", "(", ")", "[", "]", "{", "}", ",", "=", ":", ".", "/", "+", "-", "*", "!", "<", ">"}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// Splits a single line into tokens. The returned slice always ends with a
+// tokenEOL token.
+func lexLine(text string, line int) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(text) {
+		c := text[i]
+		start := i
+		col := i + 1
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '#':
+			tokens = append(tokens, token{kind: tokenComment, text: strings.TrimSpace(text[i+1:]), line: line, col: col})
+			i = len(text)
+			continue
+		case isIdentStart(c):
+			for i < len(text) && isIdentPart(text[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: text[start:i], line: line, col: col})
+			continue
+		case isDigit(c):
+			// Literals include unit suffixes and decimals, e.g. 1.5s or 1m30s.
+			for i < len(text) && (isIdentPart(text[i]) || text[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenLiteral, text: text[start:i], line: line, col: col})
+			continue
+		case c == '"':
+			i++
+			for i < len(text) && text[i] != '"' {
+				if text[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(text) {
+				return nil, &ParseError{Line: line, Col: col, Msg: "unterminated string"}
+			}
+			i++
+			value, err := strconv.Unquote(text[start:i])
+			if err != nil {
+				return nil, &ParseError{Line: line, Col: col, Msg: fmt.Sprintf("invalid string %s", text[start:i])}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, line: line, col: col})
+			continue
+		}
+
+		matched := false
+		for _, punct := range puncts {
+			if strings.HasPrefix(text[i:], punct) {
+				tokens = append(tokens, token{kind: tokenPunct, text: punct, line: line, col: col})
+				i += len(punct)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, &ParseError{Line: line, Col: col, Msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	return append(tokens, token{kind: tokenEOL, line: line, col: len(text) + 1}), nil
+}
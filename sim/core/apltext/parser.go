@@ -0,0 +1,737 @@
+// Package apltext converts APL rotations to and from a compact text syntax,
+// loosely modelled on SimulationCraft action lists:
+//
+//	variable.execute = is_execute_phase(threshold=E20)
+//	prepull+=/cast_spell(spell_id=spell:13165) at -10s
+//	actions+=/call_action_list(list_name="aoe") if number_targets() > 2
+//	actions+=/cast_spell(spell_id=spell:53351) if variable(name="execute") # Kill Shot
+//	actions+=/hidden cast_spell(spell_id=spell:3044)
+//	actions.aoe+=/cast_spell(spell_id=spell:2643)
+//
+// Actions and values are written as calls named after their field in the
+// APLAction/APLValue protos, with the message fields as named arguments. And,
+// Or, Not, comparisons and math can also be written with the usual infix
+// operators (||, &&, !, == != < <= > >=, + - * /). Bare numbers, durations and
+// booleans, as well as quoted strings, are constants. A trailing comment on an
+// item becomes its notes.
+package apltext
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// Parses a rotation in the text syntax. The returned error is a *ParseError.
+func Parse(text string) (*proto.APLRotation, error) {
+	rotation := &proto.APLRotation{Type: proto.APLRotation_TypeAPL}
+	lists := map[string]*proto.APLActionList{}
+
+	for i, line := range strings.Split(text, "\n") {
+		tokens, err := lexLine(line, i+1)
+		if err != nil {
+			return nil, err
+		}
+		p := &parser{tokens: tokens}
+		if err := p.parseStatement(rotation, lists); err != nil {
+			return nil, err
+		}
+	}
+	return rotation, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOL {
+		p.pos++
+	}
+	return tok
+}
+func (p *parser) peekIs(kind tokenKind, text string) bool {
+	tok := p.peek()
+	return tok.kind == kind && tok.text == text
+}
+func (p *parser) accept(kind tokenKind, text string) bool {
+	if p.peekIs(kind, text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &ParseError{Line: tok.line, Col: tok.col, Msg: fmt.Sprintf(format, args...)}
+}
+func describe(tok token) string {
+	switch tok.kind {
+	case tokenEOL:
+		return "end of line"
+	case tokenString:
+		return strconv.Quote(tok.text)
+	case tokenComment:
+		return "comment"
+	default:
+		return fmt.Sprintf("'%s'", tok.text)
+	}
+}
+
+func (p *parser) expectPunct(text string) error {
+	if tok := p.next(); tok.kind != tokenPunct || tok.text != text {
+		return p.errorf(tok, "expected '%s', found %s", text, describe(tok))
+	}
+	return nil
+}
+func (p *parser) expectIdent() (token, error) {
+	tok := p.next()
+	if tok.kind != tokenIdent {
+		return tok, p.errorf(tok, "expected a name, found %s", describe(tok))
+	}
+	return tok, nil
+}
+func (p *parser) expectEnd() error {
+	if tok := p.next(); tok.kind != tokenEOL {
+		return p.errorf(tok, "unexpected %s", describe(tok))
+	}
+	return nil
+}
+
+// Names of variables and action lists are identifiers or quoted strings.
+func (p *parser) parseName() (string, error) {
+	tok := p.next()
+	if tok.kind != tokenIdent && tok.kind != tokenString {
+		return "", p.errorf(tok, "expected a name, found %s", describe(tok))
+	}
+	return tok.text, nil
+}
+
+func (p *parser) parseStatement(rotation *proto.APLRotation, lists map[string]*proto.APLActionList) error {
+	if tok := p.peek(); tok.kind == tokenEOL || tok.kind == tokenComment {
+		return nil
+	}
+
+	keyword, err := p.expectIdent()
+	if err != nil {
+		return err
+	}
+
+	switch keyword.text {
+	case "variable":
+		if err := p.expectPunct("."); err != nil {
+			return err
+		}
+		name, err := p.parseName()
+		if err != nil {
+			return err
+		}
+		if err := p.expectPunct("="); err != nil {
+			return err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		if err := p.expectEnd(); err != nil {
+			return err
+		}
+		rotation.Variables = append(rotation.Variables, &proto.APLVariable{Name: name, Value: value})
+
+	case "prepull":
+		if err := p.expectAppend(); err != nil {
+			return err
+		}
+		item, err := p.parsePrepullItem()
+		if err != nil {
+			return err
+		}
+		rotation.PrepullActions = append(rotation.PrepullActions, item)
+
+	case "actions":
+		if !p.accept(tokenPunct, ".") {
+			if err := p.expectAppend(); err != nil {
+				return err
+			}
+			item, err := p.parseListItem()
+			if err != nil {
+				return err
+			}
+			rotation.PriorityList = append(rotation.PriorityList, item)
+			return nil
+		}
+
+		name, err := p.parseName()
+		if err != nil {
+			return err
+		}
+		list := lists[name]
+		if list == nil {
+			list = &proto.APLActionList{Name: name}
+			lists[name] = list
+			rotation.ActionLists = append(rotation.ActionLists, list)
+		}
+		// 'actions.name=' declares an empty list.
+		if p.accept(tokenPunct, "=") {
+			return p.expectEnd()
+		}
+		if err := p.expectAppend(); err != nil {
+			return err
+		}
+		item, err := p.parseListItem()
+		if err != nil {
+			return err
+		}
+		list.Items = append(list.Items, item)
+
+	default:
+		return p.errorf(keyword, "expected 'actions', 'prepull' or 'variable', found %s", describe(keyword))
+	}
+	return nil
+}
+
+func (p *parser) expectAppend() error {
+	if err := p.expectPunct("+="); err != nil {
+		return err
+	}
+	return p.expectPunct("/")
+}
+
+func (p *parser) parseHidden() bool {
+	if p.peekIs(tokenIdent, "hidden") && p.tokens[p.pos+1].kind == tokenIdent {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseNotes() (string, error) {
+	notes := ""
+	if p.peek().kind == tokenComment {
+		notes = p.next().text
+	}
+	return notes, p.expectEnd()
+}
+
+func (p *parser) parseListItem() (*proto.APLListItem, error) {
+	item := &proto.APLListItem{Hide: p.parseHidden()}
+
+	action, err := p.parseAction()
+	if err != nil {
+		return nil, err
+	}
+	item.Action = action
+
+	if item.Notes, err = p.parseNotes(); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (p *parser) parsePrepullItem() (*proto.APLPrepullAction, error) {
+	item := &proto.APLPrepullAction{Hide: p.parseHidden()}
+
+	action, err := p.parseAction()
+	if err != nil {
+		return nil, err
+	}
+	item.Action = action
+
+	if p.accept(tokenIdent, "at") {
+		if item.DoAtValue, err = p.parseValue(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Prepull actions have no notes, but comments are still allowed.
+	if _, err := p.parseNotes(); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Looks up the oneof field named by tok, e.g. cast_spell in APLAction.
+func (p *parser) oneofField(tok token, md protoreflect.MessageDescriptor, kind string) (protoreflect.FieldDescriptor, error) {
+	fd := md.Fields().ByName(protoreflect.Name(tok.text))
+	if fd == nil || fd.ContainingOneof() == nil || fd.Kind() != protoreflect.MessageKind {
+		return nil, p.errorf(tok, "unknown %s '%s'", kind, tok.text)
+	}
+	return fd, nil
+}
+
+// action := name '(' args ')' ['if' value]
+func (p *parser) parseAction() (*proto.APLAction, error) {
+	nameTok, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	action := &proto.APLAction{}
+	msg := action.ProtoReflect()
+	fd, err := p.oneofField(nameTok, msg.Descriptor(), "action")
+	if err != nil {
+		return nil, err
+	}
+	impl := msg.NewField(fd)
+	if err := p.parseFields(impl.Message(), "(", ")"); err != nil {
+		return nil, err
+	}
+	msg.Set(fd, impl)
+
+	if p.accept(tokenIdent, "if") {
+		if action.Condition, err = p.parseValue(); err != nil {
+			return nil, err
+		}
+	}
+	return action, nil
+}
+
+// value := or
+// or    := and ('||' and)*
+// and   := cmp ('&&' cmp)*
+// cmp   := sum [('==' | '!=' | '<' | '<=' | '>' | '>=') sum]
+// sum   := term (('+' | '-') term)*
+// term  := unary (('*' | '/') unary)*
+// unary := '!' unary | '-' literal | primary
+func (p *parser) parseValue() (*proto.APLValue, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (*proto.APLValue, error) {
+	first, err := p.parseAnd()
+	if err != nil || !p.peekIs(tokenPunct, "||") {
+		return first, err
+	}
+	vals := []*proto.APLValue{first}
+	for p.accept(tokenPunct, "||") {
+		val, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+	return &proto.APLValue{Value: &proto.APLValue_Or{Or: &proto.APLValueOr{Vals: vals}}}, nil
+}
+
+func (p *parser) parseAnd() (*proto.APLValue, error) {
+	first, err := p.parseCmp()
+	if err != nil || !p.peekIs(tokenPunct, "&&") {
+		return first, err
+	}
+	vals := []*proto.APLValue{first}
+	for p.accept(tokenPunct, "&&") {
+		val, err := p.parseCmp()
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+	return &proto.APLValue{Value: &proto.APLValue_And{And: &proto.APLValueAnd{Vals: vals}}}, nil
+}
+
+var comparisonOps = map[string]proto.APLValueCompare_ComparisonOperator{
+	"==": proto.APLValueCompare_OpEq,
+	"!=": proto.APLValueCompare_OpNe,
+	"<":  proto.APLValueCompare_OpLt,
+	"<=": proto.APLValueCompare_OpLe,
+	">":  proto.APLValueCompare_OpGt,
+	">=": proto.APLValueCompare_OpGe,
+}
+
+var mathOps = map[string]proto.APLValueMath_MathOperator{
+	"+": proto.APLValueMath_OpAdd,
+	"-": proto.APLValueMath_OpSub,
+	"*": proto.APLValueMath_OpMul,
+	"/": proto.APLValueMath_OpDiv,
+}
+
+func (p *parser) parseCmp() (*proto.APLValue, error) {
+	lhs, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	op, ok := comparisonOps[tok.text]
+	if tok.kind != tokenPunct || !ok {
+		return lhs, nil
+	}
+	p.next()
+
+	rhs, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind == tokenPunct && comparisonOps[tok.text] != proto.APLValueCompare_OpUnknown {
+		return nil, p.errorf(tok, "comparisons cannot be chained, use parentheses")
+	}
+	return &proto.APLValue{Value: &proto.APLValue_Cmp{Cmp: &proto.APLValueCompare{Op: op, Lhs: lhs, Rhs: rhs}}}, nil
+}
+
+func (p *parser) parseSum() (*proto.APLValue, error) {
+	return p.parseMath(p.parseTerm, "+", "-")
+}
+
+func (p *parser) parseTerm() (*proto.APLValue, error) {
+	return p.parseMath(p.parseUnary, "*", "/")
+}
+
+// Parses a left-associative chain of math operators.
+func (p *parser) parseMath(operand func() (*proto.APLValue, error), ops ...string) (*proto.APLValue, error) {
+	lhs, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokenPunct || (tok.text != ops[0] && tok.text != ops[1]) {
+			return lhs, nil
+		}
+		p.next()
+
+		rhs, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &proto.APLValue{Value: &proto.APLValue_Math{Math: &proto.APLValueMath{Op: mathOps[tok.text], Lhs: lhs, Rhs: rhs}}}
+	}
+}
+
+func (p *parser) parseUnary() (*proto.APLValue, error) {
+	if p.accept(tokenPunct, "!") {
+		val, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &proto.APLValue{Value: &proto.APLValue_Not{Not: &proto.APLValueNot{Val: val}}}, nil
+	}
+	if minus := p.peek(); p.accept(tokenPunct, "-") {
+		tok := p.next()
+		if tok.kind != tokenLiteral {
+			return nil, p.errorf(minus, "'-' can only negate numbers, use 0 - x instead")
+		}
+		return constValue("-" + tok.text), nil
+	}
+	return p.parsePrimary()
+}
+
+func constValue(val string) *proto.APLValue {
+	return &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: val}}}
+}
+
+func (p *parser) parsePrimary() (*proto.APLValue, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLiteral, tokenString:
+		return constValue(tok.text), nil
+	case tokenPunct:
+		if tok.text == "(" {
+			val, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			return val, p.expectPunct(")")
+		}
+	case tokenIdent:
+		if (tok.text == "true" || tok.text == "false") && !p.peekIs(tokenPunct, "(") {
+			return constValue(tok.text), nil
+		}
+
+		value := &proto.APLValue{}
+		msg := value.ProtoReflect()
+		fd, err := p.oneofField(tok, msg.Descriptor(), "value")
+		if err != nil {
+			return nil, err
+		}
+		impl := msg.NewField(fd)
+		if err := p.parseFields(impl.Message(), "(", ")"); err != nil {
+			return nil, err
+		}
+		msg.Set(fd, impl)
+		return value, nil
+	}
+	return nil, p.errorf(tok, "expected a value, found %s", describe(tok))
+}
+
+// fields := open [name '=' field (',' name '=' field)*] close
+func (p *parser) parseFields(msg protoreflect.Message, open string, close string) error {
+	if err := p.expectPunct(open); err != nil {
+		return err
+	}
+	if p.accept(tokenPunct, close) {
+		return nil
+	}
+
+	for {
+		nameTok, err := p.expectIdent()
+		if err != nil {
+			return err
+		}
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(nameTok.text))
+		if fd == nil {
+			return p.errorf(nameTok, "%s has no field '%s'", msg.Descriptor().Name(), nameTok.text)
+		}
+		if msg.Has(fd) {
+			return p.errorf(nameTok, "duplicate field '%s'", nameTok.text)
+		}
+		if err := p.expectPunct("="); err != nil {
+			return err
+		}
+
+		if fd.IsList() {
+			if err := p.parseList(msg.Mutable(fd).List(), fd); err != nil {
+				return err
+			}
+		} else {
+			val, err := p.parseField(fd)
+			if err != nil {
+				return err
+			}
+			msg.Set(fd, val)
+		}
+
+		if p.accept(tokenPunct, close) {
+			return nil
+		}
+		if err := p.expectPunct(","); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseList(list protoreflect.List, fd protoreflect.FieldDescriptor) error {
+	if err := p.expectPunct("["); err != nil {
+		return err
+	}
+	if p.accept(tokenPunct, "]") {
+		return nil
+	}
+	for {
+		val, err := p.parseField(fd)
+		if err != nil {
+			return err
+		}
+		list.Append(val)
+
+		if p.accept(tokenPunct, "]") {
+			return nil
+		}
+		if err := p.expectPunct(","); err != nil {
+			return err
+		}
+	}
+}
+
+// Parses a single value of the given field (an element, for repeated fields).
+func (p *parser) parseField(fd protoreflect.FieldDescriptor) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		return p.parseMessage(fd.Message())
+
+	case protoreflect.EnumKind:
+		tok, err := p.expectIdent()
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return p.enumValue(tok, fd.Enum())
+
+	case protoreflect.StringKind:
+		tok := p.next()
+		if tok.kind != tokenString {
+			return protoreflect.Value{}, p.errorf(tok, "expected a quoted string, found %s", describe(tok))
+		}
+		return protoreflect.ValueOfString(tok.text), nil
+
+	case protoreflect.BoolKind:
+		tok := p.next()
+		if tok.kind != tokenIdent || (tok.text != "true" && tok.text != "false") {
+			return protoreflect.Value{}, p.errorf(tok, "expected true or false, found %s", describe(tok))
+		}
+		return protoreflect.ValueOfBool(tok.text == "true"), nil
+
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := p.parseInt(32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := p.parseInt(64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := p.parseInt(32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := p.parseInt(64)
+		return protoreflect.ValueOfUint64(uint64(n)), err
+
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		text, tok, err := p.parseNumber()
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return protoreflect.Value{}, p.errorf(tok, "invalid number %s", text)
+		}
+		if fd.Kind() == protoreflect.FloatKind {
+			return protoreflect.ValueOfFloat32(float32(f)), nil
+		}
+		return protoreflect.ValueOfFloat64(f), nil
+	}
+	return protoreflect.Value{}, p.errorf(p.peek(), "unsupported field type %s", fd.Kind())
+}
+
+func (p *parser) parseMessage(md protoreflect.MessageDescriptor) (protoreflect.Value, error) {
+	switch md.FullName() {
+	case "proto.APLValue":
+		val, err := p.parseValue()
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfMessage(val.ProtoReflect()), nil
+	case "proto.APLAction":
+		action, err := p.parseAction()
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfMessage(action.ProtoReflect()), nil
+	}
+
+	// Any message can be written with braces, but action IDs and unit
+	// references have a shorthand.
+	if !p.peekIs(tokenPunct, "{") {
+		switch md.FullName() {
+		case "proto.ActionID":
+			id, err := p.parseActionID()
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			return protoreflect.ValueOfMessage(id.ProtoReflect()), nil
+		case "proto.UnitReference":
+			ref, err := p.parseUnitReference()
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			return protoreflect.ValueOfMessage(ref.ProtoReflect()), nil
+		}
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName())
+	if err != nil {
+		return protoreflect.Value{}, p.errorf(p.peek(), "unknown message type %s", md.FullName())
+	}
+	msg := mt.New()
+	if err := p.parseFields(msg, "{", "}"); err != nil {
+		return protoreflect.Value{}, err
+	}
+	return protoreflect.ValueOfMessage(msg), nil
+}
+
+func (p *parser) enumValue(tok token, ed protoreflect.EnumDescriptor) (protoreflect.Value, error) {
+	ev := ed.Values().ByName(protoreflect.Name(tok.text))
+	if ev == nil {
+		return protoreflect.Value{}, p.errorf(tok, "unknown %s '%s'", ed.Name(), tok.text)
+	}
+	return protoreflect.ValueOfEnum(ev.Number()), nil
+}
+
+// Returns the text of a number, including its sign.
+func (p *parser) parseNumber() (string, token, error) {
+	first := p.peek()
+	sign := ""
+	if p.accept(tokenPunct, "-") {
+		sign = "-"
+	}
+	tok := p.next()
+	if tok.kind != tokenLiteral {
+		return "", first, p.errorf(tok, "expected a number, found %s", describe(tok))
+	}
+	return sign + tok.text, first, nil
+}
+
+func (p *parser) parseInt(bits int) (int64, error) {
+	text, tok, err := p.parseNumber()
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(text, 10, bits)
+	if err != nil {
+		return 0, p.errorf(tok, "invalid integer %s", text)
+	}
+	return n, nil
+}
+
+// action_id := ('spell' | 'item') ':' int [':' tag] | 'other' ':' OtherAction [':' tag]
+func (p *parser) parseActionID() (*proto.ActionID, error) {
+	kindTok, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct(":"); err != nil {
+		return nil, err
+	}
+
+	id := &proto.ActionID{}
+	switch kindTok.text {
+	case "spell", "item":
+		n, err := p.parseInt(32)
+		if err != nil {
+			return nil, err
+		}
+		if kindTok.text == "spell" {
+			id.RawId = &proto.ActionID_SpellId{SpellId: int32(n)}
+		} else {
+			id.RawId = &proto.ActionID_ItemId{ItemId: int32(n)}
+		}
+	case "other":
+		tok, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		val, err := p.enumValue(tok, proto.OtherAction(0).Descriptor())
+		if err != nil {
+			return nil, err
+		}
+		id.RawId = &proto.ActionID_OtherId{OtherId: proto.OtherAction(val.Enum())}
+	default:
+		return nil, p.errorf(kindTok, "expected spell, item or other, found %s", describe(kindTok))
+	}
+
+	if p.accept(tokenPunct, ":") {
+		tag, err := p.parseInt(32)
+		if err != nil {
+			return nil, err
+		}
+		id.Tag = int32(tag)
+	}
+	return id, nil
+}
+
+// unit := Type [':' index]
+func (p *parser) parseUnitReference() (*proto.UnitReference, error) {
+	tok, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	val, err := p.enumValue(tok, proto.UnitReference_Type(0).Descriptor())
+	if err != nil {
+		return nil, err
+	}
+
+	ref := &proto.UnitReference{Type: proto.UnitReference_Type(val.Enum())}
+	if p.accept(tokenPunct, ":") {
+		index, err := p.parseInt(32)
+		if err != nil {
+			return nil, err
+		}
+		ref.Index = int32(index)
+	}
+	return ref, nil
+}
@@ -0,0 +1,321 @@
+package apltext
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+var (
+	identRegex   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	literalRegex = regexp.MustCompile(`^-?[0-9][0-9A-Za-z_.]*$`)
+)
+
+// Formats a rotation in the text syntax, such that Parse(Format(rotation))
+// returns an equivalent rotation. The rotation type and simple rotation
+// settings are not part of the syntax, and newlines in notes become spaces.
+func Format(rotation *proto.APLRotation) string {
+	var sections []string
+	var lines []string
+	endSection := func() {
+		if len(lines) > 0 {
+			sections = append(sections, strings.Join(lines, "\n"))
+			lines = nil
+		}
+	}
+
+	for _, variable := range rotation.Variables {
+		if isEmptyValue(variable.Value) {
+			continue
+		}
+		lines = append(lines, "variable."+formatName(variable.Name)+" = "+formatValue(variable.Value))
+	}
+	endSection()
+
+	for _, item := range rotation.PrepullActions {
+		if isEmptyAction(item.Action) {
+			continue
+		}
+		line := "prepull+=/" + formatHidden(item.Hide) + formatAction(item.Action)
+		if !isEmptyValue(item.DoAtValue) {
+			line += " at " + formatValue(item.DoAtValue)
+		}
+		lines = append(lines, line)
+	}
+	endSection()
+
+	lines = formatListItems(lines, "actions", rotation.PriorityList)
+	endSection()
+
+	for _, list := range rotation.ActionLists {
+		prefix := "actions." + formatName(list.Name)
+		if len(list.Items) == 0 {
+			lines = append(lines, prefix+"=")
+		}
+		lines = formatListItems(lines, prefix, list.Items)
+		endSection()
+	}
+
+	if len(sections) == 0 {
+		return ""
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+func formatListItems(lines []string, prefix string, items []*proto.APLListItem) []string {
+	for _, item := range items {
+		if isEmptyAction(item.Action) {
+			continue
+		}
+		line := prefix + "+=/" + formatHidden(item.Hide) + formatAction(item.Action)
+		if item.Notes != "" {
+			line += " # " + strings.Join(strings.Fields(item.Notes), " ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func formatHidden(hide bool) string {
+	if hide {
+		return "hidden "
+	}
+	return ""
+}
+
+func formatName(name string) string {
+	if identRegex.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// Empty values and actions are ignored by the sim, so they are left out.
+func isEmptyValue(value *proto.APLValue) bool {
+	return value == nil || value.Value == nil
+}
+func isEmptyAction(action *proto.APLAction) bool {
+	return action == nil || action.Action == nil
+}
+
+func formatAction(action *proto.APLAction) string {
+	msg := action.ProtoReflect()
+	fd := msg.WhichOneof(msg.Descriptor().Oneofs().ByName("action"))
+	text := string(fd.Name()) + formatFields(msg.Get(fd).Message(), "(", ")")
+	if !isEmptyValue(action.Condition) {
+		text += " if " + formatValue(action.Condition)
+	}
+	return text
+}
+
+// Operator precedence, from loosest to tightest binding.
+const (
+	precOr = iota + 1
+	precAnd
+	precCmp
+	precSum
+	precTerm
+	precUnary
+	precPrimary
+)
+
+var comparisonSymbols = map[proto.APLValueCompare_ComparisonOperator]string{}
+var mathSymbols = map[proto.APLValueMath_MathOperator]string{}
+
+func init() {
+	for symbol, op := range comparisonOps {
+		comparisonSymbols[op] = symbol
+	}
+	for symbol, op := range mathOps {
+		mathSymbols[op] = symbol
+	}
+}
+
+// Returns the precedence of the infix form of the value, or precPrimary if
+// the value is written as a call or literal.
+func precedence(value *proto.APLValue) int {
+	switch v := value.Value.(type) {
+	case *proto.APLValue_Or:
+		if len(v.Or.Vals) >= 2 && !anyEmptyValue(v.Or.Vals) {
+			return precOr
+		}
+	case *proto.APLValue_And:
+		if len(v.And.Vals) >= 2 && !anyEmptyValue(v.And.Vals) {
+			return precAnd
+		}
+	case *proto.APLValue_Cmp:
+		if comparisonSymbols[v.Cmp.Op] != "" && !isEmptyValue(v.Cmp.Lhs) && !isEmptyValue(v.Cmp.Rhs) {
+			return precCmp
+		}
+	case *proto.APLValue_Math:
+		if !isEmptyValue(v.Math.Lhs) && !isEmptyValue(v.Math.Rhs) {
+			switch v.Math.Op {
+			case proto.APLValueMath_OpAdd, proto.APLValueMath_OpSub:
+				return precSum
+			case proto.APLValueMath_OpMul, proto.APLValueMath_OpDiv:
+				return precTerm
+			}
+		}
+	case *proto.APLValue_Not:
+		if !isEmptyValue(v.Not.Val) {
+			return precUnary
+		}
+	}
+	return precPrimary
+}
+
+func anyEmptyValue(values []*proto.APLValue) bool {
+	for _, value := range values {
+		if isEmptyValue(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValue(value *proto.APLValue) string {
+	switch precedence(value) {
+	case precOr:
+		return formatOperands(value.GetOr().Vals, " || ", precAnd)
+	case precAnd:
+		return formatOperands(value.GetAnd().Vals, " && ", precCmp)
+	case precCmp:
+		cmp := value.GetCmp()
+		return formatOperand(cmp.Lhs, precSum) + " " + comparisonSymbols[cmp.Op] + " " + formatOperand(cmp.Rhs, precSum)
+	case precSum, precTerm:
+		// Math is left-associative, so only the right operand needs
+		// parentheses at the same precedence.
+		math := value.GetMath()
+		prec := precedence(value)
+		return formatOperand(math.Lhs, prec) + " " + mathSymbols[math.Op] + " " + formatOperand(math.Rhs, prec+1)
+	case precUnary:
+		return "!" + formatOperand(value.GetNot().Val, precUnary)
+	}
+
+	if c, ok := value.Value.(*proto.APLValue_Const); ok {
+		val := c.Const.Val
+		if literalRegex.MatchString(val) || val == "true" || val == "false" {
+			return val
+		}
+		return strconv.Quote(val)
+	}
+
+	msg := value.ProtoReflect()
+	fd := msg.WhichOneof(msg.Descriptor().Oneofs().ByName("value"))
+	return string(fd.Name()) + formatFields(msg.Get(fd).Message(), "(", ")")
+}
+
+func formatOperands(values []*proto.APLValue, sep string, minPrec int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = formatOperand(value, minPrec)
+	}
+	return strings.Join(parts, sep)
+}
+
+// Formats a value, with parentheses if it binds looser than minPrec.
+func formatOperand(value *proto.APLValue, minPrec int) string {
+	if precedence(value) < minPrec {
+		return "(" + formatValue(value) + ")"
+	}
+	return formatValue(value)
+}
+
+func formatFields(msg protoreflect.Message, open string, close string) string {
+	var parts []string
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !msg.Has(fd) {
+			continue
+		}
+
+		if fd.IsList() {
+			list := msg.Get(fd).List()
+			elements := make([]string, 0, list.Len())
+			for j := 0; j < list.Len(); j++ {
+				if element, ok := formatField(fd, list.Get(j)); ok {
+					elements = append(elements, element)
+				}
+			}
+			parts = append(parts, string(fd.Name())+"=["+strings.Join(elements, ", ")+"]")
+		} else if text, ok := formatField(fd, msg.Get(fd)); ok {
+			parts = append(parts, string(fd.Name())+"="+text)
+		}
+	}
+	return open + strings.Join(parts, ", ") + close
+}
+
+// Formats a single value of the given field. Returns false for empty values
+// and actions, which are left out.
+func formatField(fd protoreflect.FieldDescriptor, val protoreflect.Value) (string, bool) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		return formatMessage(val.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(val.Enum()); ev != nil {
+			return string(ev.Name()), true
+		}
+		return strconv.Itoa(int(val.Enum())), true
+	case protoreflect.StringKind:
+		return strconv.Quote(val.String()), true
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(val.Float(), 'f', -1, 32), true
+	case protoreflect.DoubleKind:
+		return strconv.FormatFloat(val.Float(), 'f', -1, 64), true
+	default:
+		// Bools and integers.
+		return val.String(), true
+	}
+}
+
+func formatMessage(msg protoreflect.Message) (string, bool) {
+	switch m := msg.Interface().(type) {
+	case *proto.APLValue:
+		if isEmptyValue(m) {
+			return "", false
+		}
+		return formatValue(m), true
+	case *proto.APLAction:
+		if isEmptyAction(m) {
+			return "", false
+		}
+		return formatAction(m), true
+	case *proto.ActionID:
+		if text := formatActionID(m); text != "" {
+			return text, true
+		}
+	case *proto.UnitReference:
+		if m.Owner == nil {
+			text := m.Type.String()
+			if m.Index != 0 {
+				text += ":" + strconv.Itoa(int(m.Index))
+			}
+			return text, true
+		}
+	}
+	return formatFields(msg, "{", "}"), true
+}
+
+// Returns the shorthand for an action ID, or "" if it has none.
+func formatActionID(id *proto.ActionID) string {
+	var text string
+	switch rawID := id.RawId.(type) {
+	case *proto.ActionID_SpellId:
+		text = "spell:" + strconv.Itoa(int(rawID.SpellId))
+	case *proto.ActionID_ItemId:
+		text = "item:" + strconv.Itoa(int(rawID.ItemId))
+	case *proto.ActionID_OtherId:
+		text = "other:" + rawID.OtherId.String()
+	default:
+		return ""
+	}
+	if id.Tag != 0 {
+		text += ":" + strconv.Itoa(int(id.Tag))
+	}
+	return text
+}
@@ -311,7 +311,7 @@ func (wa *WeaponAttack) swing(sim *Simulation) time.Duration {
 	wa.swingAt = sim.CurrentTime + wa.curSwingDuration
 	attackSpell.Cast(sim, wa.unit.CurrentTarget)
 
-	if !sim.Options.Interactive && wa.unit.Rotation != nil {
+	if !wa.unit.IsInteractive(sim) && wa.unit.Rotation != nil {
 		wa.unit.ReactToEvent(sim)
 	}
 
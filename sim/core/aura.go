@@ -82,6 +82,7 @@ type Aura struct {
 	OnInit          OnInit
 	OnReset         OnReset
 	OnDoneIteration OnDoneIteration
+	OnActivate      OnGain // Invoked when the aura is activated, before its exclusive effects and OnGain.
 	OnGain          OnGain
 	OnExpire        OnExpire
 	OnStacksChange  OnStacksChange // Invoked when the number of stacks of this aura changes.
@@ -253,6 +254,19 @@ func (aura *Aura) ApplyOnGain(newOnGain OnGain) {
 	}
 }
 
+// Adds a handler to be called OnActivate, in addition to any current handlers.
+func (aura *Aura) ApplyOnActivate(newOnActivate OnGain) {
+	oldOnActivate := aura.OnActivate
+	if oldOnActivate == nil {
+		aura.OnActivate = newOnActivate
+	} else {
+		aura.OnActivate = func(aura *Aura, sim *Simulation) {
+			oldOnActivate(aura, sim)
+			newOnActivate(aura, sim)
+		}
+	}
+}
+
 // Adds a handler to be called OnExpire, in addition to any current handlers.
 func (aura *Aura) ApplyOnExpire(newOnExpire OnExpire) {
 	oldOnExpire := aura.OnExpire
@@ -549,6 +563,10 @@ func (aura *Aura) Activate(sim *Simulation) {
 		panic("Aura with 0 duration")
 	}
 
+	if aura.OnActivate != nil {
+		aura.OnActivate(aura, sim)
+	}
+
 	// Activate exclusive effects.
 	// If there is already an active aura stronger than this one, then this one
 	// will be blocked.
@@ -641,11 +659,8 @@ func (aura *Aura) Deactivate(sim *Simulation) {
 	aura.active = false
 
 	if !aura.ActionID.IsEmptyAction() {
-		if sim.CurrentTime > aura.expires {
-			aura.metrics.Uptime += aura.expires - max(aura.startTime, 0)
-		} else {
-			aura.metrics.Uptime += sim.CurrentTime - max(aura.startTime, 0)
-		}
+		// Auras removed before combat starts have no uptime.
+		aura.metrics.Uptime += max(min(sim.CurrentTime, aura.expires)-max(aura.startTime, 0), 0)
 	}
 
 	if sim.Log != nil && !aura.ActionID.IsEmptyAction() {
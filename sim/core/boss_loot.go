@@ -0,0 +1,210 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// bossLootKey identifies a loot table. Drops that don't come from an NPC, e.g.
+// a chest, are identified by their name instead.
+type bossLootKey struct {
+	npcID      int32
+	otherName  string
+	zoneID     int32
+	difficulty proto.DungeonDifficulty
+}
+
+func (gs *gearSearch) bossLootKey(drop *proto.DropSource) bossLootKey {
+	key := bossLootKey{
+		npcID:      drop.NpcId,
+		zoneID:     drop.ZoneId,
+		difficulty: drop.Difficulty,
+	}
+	if npc, ok := gs.npcs[drop.NpcId]; ok {
+		if key.zoneID == 0 {
+			key.zoneID = npc.ZoneId
+		}
+	} else {
+		key.otherName = drop.OtherName
+	}
+	return key
+}
+
+func defaultDropsPerKill(difficulty proto.DungeonDifficulty) float64 {
+	switch difficulty {
+	case proto.DungeonDifficulty_DifficultyRaid25, proto.DungeonDifficulty_DifficultyRaid25H, proto.DungeonDifficulty_DifficultyRaid25RF:
+		return 5
+	}
+	return 2
+}
+
+func BossLoot(ctx context.Context, request *proto.BossLootRequest, progress chan *proto.ProgressMetrics) (result *proto.BossLootResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			result = &proto.BossLootResult{
+				ErrorResult: fmt.Sprintf("%v\nStack Trace:\n%s", err, string(debug.Stack())),
+			}
+		}
+	}()
+
+	result, err := runBossLoot(ctx, request, progress)
+	if err != nil {
+		result = &proto.BossLootResult{
+			ErrorResult: err.Error(),
+		}
+	}
+	return result
+}
+
+func runBossLoot(ctx context.Context, request *proto.BossLootRequest, progress chan *proto.ProgressMetrics) (*proto.BossLootResult, error) {
+	if request.GetSearchSettings() == nil {
+		return nil, errors.New("boss loot: missing search settings")
+	}
+	// Only drops have a boss to attribute them to.
+	settings := goproto.Clone(request.SearchSettings).(*proto.GearSearchSettings)
+	settings.IncludeCrafted = false
+	settings.IncludeReputation = false
+	settings.IncludeVendors = false
+	settings.IncludeQuests = false
+	if len(settings.ZoneIds) == 0 && len(settings.NpcIds) == 0 {
+		return nil, errors.New("boss loot: no zones or bosses selected")
+	}
+
+	gs, err := newGearSearch(request.GetBaseSettings(), settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Loot tables include every item the boss drops, usable or not.
+	lootTables := make(map[bossLootKey]map[int32]struct{})
+	for _, uiItem := range uiItemDatabase.Items {
+		for _, source := range gs.matchingSources(uiItem) {
+			key := gs.bossLootKey(source.GetDrop())
+			if lootTables[key] == nil {
+				lootTables[key] = make(map[int32]struct{})
+			}
+			lootTables[key][uiItem.Id] = struct{}{}
+		}
+	}
+
+	// Every usable upgrade is simmed, there's no pruning by slot.
+	candidatesBySlot, _ := gs.findCandidates(nil)
+	var candidates []*gearSearchCandidate
+	for slot := proto.ItemSlot_ItemSlotHead; slot <= proto.ItemSlot_ItemSlotRanged; slot++ {
+		candidates = append(candidates, candidatesBySlot[slot]...)
+	}
+
+	upgrades, baseDps, err := gs.simUpgrades(ctx, candidates, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	bosses := make(map[bossLootKey]*proto.BossLootValue, len(lootTables))
+	for key, lootTable := range lootTables {
+		name := key.otherName
+		if npc, ok := gs.npcs[key.npcID]; ok {
+			name = npc.Name
+		}
+		dropsPerKill := request.DropsPerKill
+		if dropsPerKill <= 0 {
+			dropsPerKill = defaultDropsPerKill(key.difficulty)
+		}
+		bosses[key] = &proto.BossLootValue{
+			NpcId:         key.npcID,
+			Name:          name,
+			ZoneId:        key.zoneID,
+			Difficulty:    key.difficulty,
+			LootTableSize: int32(len(lootTable)),
+			DropsPerKill:  dropsPerKill,
+		}
+	}
+
+	// Best dps gain of each item per boss and slot. Paired slots share an entry,
+	// since a ring or trinket is only equipped once.
+	type bossSlotKey struct {
+		boss bossLootKey
+		slot proto.ItemSlot
+	}
+	slotGains := make(map[bossSlotKey]map[int32]float64)
+	for _, upgrade := range upgrades {
+		for _, source := range upgrade.candidate.sources {
+			key := gs.bossLootKey(source.GetDrop())
+			boss := bosses[key]
+			if boss == nil || (len(boss.Upgrades) > 0 && boss.Upgrades[len(boss.Upgrades)-1] == upgrade.upgrade) {
+				continue
+			}
+			boss.Upgrades = append(boss.Upgrades, upgrade.upgrade)
+
+			slotKey := bossSlotKey{boss: key, slot: pairedSlot(upgrade.candidate.slot)}
+			if slotGains[slotKey] == nil {
+				slotGains[slotKey] = make(map[int32]float64)
+			}
+			itemID := upgrade.candidate.spec.Id
+			slotGains[slotKey][itemID] = max(slotGains[slotKey][itemID], upgrade.upgrade.DpsDelta)
+		}
+	}
+	for slotKey, gains := range slotGains {
+		boss := bosses[slotKey.boss]
+		deltas := make([]float64, 0, len(gains))
+		for _, delta := range gains {
+			deltas = append(deltas, delta)
+		}
+		boss.ExpectedDpsGain += expectedBestUpgrade(deltas, boss.DropsPerKill, int(boss.LootTableSize))
+	}
+
+	result := &proto.BossLootResult{
+		EquippedGearDps: baseDps,
+	}
+	for _, boss := range bosses {
+		result.Bosses = append(result.Bosses, boss)
+	}
+	sort.Slice(result.Bosses, func(i, j int) bool {
+		a, b := result.Bosses[i], result.Bosses[j]
+		if a.ExpectedDpsGain != b.ExpectedDpsGain {
+			return a.ExpectedDpsGain > b.ExpectedDpsGain
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Difficulty < b.Difficulty
+	})
+
+	return result, nil
+}
+
+func pairedSlot(slot proto.ItemSlot) proto.ItemSlot {
+	switch slot {
+	case proto.ItemSlot_ItemSlotFinger2:
+		return proto.ItemSlot_ItemSlotFinger1
+	case proto.ItemSlot_ItemSlotTrinket2:
+		return proto.ItemSlot_ItemSlotTrinket1
+	}
+	return slot
+}
+
+// expectedBestUpgrade returns the expected dps gain of the best upgrade that
+// drops in a kill, for upgrades to the same slot. A kill drops dropsPerKill
+// different items, each item in the loot table being equally likely.
+func expectedBestUpgrade(deltas []float64, dropsPerKill float64, lootTableSize int) float64 {
+	sort.Sort(sort.Reverse(sort.Float64Slice(deltas)))
+
+	expected := 0.0
+	noneDropped := 1.0 // Chance that none of the better upgrades dropped.
+	for i, delta := range deltas {
+		// Given the better upgrades didn't drop, the drops come from the rest of the table.
+		dropChance := min(1, dropsPerKill/float64(lootTableSize-i))
+		expected += noneDropped * dropChance * delta
+		noneDropped *= 1 - dropChance
+		if noneDropped <= 0 {
+			break
+		}
+	}
+	return expected
+}
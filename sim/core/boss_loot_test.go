@@ -0,0 +1,79 @@
+package core_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/stats"
+)
+
+func TestBossLoot(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerFeralCat())
+	rsr.Encounter.Duration = 60
+	const blackwingDescent = 5094
+
+	result := core.RunBossLoot(&proto.BossLootRequest{
+		BaseSettings: rsr,
+		SearchSettings: &proto.GearSearchSettings{
+			ZoneIds:      []int32{blackwingDescent},
+			Difficulties: []proto.DungeonDifficulty{proto.DungeonDifficulty_DifficultyRaid25H},
+			StatWeights: &proto.UnitStats{
+				Stats: stats.Stats{
+					stats.Agility:     3,
+					stats.AttackPower: 1,
+					stats.MeleeHit:    1,
+					stats.MeleeCrit:   1,
+					stats.MeleeHaste:  1,
+					stats.Expertise:   1,
+					stats.Mastery:     1,
+				}.ToFloatArray(),
+			},
+			IterationsPerCombo: 20,
+		},
+	})
+	if result.ErrorResult != "" {
+		t.Fatal(result.ErrorResult)
+	}
+	if len(result.Bosses) < 2 || len(result.Bosses[0].Upgrades) == 0 {
+		t.Fatalf("expected several bosses with upgrades, got %d bosses", len(result.Bosses))
+	}
+
+	for i, boss := range result.Bosses {
+		if i > 0 && boss.ExpectedDpsGain > result.Bosses[i-1].ExpectedDpsGain {
+			t.Fatalf("bosses are not ranked by expected dps gain")
+		}
+		if boss.Name == "" || boss.Difficulty != proto.DungeonDifficulty_DifficultyRaid25H || boss.DropsPerKill != 5 {
+			t.Fatalf("unexpected boss %v", boss)
+		}
+		if int(boss.LootTableSize) < len(boss.Upgrades) {
+			t.Fatalf("%s has more upgrades than items", boss.Name)
+		}
+
+		// Upgrades to the same slot don't add up, only the best one that drops counts.
+		dropChance := min(1, boss.DropsPerKill/float64(boss.LootTableSize))
+		summedGain, bestGain := 0.0, 0.0
+		for _, upgrade := range boss.Upgrades {
+			summedGain += upgrade.DpsDelta * dropChance
+			bestGain = max(bestGain, upgrade.DpsDelta*dropChance)
+		}
+		if boss.ExpectedDpsGain > summedGain+1e-6 || boss.ExpectedDpsGain < bestGain-1e-6 {
+			t.Fatalf("%s expected dps gain is %f, expected between %f and %f", boss.Name, boss.ExpectedDpsGain, bestGain, summedGain)
+		}
+	}
+}
+
+func TestExpectedBestUpgrade(t *testing.T) {
+	// Two upgrades in one slot, 2 drops from a table of 10 items. The second
+	// upgrade only counts when the first one doesn't drop.
+	expected := 0.2*100 + 0.8*(2.0/9)*50
+	if gain := core.ExpectedBestUpgrade([]float64{50, 100}, 2, 10); math.Abs(gain-expected) > 1e-9 {
+		t.Fatalf("expected gain %f, got %f", expected, gain)
+	}
+
+	// With every item dropping, the best upgrade always drops.
+	if gain := core.ExpectedBestUpgrade([]float64{50, 100}, 5, 4); gain != 100 {
+		t.Fatalf("expected gain 100, got %f", gain)
+	}
+}
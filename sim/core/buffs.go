@@ -99,7 +99,7 @@ func makeExclusiveBuff(unit *Unit, config BuffConfig) *Aura {
 }
 
 // Applies buffs that affect individual players.
-func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, _ *proto.PartyBuffs, individualBuffs *proto.IndividualBuffs) {
+func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, partyBuffs *proto.PartyBuffs, individualBuffs *proto.IndividualBuffs) {
 	character := agent.GetCharacter()
 
 	// % Stats Buffs
@@ -288,7 +288,13 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, _ *proto.PartyBuf
 		RetributionAura(&character.Unit)
 	}
 
-	if len(character.Env.Raid.AllPlayerUnits) == 1 {
+	// Mana Tide from party members outside the sim applies in raid sims too,
+	// since it only reaches the shaman's own party.
+	manaTideTotemCount := partyBuffs.ManaTideTotemCount
+
+	if len(character.Env.Raid.AllPlayerUnits) == 1 && !character.Env.Raid.BuffsFromComposition {
+		manaTideTotemCount += raidBuffs.ManaTideTotemCount
+
 		if raidBuffs.Bloodlust {
 			registerBloodlustCD(agent, 2825)
 		} else if raidBuffs.Heroism {
@@ -300,7 +306,6 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, _ *proto.PartyBuf
 		registerUnholyFrenzyCD(agent, individualBuffs.UnholyFrenzyCount)
 		registerTricksOfTheTradeCD(agent, individualBuffs.TricksOfTheTradeCount)
 		registerPowerInfusionCD(agent, individualBuffs.PowerInfusionCount)
-		registerManaTideTotemCD(agent, raidBuffs.ManaTideTotemCount)
 		registerInnervateCD(agent, individualBuffs.InnervateCount)
 		registerDivineGuardianCD(agent, individualBuffs.DivineGuardianCount)
 		registerHandOfSacrificeCD(agent, individualBuffs.HandOfSacrificeCount)
@@ -315,6 +320,8 @@ func applyBuffEffects(agent Agent, raidBuffs *proto.RaidBuffs, _ *proto.PartyBuf
 			MakePermanent(DarkIntentAura(&character.Unit, character.Class == proto.Class_ClassWarlock))
 		}
 	}
+
+	registerManaTideTotemCD(agent, manaTideTotemCount)
 }
 
 func DarkIntentAura(unit *Unit, isWarlock bool) *Aura {
@@ -1006,6 +1013,8 @@ func applyPetBuffEffects(petAgent PetAgent, raidBuffs *proto.RaidBuffs, partyBuf
 	raidBuffs.BlessingOfKings = false
 	raidBuffs.DrumsOfTheBurningWild = false
 
+	partyBuffs.ManaTideTotemCount = 0
+
 	individualBuffs.HymnOfHopeCount = 0
 	individualBuffs.InnervateCount = 0
 	individualBuffs.PowerInfusionCount = 0
@@ -1206,8 +1215,12 @@ func registerBloodlustCD(agent Agent, spellID int32) {
 }
 
 func BloodlustAura(character *Character, actionTag int32) *Aura {
-	actionID := BloodlustActionID.WithTag(actionTag)
+	return BloodlustEffectAura(character, BloodlustActionID.WithTag(actionTag))
+}
 
+// Registers a Bloodlust-style haste aura on the character and its pets. Used by
+// Bloodlust, Heroism, Time Warp and Ancient Hysteria, which differ only in their ID.
+func BloodlustEffectAura(character *Character, actionID ActionID) *Aura {
 	sated := character.GetOrRegisterAura(Aura{
 		Label:    SatedAuraLabel,
 		ActionID: ActionID{SpellID: 57724},
@@ -1216,7 +1229,7 @@ func BloodlustAura(character *Character, actionTag int32) *Aura {
 
 	for _, pet := range character.Pets {
 		if !pet.IsGuardian() {
-			BloodlustAura(&pet.Character, actionTag)
+			BloodlustEffectAura(&pet.Character, actionID)
 		}
 	}
 
@@ -1245,6 +1258,36 @@ func BloodlustAura(character *Character, actionTag int32) *Aura {
 	return aura
 }
 
+// Registers a Bloodlust-style aura on every player in the raid, for raid-wide casts.
+func RaidBloodlustAuras(raid *Raid, actionID ActionID) []*Aura {
+	var auras []*Aura
+	for _, party := range raid.Parties {
+		for _, partyMember := range party.Players {
+			auras = append(auras, BloodlustEffectAura(partyMember.GetCharacter(), actionID))
+		}
+	}
+	return auras
+}
+
+// Returns true if any player in the raid can still receive a Bloodlust-style effect.
+func RaidMissingSated(raid *Raid) bool {
+	for _, playerUnit := range raid.AllPlayerUnits {
+		if !playerUnit.HasActiveAura(SatedAuraLabel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Activates the given Bloodlust-style auras on every unit without Sated.
+func ActivateRaidBloodlust(sim *Simulation, auras []*Aura) {
+	for _, aura := range auras {
+		if !aura.Unit.HasActiveAura(SatedAuraLabel) {
+			aura.Activate(sim)
+		}
+	}
+}
+
 var PowerInfusionActionID = ActionID{SpellID: 10060}
 var PowerInfusionAuraTag = "PowerInfusion"
 
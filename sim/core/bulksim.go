@@ -19,10 +19,14 @@ import (
 
 const (
 	defaultIterationsPerCombo = 1000
+
+	// Maximum number of combos in a fast mode round for which environments are
+	// kept, so later rounds can reuse them instead of rebuilding.
+	maxCachedEnvironments = 128
 )
 
-// raidSimRunner runs a standard raid simulation.
-type raidSimRunner func(*proto.RaidSimRequest, chan *proto.ProgressMetrics, bool, chan bool) *proto.RaidSimResult
+// raidSimRunner runs a standard raid simulation in the given environment.
+type raidSimRunner func(*Environment, *proto.RaidSimRequest, chan *proto.ProgressMetrics, bool, chan bool) *proto.RaidSimResult
 
 // bulkSimRunner runs a bulk simulation.
 type bulkSimRunner struct {
@@ -34,7 +38,7 @@ type bulkSimRunner struct {
 
 func BulkSim(ctx context.Context, request *proto.BulkSimRequest, progress chan *proto.ProgressMetrics) *proto.BulkSimResult {
 	bulk := &bulkSimRunner{
-		SingleRaidSimRunner: runSim,
+		SingleRaidSimRunner: runSimWithEnv,
 		Request:             request,
 	}
 
@@ -59,6 +63,7 @@ type singleBulkSim struct {
 	req *proto.RaidSimRequest
 	cl  *raidSimRequestChangeLog
 	eq  *equipmentSubstitution
+	env *Environment // Environment from a previous round, if any.
 }
 
 func (b *bulkSimRunner) Run(pctx context.Context, progress chan *proto.ProgressMetrics) (result *proto.BulkSimResult, resultErr error) {
@@ -100,46 +105,9 @@ func (b *bulkSimRunner) Run(pctx context.Context, progress chan *proto.ProgressM
 	// We might have to add logic after slot decisions if we want to enforce keeping meta gem active.
 
 	if b.Request.BulkSettings.AutoGem {
-		for _, replaceItem := range b.Request.BulkSettings.Items {
-			itemData := ItemsByID[replaceItem.Id]
-			if len(itemData.GemSockets) == 0 && itemData.Type != proto.ItemType_ItemTypeWaist {
-				continue
-			}
-
-			sockets := make([]int32, len(itemData.GemSockets))
-			if len(sockets) < len(replaceItem.Gems) {
-				// this means the extra gem was specified, just add an extra element
-				sockets = append(sockets, 0)
-			}
-			// now copy over what we have from inputs.
-			copy(sockets, replaceItem.Gems)
-			if itemData.Type == proto.ItemType_ItemTypeWaist {
-				// Assume waist always has the eternal belt buckle and add extra red gem.
-				// TODO: is there a better way to do this?
-				// Should we have a 'prismatic' standard gem in the defaults?
-				if len(sockets) == len(itemData.GemSockets) {
-					sockets = append(sockets, b.Request.BulkSettings.DefaultRedGem)
-				} else if len(sockets) > len(itemData.GemSockets) && sockets[len(sockets)-1] == 0 {
-					sockets[len(sockets)-1] = b.Request.BulkSettings.DefaultRedGem
-				}
-			}
-
-			for i, color := range itemData.GemSockets {
-				if sockets[i] > 0 {
-					// This means gem was already specified, skip autogem
-					continue
-				}
-				if ColorIntersects(color, proto.GemColor_GemColorRed) {
-					sockets[i] = b.Request.BulkSettings.DefaultRedGem
-				} else if ColorIntersects(color, proto.GemColor_GemColorYellow) {
-					sockets[i] = b.Request.BulkSettings.DefaultYellowGem
-				} else if ColorIntersects(color, proto.GemColor_GemColorBlue) {
-					sockets[i] = b.Request.BulkSettings.DefaultBlueGem
-				} else if ColorIntersects(color, proto.GemColor_GemColorMeta) {
-					sockets[i] = b.Request.BulkSettings.DefaultMetaGem
-				}
-			}
-			replaceItem.Gems = sockets
+		settings := b.Request.BulkSettings
+		for _, replaceItem := range settings.Items {
+			autoGemItem(replaceItem, settings.DefaultRedGem, settings.DefaultYellowGem, settings.DefaultBlueGem, settings.DefaultMetaGem)
 		}
 	}
 
@@ -268,6 +236,7 @@ func (b *bulkSimRunner) Run(pctx context.Context, progress chan *proto.ProgressM
 				req: comb.Request,
 				cl:  comb.ChangeLog,
 				eq:  comb.Substitution,
+				env: comb.Env,
 			}
 		}
 	}
@@ -328,6 +297,10 @@ func (b *bulkSimRunner) getRankedResults(pctx context.Context, validCombos []sin
 	results := make(chan *itemSubstitutionSimResult, 10)
 
 	numCombinations := int32(len(validCombos))
+
+	// Only fast mode re-sims the same combos, so that's the only time it's
+	// worth keeping environments around.
+	cacheEnvs := b.Request.BulkSettings.FastMode && numCombinations <= maxCachedEnvironments
 	totalIterationsUpperBound := int64(numCombinations) * iterations
 
 	var totalCompletedIterations int32
@@ -336,6 +309,9 @@ func (b *bulkSimRunner) getRankedResults(pctx context.Context, validCombos []sin
 	ctx, cancel := context.WithCancel(pctx)
 	// reporter for all sims combined.
 	go func() {
+		if progress == nil {
+			return
+		}
 		for ctx.Err() == nil {
 			complIters := atomic.LoadInt32(&totalCompletedIterations)
 			complSims := atomic.LoadInt32(&totalCompletedSims)
@@ -378,12 +354,20 @@ func (b *bulkSimRunner) getRankedResults(pctx context.Context, validCombos []sin
 				// overwrite the requests iterations with the input for this function.
 
 				sub.req.SimOptions.Iterations = int32(iterations)
-				results <- &itemSubstitutionSimResult{
+				env := sub.env
+				if env == nil {
+					env = &Environment{}
+				}
+				result := &itemSubstitutionSimResult{
 					Request:      sub.req,
-					Result:       b.SingleRaidSimRunner(sub.req, singleSimProgress, false, nil),
+					Result:       b.SingleRaidSimRunner(env, sub.req, singleSimProgress, false, nil),
 					Substitution: sub.eq,
 					ChangeLog:    sub.cl,
 				}
+				if cacheEnvs && env.IsFinalized() {
+					result.Env = env
+				}
+				results <- result
 				atomic.AddInt32(&totalCompletedSims, 1)
 				tickets <- struct{}{} // when done, allow for new sim to be launched.
 			}(singleCombo)
@@ -412,6 +396,49 @@ func (b *bulkSimRunner) getRankedResults(pctx context.Context, validCombos []sin
 	return rankedResults, baseResult, nil
 }
 
+// autoGemItem fills the empty sockets of the item with the given default gems.
+func autoGemItem(replaceItem *proto.ItemSpec, redGem int32, yellowGem int32, blueGem int32, metaGem int32) {
+	itemData := ItemsByID[replaceItem.Id]
+	if len(itemData.GemSockets) == 0 && itemData.Type != proto.ItemType_ItemTypeWaist {
+		return
+	}
+
+	sockets := make([]int32, len(itemData.GemSockets))
+	if len(sockets) < len(replaceItem.Gems) {
+		// this means the extra gem was specified, just add an extra element
+		sockets = append(sockets, 0)
+	}
+	// now copy over what we have from inputs.
+	copy(sockets, replaceItem.Gems)
+	if itemData.Type == proto.ItemType_ItemTypeWaist {
+		// Assume waist always has the eternal belt buckle and add extra red gem.
+		// TODO: is there a better way to do this?
+		// Should we have a 'prismatic' standard gem in the defaults?
+		if len(sockets) == len(itemData.GemSockets) {
+			sockets = append(sockets, redGem)
+		} else if len(sockets) > len(itemData.GemSockets) && sockets[len(sockets)-1] == 0 {
+			sockets[len(sockets)-1] = redGem
+		}
+	}
+
+	for i, color := range itemData.GemSockets {
+		if sockets[i] > 0 {
+			// This means gem was already specified, skip autogem
+			continue
+		}
+		if ColorIntersects(color, proto.GemColor_GemColorRed) {
+			sockets[i] = redGem
+		} else if ColorIntersects(color, proto.GemColor_GemColorYellow) {
+			sockets[i] = yellowGem
+		} else if ColorIntersects(color, proto.GemColor_GemColorBlue) {
+			sockets[i] = blueGem
+		} else if ColorIntersects(color, proto.GemColor_GemColorMeta) {
+			sockets[i] = metaGem
+		}
+	}
+	replaceItem.Gems = sockets
+}
+
 // itemSubstitutionSimResult stores the request and response of a simulation, along with the used
 // equipment susbstitution and a changelog of which items were added and removed from the base
 // equipment set.
@@ -420,6 +447,7 @@ type itemSubstitutionSimResult struct {
 	Result       *proto.RaidSimResult
 	Substitution *equipmentSubstitution
 	ChangeLog    *raidSimRequestChangeLog
+	Env          *Environment
 }
 
 // Score used to rank results.
@@ -482,40 +510,7 @@ func (es *equipmentSubstitution) CanonicalHash() string {
 // isValidEquipment returns true if the specified equipment spec is valid. An equipment spec
 // is valid if it does not reference a two-hander and off-hand weapon combo.
 func isValidEquipment(equipment *proto.EquipmentSpec) bool {
-	var usesTwoHander, usesOffhand bool
-
-	// Validate weapons
-	if knownItem, ok := ItemsByID[equipment.Items[proto.ItemSlot_ItemSlotMainHand].Id]; ok {
-		usesTwoHander = knownItem.HandType == proto.HandType_HandTypeTwoHand
-	}
-	if knownItem, ok := ItemsByID[equipment.Items[proto.ItemSlot_ItemSlotOffHand].Id]; ok {
-		usesOffhand = knownItem.HandType == proto.HandType_HandTypeOffHand
-	}
-	if usesTwoHander && usesOffhand {
-		return false
-	}
-
-	// Validate trinkets/rings for duplicate IDs
-	if equipment.Items[proto.ItemSlot_ItemSlotFinger1].Id == equipment.Items[proto.ItemSlot_ItemSlotFinger2].Id && equipment.Items[proto.ItemSlot_ItemSlotFinger1].Id != 0 {
-		return false
-	} else if equipment.Items[proto.ItemSlot_ItemSlotTrinket1].Id == equipment.Items[proto.ItemSlot_ItemSlotTrinket2].Id && equipment.Items[proto.ItemSlot_ItemSlotTrinket1].Id != 0 {
-		return false
-	}
-
-	// Validate rings/trinkets for heroic/non-heroic (matching name)
-	f1, ok1 := ItemsByID[equipment.Items[proto.ItemSlot_ItemSlotFinger1].Id]
-	f2, ok2 := ItemsByID[equipment.Items[proto.ItemSlot_ItemSlotFinger2].Id]
-	if ok1 && ok2 && f1.Name == f2.Name {
-		return false
-	}
-
-	t1, ok1 := ItemsByID[equipment.Items[proto.ItemSlot_ItemSlotTrinket1].Id]
-	t2, ok2 := ItemsByID[equipment.Items[proto.ItemSlot_ItemSlotTrinket2].Id]
-	if ok1 && ok2 && t1.Name == t2.Name {
-		return false
-	}
-
-	return true
+	return len(equipmentConflicts(equipment)) == 0
 }
 
 // generateAllEquipmentSubstitutions generates all possible valid equipment substitutions for the
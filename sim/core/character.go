@@ -321,7 +321,17 @@ func (character *Character) CalculateMasteryPoints() float64 {
 
 // Apply effects from all equipped core.
 func (character *Character) applyItemEffects(agent Agent) {
+	swap := &character.ItemSwap
+	if swap.IsEnabled() {
+		swap.registerSetAuras()
+	}
+
 	for slot, eq := range character.Equipment {
+		if swap.IsEnabled() && slices.Contains(swap.slots, proto.ItemSlot(slot)) {
+			swap.applySlotEffects(agent, proto.ItemSlot(slot), eq)
+			continue
+		}
+
 		if applyItemEffect, ok := itemEffects[eq.ID]; ok {
 			applyItemEffect(agent)
 		}
@@ -341,15 +351,10 @@ func (character *Character) applyItemEffects(agent Agent) {
 		}
 	}
 
-	if character.ItemSwap.IsEnabled() {
-		offset := int(proto.ItemSlot_ItemSlotMainHand)
-		for i, item := range character.ItemSwap.unEquippedItems {
-			if applyEnchantEffect, ok := enchantEffects[item.Enchant.EffectID]; ok {
-				applyEnchantEffect(agent)
-			}
-
-			if applyWeaponEffect, ok := weaponEffects[item.Enchant.EffectID]; ok {
-				applyWeaponEffect(agent, proto.ItemSlot(offset+i))
+	if swap.IsEnabled() {
+		for _, set := range swap.sets[mainSwapSet+1:] {
+			for _, slot := range set.slots {
+				swap.applySlotEffects(agent, slot, set.items[slot])
 			}
 		}
 	}
@@ -439,7 +444,7 @@ func (character *Character) initialize(agent Agent) {
 				return
 			}
 
-			if sim.Options.Interactive {
+			if character.IsInteractive(sim) {
 				if character.GCD.IsReady(sim) {
 					sim.NeedsInput = true
 				}
@@ -464,6 +469,8 @@ func (character *Character) Finalize() {
 	character.Unit.finalize()
 
 	character.majorCooldownManager.finalize()
+
+	character.Metrics.registerMitigationCooldowns(&character.Unit, character.initialMajorCooldowns)
 }
 
 func (character *Character) FillPlayerStats(playerStats *proto.PlayerStats) {
@@ -500,6 +507,7 @@ func (character *Character) FillPlayerStats(playerStats *proto.PlayerStats) {
 
 func (character *Character) reset(sim *Simulation, agent Agent) {
 	character.Unit.reset(sim, agent)
+	character.ItemSwap.reset(sim)
 	character.majorCooldownManager.reset(sim)
 	character.CurrentTarget = character.defaultTarget
 
@@ -639,6 +647,10 @@ func (character *Character) GetMetricsProto() *proto.UnitMetrics {
 	metrics.Name = character.Name
 	metrics.UnitIndex = character.UnitIndex
 	metrics.Auras = character.auraTracker.GetMetricsProto()
+	if character.Rotation != nil {
+		metrics.AplTrace = character.Rotation.trace.toProto()
+		metrics.RotationStats = character.Rotation.getCoverageStats()
+	}
 
 	metrics.Pets = make([]*proto.UnitMetrics, len(character.Pets))
 	for i, pet := range character.Pets {
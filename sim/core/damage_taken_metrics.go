@@ -0,0 +1,296 @@
+package core
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/stats"
+)
+
+type damageTakenKey struct {
+	actionID        ActionID
+	sourceUnitIndex int32
+}
+
+// Damage taken from a single source ability, summed over all iterations.
+type DamageTakenMetrics struct {
+	key damageTakenKey
+
+	School stats.SchoolIndex
+
+	Hits    int32
+	Crits   int32
+	Misses  int32
+	Dodges  int32
+	Parries int32
+	Blocks  int32
+
+	Damage         float64
+	ResistedDamage float64
+	ReducedDamage  float64
+	AvoidedDamage  float64
+	BlockedDamage  float64
+	AbsorbedDamage float64
+}
+
+func (dtm *DamageTakenMetrics) ToProto() *proto.DamageTakenMetrics {
+	return &proto.DamageTakenMetrics{
+		Id:              dtm.key.actionID.ToProto(),
+		SourceUnitIndex: dtm.key.sourceUnitIndex,
+		School:          proto.SpellSchool(max(dtm.School, stats.SchoolIndexPhysical) - stats.SchoolIndexPhysical),
+
+		Hits:    dtm.Hits,
+		Crits:   dtm.Crits,
+		Misses:  dtm.Misses,
+		Dodges:  dtm.Dodges,
+		Parries: dtm.Parries,
+		Blocks:  dtm.Blocks,
+
+		Damage:         dtm.Damage,
+		ResistedDamage: dtm.ResistedDamage,
+		ReducedDamage:  dtm.ReducedDamage,
+		AvoidedDamage:  dtm.AvoidedDamage,
+		BlockedDamage:  dtm.BlockedDamage,
+		AbsorbedDamage: dtm.AbsorbedDamage,
+	}
+}
+
+// The parts of a unit's defenses that cooldowns can change.
+type mitigationSnapshot struct {
+	damageTakenMultiplier [stats.SchoolLen]float64
+	armor                 float64
+	dodge                 float64
+	parry                 float64
+}
+
+func (unit *Unit) mitigationSnapshot() mitigationSnapshot {
+	snapshot := mitigationSnapshot{
+		armor: unit.Armor(),
+		dodge: unit.PseudoStats.BaseDodge + unit.GetDiminishedDodgeChance(),
+		parry: unit.PseudoStats.BaseParry + unit.GetDiminishedParryChance(),
+	}
+	for i := range snapshot.damageTakenMultiplier {
+		snapshot.damageTakenMultiplier[i] = unit.PseudoStats.DamageTakenMultiplier * unit.PseudoStats.SchoolDamageTakenMultiplier[i]
+	}
+	return snapshot
+}
+
+// Damage taken while a defensive cooldown was active, and the damage the
+// cooldown itself prevented, summed over all iterations.
+type CooldownMitigationMetrics struct {
+	aura *Aura
+
+	// Defenses right before and right after the cooldown was last used.
+	without mitigationSnapshot
+	with    mitigationSnapshot
+	applied bool
+
+	Hits            int32
+	DamageTaken     float64
+	DamageMitigated float64
+}
+
+func (cmm *CooldownMitigationMetrics) ToProto() *proto.CooldownMitigationMetrics {
+	return &proto.CooldownMitigationMetrics{
+		Id:              cmm.aura.ActionID.ToProto(),
+		Hits:            cmm.Hits,
+		DamageTaken:     cmm.DamageTaken,
+		DamageMitigated: cmm.DamageMitigated,
+	}
+}
+
+// Registers the auras of survival cooldowns, so damage mitigated while they
+// are active can be attributed to them.
+func (unitMetrics *UnitMetrics) registerMitigationCooldowns(unit *Unit, mcds []MajorCooldown) {
+	unitMetrics.cooldownMitigation = nil
+	for _, mcd := range mcds {
+		if !mcd.Type.Matches(CooldownTypeSurvival) {
+			continue
+		}
+
+		aura := unit.GetAuraByID(mcd.Spell.ActionID)
+		if aura == nil {
+			for _, candidate := range unit.auras {
+				if candidate.ActionID.SameActionIgnoreTag(mcd.Spell.ActionID) {
+					aura = candidate
+					break
+				}
+			}
+		}
+		if aura == nil {
+			continue
+		}
+
+		cmm := &CooldownMitigationMetrics{aura: aura}
+		unitMetrics.cooldownMitigation = append(unitMetrics.cooldownMitigation, cmm)
+
+		// Compare the unit's defenses around the activation, which also covers
+		// exclusive effects that are applied before the aura's OnGain.
+		var without mitigationSnapshot
+		aura.ApplyOnActivate(func(_ *Aura, _ *Simulation) {
+			without = unit.mitigationSnapshot()
+		})
+		aura.ApplyOnGain(func(_ *Aura, _ *Simulation) {
+			cmm.without = without
+			cmm.with = unit.mitigationSnapshot()
+			cmm.applied = true
+		})
+	}
+}
+
+// Returns the damage this hit would have done without the cooldown, minus the
+// damage it did. Only the cooldown's own changes to damage taken multipliers,
+// armor and avoidance are counted; absorbs and the unit's base mitigation are
+// not.
+func (cmm *CooldownMitigationMetrics) mitigatedBy(spell *Spell, result *SpellResult) float64 {
+	if !cmm.applied {
+		return 0
+	}
+
+	target := result.Target
+	if !result.Landed() {
+		// Attribute the share of the avoidance chance the cooldown added.
+		var added, chance float64
+		attackTable := spell.Unit.AttackTables[target.UnitIndex]
+		if result.Outcome.Matches(OutcomeDodge) {
+			added = cmm.with.dodge - cmm.without.dodge
+			chance = target.GetTotalDodgeChanceAsDefender(attackTable)
+		} else if result.Outcome.Matches(OutcomeParry) {
+			added = cmm.with.parry - cmm.without.parry
+			chance = target.GetTotalParryChanceAsDefender(attackTable)
+		}
+		if added <= 0 || chance <= 0 {
+			return 0
+		}
+		return result.afterTargetMods * min(1, added/chance)
+	}
+
+	ratio := 1.0
+	if with := cmm.with.damageTakenMultiplier[spell.SchoolIndex]; with > 0 {
+		ratio = cmm.without.damageTakenMultiplier[spell.SchoolIndex] / with
+	}
+	if addedArmor := cmm.with.armor - cmm.without.armor; addedArmor != 0 && result.afterResistances < result.afterAttackerMods && spell.SchoolIndex == stats.SchoolIndexPhysical {
+		armorConstant := armorConstant(spell.Unit)
+		armor := target.Armor()
+		ratio *= (armor + armorConstant) / (max(0, armor-addedArmor) + armorConstant)
+	}
+	return max(0, result.afterOutcome*(ratio-1))
+}
+
+// Returns the metrics for damage from spell to target, looking up the map only
+// when the spell hasn't hit the target before or its action ID changed.
+func (spell *Spell) getDamageTakenMetrics(target *Unit) *DamageTakenMetrics {
+	if spell.damageTakenMetrics == nil {
+		spell.damageTakenMetrics = make([]*DamageTakenMetrics, len(spell.Unit.Env.AllUnits))
+	}
+	dtm := spell.damageTakenMetrics[target.UnitIndex]
+	if dtm != nil && dtm.key.actionID == spell.ActionID {
+		return dtm
+	}
+
+	key := damageTakenKey{
+		actionID:        spell.ActionID,
+		sourceUnitIndex: spell.Unit.UnitIndex,
+	}
+	dtm, ok := target.Metrics.damageTaken[key]
+	if !ok {
+		dtm = &DamageTakenMetrics{key: key, School: spell.SchoolIndex}
+		target.Metrics.damageTaken[key] = dtm
+	}
+	spell.damageTakenMetrics[target.UnitIndex] = dtm
+	return dtm
+}
+
+func (unitMetrics *UnitMetrics) addDamageTaken(spell *Spell, result *SpellResult) {
+	dtm := spell.getDamageTakenMetrics(result.Target)
+
+	if result.Landed() {
+		dtm.Hits++
+		if result.DidCrit() {
+			dtm.Crits++
+		}
+		if result.Outcome.Matches(OutcomeBlock) {
+			dtm.Blocks++
+		}
+	} else if result.Outcome.Matches(OutcomeMiss) {
+		dtm.Misses++
+	} else if result.Outcome.Matches(OutcomeDodge) {
+		dtm.Dodges++
+	} else if result.Outcome.Matches(OutcomeParry) {
+		dtm.Parries++
+	}
+	dtm.Damage += result.Damage
+
+	// Results that didn't go through the damage calculation (e.g. fixed
+	// damage) have no mitigation breakdown.
+	if result.afterAttackerMods == 0 {
+		return
+	}
+
+	resisted := result.afterAttackerMods - result.afterResistances
+	reduced := result.afterResistances - result.afterTargetMods
+	avoided := 0.0
+	blocked := 0.0
+	absorbed := 0.0
+	if result.Outcome.Matches(OutcomeMiss | OutcomeDodge | OutcomeParry) {
+		avoided = result.afterTargetMods
+	} else {
+		if result.Outcome.Matches(OutcomeBlock) {
+			blocked = max(0, result.afterTargetMods-result.afterOutcome)
+		}
+		absorbed = max(0, result.afterOutcome-result.Damage)
+	}
+
+	dtm.ResistedDamage += resisted
+	dtm.ReducedDamage += reduced
+	dtm.AvoidedDamage += avoided
+	dtm.BlockedDamage += blocked
+	dtm.AbsorbedDamage += absorbed
+
+	for _, cmm := range unitMetrics.cooldownMitigation {
+		if cmm.aura.IsActive() {
+			cmm.Hits++
+			cmm.DamageTaken += result.Damage
+			cmm.DamageMitigated += cmm.mitigatedBy(spell, result)
+		}
+	}
+}
+
+// Zeroes the damage taken metrics in place, so pointers cached on spells stay
+// valid.
+func (unitMetrics *UnitMetrics) clearDamageTaken() {
+	for _, dtm := range unitMetrics.damageTaken {
+		*dtm = DamageTakenMetrics{key: dtm.key, School: dtm.School}
+	}
+	for _, cmm := range unitMetrics.cooldownMitigation {
+		cmm.Hits = 0
+		cmm.DamageTaken = 0
+		cmm.DamageMitigated = 0
+	}
+}
+
+func (unitMetrics *UnitMetrics) damageTakenToProto() []*proto.DamageTakenMetrics {
+	keys := make([]damageTakenKey, 0, len(unitMetrics.damageTaken))
+	for key, dtm := range unitMetrics.damageTaken {
+		if *dtm != (DamageTakenMetrics{key: dtm.key, School: dtm.School}) {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b damageTakenKey) int {
+		if a.sourceUnitIndex != b.sourceUnitIndex {
+			return cmp.Compare(a.sourceUnitIndex, b.sourceUnitIndex)
+		}
+		return cmp.Compare(a.actionID.String(), b.actionID.String())
+	})
+
+	return MapSlice(keys, func(key damageTakenKey) *proto.DamageTakenMetrics {
+		return unitMetrics.damageTaken[key].ToProto()
+	})
+}
+
+func (unitMetrics *UnitMetrics) cooldownMitigationToProto() []*proto.CooldownMitigationMetrics {
+	return MapSlice(unitMetrics.cooldownMitigation, func(cmm *CooldownMitigationMetrics) *proto.CooldownMitigationMetrics {
+		return cmm.ToProto()
+	})
+}
@@ -0,0 +1,91 @@
+package core_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+func TestDamageTakenMetrics(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerBloodDk())
+	rsr.Raid.Tanks = []*proto.UnitReference{{Type: proto.UnitReference_Player, Index: 0}}
+	rsr.SimOptions.Iterations = 50
+
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatal(result.ErrorResult)
+	}
+
+	melee := checkDamageTakenTotal(t, rsr, result)
+
+	if melee == nil {
+		t.Fatalf("expected boss melee in damage taken metrics")
+	}
+	if melee.School != proto.SpellSchool_SpellSchoolPhysical {
+		t.Fatalf("boss melee should be physical, got %s", melee.School)
+	}
+	if melee.Dodges == 0 || melee.Parries == 0 || melee.AvoidedDamage <= 0 {
+		t.Fatalf("expected avoided boss melee, got %d dodges, %d parries, %f avoided", melee.Dodges, melee.Parries, melee.AvoidedDamage)
+	}
+	if melee.ResistedDamage <= 0 {
+		t.Fatalf("expected armor to mitigate boss melee, got %f", melee.ResistedDamage)
+	}
+
+	// Cooldowns only get credit for the damage reduction they add, e.g. 50%
+	// for Icebound Fortitude prevents as much damage as is taken, and nothing
+	// for Vampiric Blood, which doesn't reduce damage.
+	expectedMitigatedRatio := map[int32]float64{
+		48792: 1,    // Icebound Fortitude
+		49222: 0.25, // Bone Shield
+		55233: 0,    // Vampiric Blood
+	}
+	player := result.RaidMetrics.Parties[0].Players[0]
+	for _, cmm := range player.CooldownMitigation {
+		expected, ok := expectedMitigatedRatio[cmm.Id.GetSpellId()]
+		if !ok {
+			continue
+		}
+		delete(expectedMitigatedRatio, cmm.Id.GetSpellId())
+		if cmm.Hits == 0 {
+			t.Fatalf("expected hits while %v was active", cmm.Id)
+		}
+		if ratio := cmm.DamageMitigated / cmm.DamageTaken; math.Abs(ratio-expected) > 1e-6 {
+			t.Fatalf("expected %v to mitigate %f of the damage taken, got %f", cmm.Id, expected, ratio)
+		}
+	}
+	if len(expectedMitigatedRatio) != 0 {
+		t.Fatalf("missing cooldown mitigation metrics for %v", expectedMitigatedRatio)
+	}
+
+	// Breakdowns from multiple threads are merged per ability.
+	checkDamageTakenTotal(t, rsr, core.RunConcurrentRaidSimSync(rsr))
+}
+
+// Checks that the per-ability breakdown adds up to the tank's DTPS, and returns
+// the boss melee entry.
+func checkDamageTakenTotal(t *testing.T, rsr *proto.RaidSimRequest, result *proto.RaidSimResult) *proto.DamageTakenMetrics {
+	player := result.RaidMetrics.Parties[0].Players[0]
+	if len(player.DamageTaken) == 0 {
+		t.Fatalf("expected damage taken metrics for the tank")
+	}
+
+	totalDamage := 0.0
+	var melee *proto.DamageTakenMetrics
+	for _, dtm := range player.DamageTaken {
+		totalDamage += dtm.Damage
+		if dtm.Id.GetOtherId() == proto.OtherAction_OtherActionAttack {
+			if melee != nil {
+				t.Fatalf("boss melee appears multiple times in damage taken metrics")
+			}
+			melee = dtm
+		}
+	}
+
+	expectedDamage := player.Dtps.Avg * rsr.Encounter.Duration * float64(rsr.SimOptions.Iterations)
+	if math.Abs(totalDamage-expectedDamage) > expectedDamage*1e-6 {
+		t.Fatalf("damage taken breakdown sums to %f, expected %f", totalDamage, expectedDamage)
+	}
+	return melee
+}
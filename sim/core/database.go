@@ -17,6 +17,11 @@ var RandomSuffixesByID = map[int32]RandomSuffix{}
 var EnchantsByEffectID = map[int32]Enchant{}
 var ReforgeStatsByID = map[int32]ReforgeStat{}
 
+// Items, enchants, zones and NPCs from the UI database, used to search items by
+// where they drop and to name entries in reports. Only populated when built
+// with the database.
+var uiItemDatabase = &proto.UIDatabase{}
+
 func addToDatabase(newDB *proto.SimDatabase) {
 	for _, v := range newDB.Items {
 		if _, ok := ItemsByID[v.Id]; !ok {
@@ -75,4 +75,11 @@ func init() {
 	}
 
 	addToDatabase(simDB)
+
+	uiItemDatabase = &proto.UIDatabase{
+		Items:    db.Items,
+		Enchants: db.Enchants,
+		Zones:    db.Zones,
+		Npcs:     db.Npcs,
+	}
 }
@@ -221,6 +221,22 @@ func (dot *Dot) RecomputeAuraDuration() {
 	}
 }
 
+// Tick period a new application of this dot would have at the current haste.
+func (dot *Dot) expectedTickPeriod() time.Duration {
+	if dot.AffectedByCastSpeed {
+		return dot.Spell.Unit.ApplyCastSpeedForSpell(dot.TickLength, dot.Spell)
+	}
+	return dot.TickLength
+}
+
+// Number of ticks a new application of this dot would have at the current haste.
+func (dot *Dot) expectedTickCount() int32 {
+	if dot.AffectedByCastSpeed && !dot.isChanneled && !dot.HasteAffectsDuration {
+		return int32(round(float64(dot.GetBaseDuration()) / float64(dot.expectedTickPeriod())))
+	}
+	return dot.NumberOfTicks
+}
+
 func (dot *Dot) AddTicks(num int32) {
 	dot.BaseTickCount += num
 	dot.NumberOfTicks += num
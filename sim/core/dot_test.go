@@ -85,6 +85,9 @@ func NewFakeElementalShaman(char *Character, _ *proto.Player) Agent {
 				}
 				spell.DealOutcome(sim, result)
 			},
+			ExpectedTickDamage: func(sim *Simulation, target *Unit, spell *Spell, _ bool) *SpellResult {
+				return spell.CalcPeriodicDamage(sim, target, 100, spell.OutcomeExpectedTick)
+			},
 		})
 		fa.Dot = fa.Spell.CurDot()
 	}
@@ -176,3 +179,23 @@ func TestDotSnapshotSpellMultiplier(t *testing.T) {
 	fa.Dot.Rollover(sim)
 	expectDotTickDamage(t, sim, fa.Dot, 300) // (100) * 1.5 * 2
 }
+
+func TestDotExpectedDamage(t *testing.T) {
+	sim := SetupFakeSim()
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	target := sim.GetTargetUnit(0)
+
+	tickDamage := fa.Spell.ExpectedTickDamage(sim, target)
+	if damage := fa.Spell.ExpectedDamage(sim, target); !WithinToleranceFloat64(tickDamage*6, damage, 0.01) {
+		t.Fatalf("Incorrect expected damage: Expected: %0.3f, Actual: %0.3f", tickDamage*6, damage)
+	}
+
+	// Haste adds ticks to the dot, since its duration stays the same.
+	fa.MultiplyCastSpeed(1.5)
+	if damage := fa.Spell.ExpectedDamage(sim, target); !WithinToleranceFloat64(tickDamage*9, damage, 0.01) {
+		t.Fatalf("Incorrect expected damage with haste: Expected: %0.3f, Actual: %0.3f", tickDamage*9, damage)
+	}
+	if executeTime := fa.Spell.ExpectedExecuteTime(target); executeTime != GCDMin {
+		t.Fatalf("Incorrect expected execute time: Expected: %s, Actual: %s", GCDMin, executeTime)
+	}
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/stats"
+)
+
+// Lists the items, gems, enchants and item sets in the database whose special
+// effects are not registered in the sim. Effects register themselves in init
+// functions, so callers need to import the packages with the effects (e.g. via
+// sim.RegisterAll) for the report to be accurate.
+func EffectCoverage(_ *proto.EffectCoverageRequest) *proto.EffectCoverageResult {
+	result := &proto.EffectCoverageResult{}
+
+	// The database doesn't say which items have an effect, so every item
+	// without one in the effect registry is reported, and callers match the
+	// list against the item tooltips.
+	for _, item := range ItemsByID {
+		if !HasItemEffect(item.ID) {
+			result.Unimplemented = append(result.Unimplemented, &proto.UnimplementedEffect{
+				Source: proto.EffectSource_EffectSourceItem,
+				Id:     item.ID,
+				Name:   item.Name,
+			})
+		}
+	}
+
+	for _, gem := range GemsByID {
+		if gem.Color == proto.GemColor_GemColorMeta && !HasItemEffect(gem.ID) {
+			result.Unimplemented = append(result.Unimplemented, &proto.UnimplementedEffect{
+				Source: proto.EffectSource_EffectSourceGem,
+				Id:     gem.ID,
+				Name:   gem.Name,
+			})
+		}
+	}
+
+	enchantNames := make(map[int32]string, len(uiItemDatabase.Enchants))
+	for _, enchant := range uiItemDatabase.Enchants {
+		enchantNames[enchant.EffectId] = enchant.Name
+	}
+	for _, enchant := range EnchantsByEffectID {
+		if enchant.Stats.Equals(stats.Stats{}) && !HasEnchantEffect(enchant.EffectID) && !HasWeaponEffect(enchant.EffectID) {
+			result.Unimplemented = append(result.Unimplemented, &proto.UnimplementedEffect{
+				Source: proto.EffectSource_EffectSourceEnchant,
+				Id:     enchant.EffectID,
+				Name:   enchantNames[enchant.EffectID],
+			})
+		}
+	}
+
+	setItems := map[string][]Item{}
+	for _, item := range ItemsByID {
+		if item.SetName != "" {
+			setItems[item.SetName] = append(setItems[item.SetName], item)
+		}
+	}
+	registeredSets := map[string]ItemSet{}
+	for _, set := range sets {
+		registeredSets[set.Name] = *set
+		if set.AlternativeName != "" {
+			registeredSets[set.AlternativeName] = *set
+		}
+	}
+	for setName, items := range setItems {
+		var bonuses map[int32]ApplyEffect
+		if set, ok := registeredSets[setName]; ok {
+			bonuses = set.Bonuses
+		}
+
+		itemIDs := MapSlice(items, func(item Item) int32 { return item.ID })
+		slices.Sort(itemIDs)
+		for _, numPieces := range setBonusThresholds(items) {
+			if _, ok := bonuses[numPieces]; !ok {
+				result.Unimplemented = append(result.Unimplemented, &proto.UnimplementedEffect{
+					Source:    proto.EffectSource_EffectSourceItemSet,
+					Name:      setName,
+					ItemIds:   itemIDs,
+					NumPieces: numPieces,
+				})
+			}
+		}
+	}
+
+	slices.SortFunc(result.Unimplemented, func(a, b *proto.UnimplementedEffect) int {
+		if a.Source != b.Source {
+			return cmp.Compare(a.Source, b.Source)
+		}
+		if a.Id != b.Id {
+			return cmp.Compare(a.Id, b.Id)
+		}
+		if a.Name != b.Name {
+			return cmp.Compare(a.Name, b.Name)
+		}
+		return cmp.Compare(a.NumPieces, b.NumPieces)
+	})
+	return result
+}
+
+// Returns the piece counts a set is expected to have bonuses at. Normal and
+// heroic versions of a piece share a set and a name, so pieces are counted by
+// name. Tier and PvP sets have bonuses at 2 and 4 pieces, smaller sets only
+// when complete.
+func setBonusThresholds(items []Item) []int32 {
+	names := map[string]bool{}
+	for _, item := range items {
+		names[item.Name] = true
+	}
+	if len(names) >= 4 {
+		return []int32{2, 4}
+	}
+	return []int32{int32(len(names))}
+}
@@ -0,0 +1,53 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+func TestEffectCoverage(t *testing.T) {
+	if !core.WITH_DB {
+		t.Skip("effect coverage needs the database")
+	}
+
+	result := core.EffectCoverage(&proto.EffectCoverageRequest{})
+	if len(result.Unimplemented) == 0 {
+		t.Fatalf("expected unimplemented effects in the database")
+	}
+
+	reportedItems := map[int32]bool{}
+	for i, effect := range result.Unimplemented {
+		switch effect.Source {
+		case proto.EffectSource_EffectSourceItem, proto.EffectSource_EffectSourceGem:
+			if core.HasItemEffect(effect.Id) {
+				t.Fatalf("%s (%d) has a registered effect", effect.Name, effect.Id)
+			}
+			if effect.Source == proto.EffectSource_EffectSourceItem {
+				reportedItems[effect.Id] = true
+			}
+		case proto.EffectSource_EffectSourceEnchant:
+			if core.HasEnchantEffect(effect.Id) || core.HasWeaponEffect(effect.Id) {
+				t.Fatalf("enchant %d has a registered effect", effect.Id)
+			}
+		case proto.EffectSource_EffectSourceItemSet:
+			if len(effect.ItemIds) == 0 {
+				t.Fatalf("item set %s has no items", effect.Name)
+			}
+			if effect.NumPieces <= 0 || int(effect.NumPieces) > len(effect.ItemIds) {
+				t.Fatalf("item set %s has an invalid bonus threshold %d", effect.Name, effect.NumPieces)
+			}
+		}
+
+		if i > 0 && effect.Source < result.Unimplemented[i-1].Source {
+			t.Fatalf("unimplemented effects are not sorted by source")
+		}
+	}
+
+	for id, item := range core.ItemsByID {
+		if !core.HasItemEffect(id) && !reportedItems[id] {
+			t.Fatalf("%s (%d) has no registered effect but was not reported", item.Name, id)
+		}
+	}
+}
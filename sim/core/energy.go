@@ -68,6 +68,30 @@ func (eb *energyBar) EnergyRegenPerSecond() float64 {
 	return 10.0 * eb.hasteRatingMultiplier * eb.energyRegenMultiplier
 }
 
+func (eb *energyBar) energyRegenTicks() regenTicks {
+	return regenTicks{
+		next:   eb.nextEnergyTick,
+		period: eb.EnergyTickDuration,
+		amount: eb.EnergyPerTick * eb.hasteRatingMultiplier * eb.energyRegenMultiplier,
+	}
+}
+
+// Returns the time until regen brings energy to the given amount, or
+// NeverExpires if it never will.
+func (eb *energyBar) TimeUntilEnergy(sim *Simulation, amount float64) time.Duration {
+	return forecastTimeUntil(sim, eb.currentEnergy, eb.maxEnergy, amount, eb.energyRegenTicks())
+}
+
+// Returns the energy at the given time, from regen alone.
+func (eb *energyBar) EnergyAt(_ *Simulation, at time.Duration) float64 {
+	return forecastAt(eb.currentEnergy, eb.maxEnergy, at, eb.energyRegenTicks())
+}
+
+// Returns the energy missing from the energy cap.
+func (eb *energyBar) EnergyDeficit() float64 {
+	return eb.maxEnergy - eb.currentEnergy
+}
+
 func (eb *energyBar) AddEnergy(sim *Simulation, amount float64, metrics *ResourceMetrics) {
 	if amount < 0 {
 		panic("Trying to add negative energy!")
@@ -51,6 +51,12 @@ func NewEnvironment(raidProto *proto.Raid, encounterProto *proto.Encounter, runF
 		State: Created,
 	}
 
+	raidStats, encounterStats := env.build(raidProto, encounterProto, runFakePrepull)
+	return env, raidStats, encounterStats
+}
+
+// Runs all the phases needed to take a new environment to Finalized.
+func (env *Environment) build(raidProto *proto.Raid, encounterProto *proto.Encounter, runFakePrepull bool) (*proto.RaidStats, *proto.EncounterStats) {
 	env.construct(raidProto, encounterProto)
 	raidStats := env.initialize(raidProto, encounterProto)
 	env.finalize(raidProto, encounterProto, raidStats, runFakePrepull)
@@ -62,7 +68,7 @@ func NewEnvironment(raidProto *proto.Raid, encounterProto *proto.Encounter, runF
 		})
 	}
 
-	return env, raidStats, encounterStats
+	return raidStats, encounterStats
 }
 
 // The construction phase.
@@ -85,8 +91,9 @@ func (env *Environment) construct(raidProto *proto.Raid, encounterProto *proto.E
 		unit.CurrentTarget = env.Encounter.TargetUnits[0]
 	}
 
-	// Apply extra debuffs from raid.
-	if raidProto.Debuffs != nil && len(env.Encounter.TargetUnits) > 0 {
+	// Apply extra debuffs from raid. When buffs come from the composition,
+	// debuffs are applied by the players who cast them instead.
+	if raidProto.Debuffs != nil && !raidProto.BuffsFromComposition && len(env.Encounter.TargetUnits) > 0 {
 		for targetIdx, targetUnit := range env.Encounter.TargetUnits {
 			applyDebuffEffects(targetUnit, targetIdx, raidProto.Debuffs, raidProto)
 		}
@@ -247,6 +254,27 @@ func (env *Environment) reset(sim *Simulation) {
 	env.Raid.reset(sim)
 }
 
+// Clears the aggregate metrics of all units, so a finalized environment can be
+// reused for another sim instead of being rebuilt.
+func (env *Environment) clearMetrics() {
+	for _, unit := range env.AllUnits {
+		unit.Metrics.clear()
+		for _, aura := range unit.auras {
+			aura.metrics.clear()
+		}
+		if unit.Rotation != nil {
+			unit.Rotation.clearMetrics()
+		}
+	}
+
+	env.Raid.dpsMetrics.clear()
+	env.Raid.hpsMetrics.clear()
+	for _, party := range env.Raid.Parties {
+		party.dpsMetrics.clear()
+		party.hpsMetrics.clear()
+	}
+}
+
 // The maximum possible duration for any iteration.
 func (env *Environment) GetMaxDuration() time.Duration {
 	return env.BaseDuration + env.DurationVariation
@@ -330,6 +358,11 @@ func (env *Environment) GetUnit(ref *proto.UnitReference, contextUnit *Unit) *Un
 			return nil
 		}
 		return contextUnit.CurrentTarget
+	case proto.UnitReference_Candidate:
+		if contextUnit == nil {
+			return nil
+		}
+		return contextUnit.targetCandidate()
 	}
 
 	return nil
@@ -0,0 +1,23 @@
+package core
+
+// Exposes internals to the external core_test package.
+var RunSimWithEnv = runSimWithEnv
+var ExpectedBestUpgrade = expectedBestUpgrade
+
+// Returns how often the player's pets cast abilities in the current episode.
+func (rlEnv *RLEnvironment) PetAbilityCasts() int32 {
+	casts := int32(0)
+	for _, petAgent := range rlEnv.character.PetAgents {
+		for _, spell := range petAgent.GetPet().Spellbook {
+			if spell.ActionID.SpellID == 0 {
+				continue
+			}
+			for _, splitMetrics := range spell.splitSpellMetrics {
+				for _, metrics := range splitMetrics {
+					casts += metrics.Casts
+				}
+			}
+		}
+	}
+	return casts
+}
@@ -81,6 +81,30 @@ func (fb *focusBar) getTotalRegenMultiplier() float64 {
 	return fb.hasteRatingMultiplier * fb.focusRegenMultiplier
 }
 
+func (fb *focusBar) focusRegenTicks() regenTicks {
+	return regenTicks{
+		next:   fb.nextFocusTick,
+		period: fb.focusTickDuration,
+		amount: fb.FocusRegenPerTick(),
+	}
+}
+
+// Returns the time until regen brings focus to the given amount, or
+// NeverExpires if it never will.
+func (fb *focusBar) TimeUntilFocus(sim *Simulation, amount float64) time.Duration {
+	return forecastTimeUntil(sim, fb.currentFocus, fb.maxFocus, amount, fb.focusRegenTicks())
+}
+
+// Returns the focus at the given time, from regen alone.
+func (fb *focusBar) FocusAt(_ *Simulation, at time.Duration) float64 {
+	return forecastAt(fb.currentFocus, fb.maxFocus, at, fb.focusRegenTicks())
+}
+
+// Returns the focus missing from the focus cap.
+func (fb *focusBar) FocusDeficit() float64 {
+	return fb.maxFocus - fb.currentFocus
+}
+
 func (fb *focusBar) AddFocus(sim *Simulation, amount float64, metrics *ResourceMetrics) {
 	if amount < 0 {
 		panic("Trying to add negative focus!")
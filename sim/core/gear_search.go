@@ -0,0 +1,636 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strings"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/stats"
+)
+
+const defaultGearSearchCandidatesPerSlot = 3
+
+var difficultyLabels = map[proto.DungeonDifficulty]string{
+	proto.DungeonDifficulty_DifficultyNormal:         "Normal",
+	proto.DungeonDifficulty_DifficultyHeroic:         "Heroic",
+	proto.DungeonDifficulty_DifficultyTitanRuneAlpha: "Titan Rune Alpha",
+	proto.DungeonDifficulty_DifficultyTitanRuneBeta:  "Titan Rune Beta",
+	proto.DungeonDifficulty_DifficultyRaid10:         "10",
+	proto.DungeonDifficulty_DifficultyRaid10H:        "10H",
+	proto.DungeonDifficulty_DifficultyRaid25:         "25",
+	proto.DungeonDifficulty_DifficultyRaid25H:        "25H",
+	proto.DungeonDifficulty_DifficultyRaid25RF:       "LFR",
+}
+
+func RaceFaction(race proto.Race) proto.Faction {
+	switch race {
+	case proto.Race_RaceDraenei, proto.Race_RaceDwarf, proto.Race_RaceGnome, proto.Race_RaceHuman, proto.Race_RaceNightElf, proto.Race_RaceWorgen:
+		return proto.Faction_Alliance
+	case proto.Race_RaceBloodElf, proto.Race_RaceOrc, proto.Race_RaceTauren, proto.Race_RaceTroll, proto.Race_RaceUndead, proto.Race_RaceGoblin:
+		return proto.Faction_Horde
+	}
+	return proto.Faction_Unknown
+}
+
+// gearSearchCandidate is a database item that could replace the item
+// equipped in a slot.
+type gearSearchCandidate struct {
+	spec    *proto.ItemSpec
+	slot    proto.ItemSlot
+	epDelta float64
+	score   float64 // Used for pruning, EP delta or item level without weights.
+	sources []*proto.UIItemSource
+}
+
+// gearSearch finds usable items in the UI database and evaluates them against
+// the equipped gear of a single player.
+type gearSearch struct {
+	request  *proto.RaidSimRequest
+	settings *proto.GearSearchSettings
+	player   *proto.Player
+
+	equipment  Equipment
+	weights    stats.Stats
+	pseudo     []float64
+	hasWeights bool
+	faction    proto.Faction
+
+	// Item types the player can use. Weapon types map to whether two-handed
+	// versions are usable.
+	armorType         proto.ArmorType
+	weaponTypes       map[proto.WeaponType]bool
+	rangedWeaponTypes []proto.RangedWeaponType
+
+	zoneNames map[int32]string
+	npcs      map[int32]*proto.UINPC
+}
+
+func newGearSearch(request *proto.RaidSimRequest, settings *proto.GearSearchSettings) (*gearSearch, error) {
+	if !WITH_DB || len(uiItemDatabase.Items) == 0 {
+		return nil, errors.New("gear search requires the item database")
+	}
+
+	// Reduce to just the player and their party, like bulk sims.
+	request = goproto.Clone(request).(*proto.RaidSimRequest)
+	var player *proto.Player
+	var playerParty *proto.Party
+	playerCount := 0
+	for _, party := range request.GetRaid().GetParties() {
+		for _, pl := range party.GetPlayers() {
+			if pl != nil && pl.Class != proto.Class_ClassUnknown {
+				player = pl
+				playerParty = party
+				playerCount++
+			}
+		}
+	}
+	if playerCount != 1 {
+		return nil, fmt.Errorf("gear search: expected exactly 1 player, found %d", playerCount)
+	}
+	if player.GetDatabase() != nil {
+		addToDatabase(player.GetDatabase())
+	}
+	playerParty.Players = []*proto.Player{player}
+	request.Raid.Parties = []*proto.Party{playerParty}
+	player.Database = nil
+	if player.Equipment == nil {
+		player.Equipment = &proto.EquipmentSpec{}
+	}
+	for len(player.Equipment.Items) <= int(proto.ItemSlot_ItemSlotRanged) {
+		player.Equipment.Items = append(player.Equipment.Items, &proto.ItemSpec{})
+	}
+
+	gs := &gearSearch{
+		request:   request,
+		settings:  settings,
+		player:    player,
+		equipment: ProtoToEquipment(player.Equipment),
+		faction:   settings.Faction,
+		zoneNames: make(map[int32]string, len(uiItemDatabase.Zones)),
+		npcs:      make(map[int32]*proto.UINPC, len(uiItemDatabase.Npcs)),
+	}
+	if gs.faction == proto.Faction_Unknown {
+		gs.faction = RaceFaction(player.Race)
+	}
+	gs.setItemTypes()
+
+	gs.pseudo = make([]float64, stats.PseudoStatsLen)
+	if weights := settings.GetStatWeights(); weights != nil {
+		gs.weights = stats.FromFloatArray(weights.Stats)
+		copy(gs.pseudo, weights.PseudoStats)
+	}
+	for _, w := range gs.weights {
+		gs.hasWeights = gs.hasWeights || w != 0
+	}
+	for _, w := range gs.pseudo {
+		gs.hasWeights = gs.hasWeights || w != 0
+	}
+
+	for _, zone := range uiItemDatabase.Zones {
+		gs.zoneNames[zone.Id] = zone.Name
+	}
+	for _, npc := range uiItemDatabase.Npcs {
+		gs.npcs[npc.Id] = npc
+	}
+
+	return gs, nil
+}
+
+// setItemTypes reads the item types the player can use from the settings,
+// falling back to the types of the equipped items.
+func (gs *gearSearch) setItemTypes() {
+	settings := gs.settings
+	if len(settings.ArmorTypes) > 0 {
+		gs.armorType = settings.ArmorTypes[0]
+	}
+	gs.weaponTypes = make(map[proto.WeaponType]bool)
+	for _, weaponType := range settings.WeaponTypes {
+		gs.weaponTypes[weaponType.WeaponType] = weaponType.CanUseTwoHand
+	}
+	gs.rangedWeaponTypes = settings.RangedWeaponTypes
+
+	for _, item := range gs.equipment {
+		switch item.Type {
+		case proto.ItemType_ItemTypeUnknown, proto.ItemType_ItemTypeNeck, proto.ItemType_ItemTypeFinger, proto.ItemType_ItemTypeTrinket, proto.ItemType_ItemTypeBack:
+		case proto.ItemType_ItemTypeWeapon:
+			if len(settings.WeaponTypes) == 0 {
+				gs.weaponTypes[item.WeaponType] = gs.weaponTypes[item.WeaponType] || item.HandType == proto.HandType_HandTypeTwoHand
+			}
+		case proto.ItemType_ItemTypeRanged:
+			if len(settings.RangedWeaponTypes) == 0 {
+				gs.rangedWeaponTypes = append(gs.rangedWeaponTypes, item.RangedWeaponType)
+			}
+		default:
+			if gs.armorType == proto.ArmorType_ArmorTypeUnknown {
+				gs.armorType = item.ArmorType
+			}
+		}
+	}
+}
+
+// matchingSources returns the sources of the item which are selected by the
+// search settings.
+func (gs *gearSearch) matchingSources(uiItem *proto.UIItem) []*proto.UIItemSource {
+	settings := gs.settings
+	return FilterSlice(uiItem.Sources, func(source *proto.UIItemSource) bool {
+		switch src := source.Source.(type) {
+		case *proto.UIItemSource_Drop:
+			drop := src.Drop
+			if len(settings.Difficulties) > 0 && !slices.Contains(settings.Difficulties, drop.Difficulty) {
+				return false
+			}
+			zoneID := drop.ZoneId
+			if npc, ok := gs.npcs[drop.NpcId]; ok && zoneID == 0 {
+				zoneID = npc.ZoneId
+			}
+			return slices.Contains(settings.ZoneIds, zoneID) || (drop.NpcId != 0 && slices.Contains(settings.NpcIds, drop.NpcId))
+		case *proto.UIItemSource_Crafted:
+			return settings.IncludeCrafted
+		case *proto.UIItemSource_Rep:
+			return settings.IncludeReputation && (src.Rep.FactionId == proto.Faction_Unknown || src.Rep.FactionId == gs.faction)
+		case *proto.UIItemSource_SoldBy:
+			return settings.IncludeVendors
+		case *proto.UIItemSource_Quest:
+			return settings.IncludeQuests
+		}
+		return false
+	})
+}
+
+func (gs *gearSearch) isUsable(uiItem *proto.UIItem) bool {
+	if gs.settings.MaxPhase > 0 && uiItem.Phase > gs.settings.MaxPhase {
+		return false
+	}
+	switch uiItem.FactionRestriction {
+	case proto.UIItem_FACTION_RESTRICTION_ALLIANCE_ONLY:
+		if gs.faction == proto.Faction_Horde {
+			return false
+		}
+	case proto.UIItem_FACTION_RESTRICTION_HORDE_ONLY:
+		if gs.faction == proto.Faction_Alliance {
+			return false
+		}
+	}
+	if len(uiItem.ClassAllowlist) > 0 && !slices.Contains(uiItem.ClassAllowlist, gs.player.Class) {
+		return false
+	}
+	if uiItem.RequiredProfession != proto.Profession_ProfessionUnknown &&
+		uiItem.RequiredProfession != gs.player.Profession1 && uiItem.RequiredProfession != gs.player.Profession2 {
+		return false
+	}
+	return true
+}
+
+// canEquipInSlot checks whether the player can use the item in the slot. Weapons
+// must keep the equipped weapon setup, e.g. a two-hander only replaces a
+// two-hander and a shield only replaces a shield.
+func (gs *gearSearch) canEquipInSlot(item Item, slot proto.ItemSlot) bool {
+	equipped := gs.equipment[slot]
+
+	switch item.Type {
+	case proto.ItemType_ItemTypeNeck, proto.ItemType_ItemTypeFinger, proto.ItemType_ItemTypeTrinket, proto.ItemType_ItemTypeBack:
+		return true
+	case proto.ItemType_ItemTypeRanged:
+		return slices.Contains(gs.rangedWeaponTypes, item.RangedWeaponType)
+	case proto.ItemType_ItemTypeWeapon:
+		canUseTwoHand, ok := gs.weaponTypes[item.WeaponType]
+		if !ok || (item.HandType == proto.HandType_HandTypeTwoHand && !canUseTwoHand) {
+			return false
+		}
+
+		isTwoHand := item.HandType == proto.HandType_HandTypeTwoHand
+		if slot == proto.ItemSlot_ItemSlotMainHand {
+			return equipped.ID == 0 || isTwoHand == (equipped.HandType == proto.HandType_HandTypeTwoHand)
+		}
+
+		if equipped.ID == 0 || isTwoHand != (equipped.HandType == proto.HandType_HandTypeTwoHand) {
+			return false
+		}
+		isHeldInOffHand := func(i Item) bool {
+			return i.WeaponType == proto.WeaponType_WeaponTypeShield || i.WeaponType == proto.WeaponType_WeaponTypeOffHand
+		}
+		if isHeldInOffHand(equipped) || isHeldInOffHand(item) {
+			return item.WeaponType == equipped.WeaponType
+		}
+		return true
+	default:
+		return item.ArmorType == gs.armorType
+	}
+}
+
+func (gs *gearSearch) itemEP(item Item, slot proto.ItemSlot) float64 {
+	if item.ID == 0 {
+		return 0
+	}
+
+	ep := 0.0
+	itemStats := ItemEquipmentStats(item)
+	for i, w := range gs.weights {
+		ep += itemStats[i] * w
+	}
+
+	if item.SwingSpeed > 0 {
+		dps := (item.WeaponDamageMin + item.WeaponDamageMax) / 2 / item.SwingSpeed
+		switch slot {
+		case proto.ItemSlot_ItemSlotMainHand:
+			ep += dps * gs.pseudo[proto.PseudoStat_PseudoStatMainHandDps]
+		case proto.ItemSlot_ItemSlotOffHand:
+			ep += dps * gs.pseudo[proto.PseudoStat_PseudoStatOffHandDps]
+		case proto.ItemSlot_ItemSlotRanged:
+			ep += dps * gs.pseudo[proto.PseudoStat_PseudoStatRangedDps]
+		}
+	}
+	return ep
+}
+
+// makeCandidate builds the item spec for a database item in the given slot,
+// with the best random suffix, default gems and the equipped enchant.
+func (gs *gearSearch) makeCandidate(uiItem *proto.UIItem, slot proto.ItemSlot, sources []*proto.UIItemSource) *gearSearchCandidate {
+	equippedSpec := gs.player.Equipment.Items[slot]
+	equippedEP := gs.itemEP(gs.equipment[slot], slot)
+
+	suffixes := uiItem.RandomSuffixOptions
+	if len(suffixes) == 0 {
+		suffixes = []int32{0}
+	}
+
+	var best *gearSearchCandidate
+	for _, suffix := range suffixes {
+		if _, ok := RandomSuffixesByID[suffix]; suffix != 0 && !ok {
+			continue
+		}
+
+		spec := &proto.ItemSpec{
+			Id:           uiItem.Id,
+			RandomSuffix: suffix,
+			Enchant:      equippedSpec.Enchant,
+		}
+		autoGemItem(spec, gs.settings.DefaultRedGem, gs.settings.DefaultYellowGem, gs.settings.DefaultBlueGem, gs.settings.DefaultMetaGem)
+
+		item := NewItem(ItemSpec{ID: spec.Id, RandomSuffix: spec.RandomSuffix, Enchant: spec.Enchant, Gems: spec.Gems})
+		epDelta := gs.itemEP(item, slot) - equippedEP
+		if best == nil || epDelta > best.epDelta {
+			best = &gearSearchCandidate{
+				spec:    spec,
+				slot:    slot,
+				epDelta: epDelta,
+				sources: sources,
+			}
+		}
+	}
+
+	if best != nil {
+		if gs.hasWeights {
+			best.score = best.epDelta
+		} else {
+			best.score = float64(uiItem.Ilvl)
+		}
+	}
+	return best
+}
+
+// findCandidates returns the usable items from the selected sources, grouped by
+// slot and ordered by score.
+func (gs *gearSearch) findCandidates(includeItem func(*proto.UIItem, []*proto.UIItemSource) bool) (map[proto.ItemSlot][]*gearSearchCandidate, int32) {
+	candidatesBySlot := make(map[proto.ItemSlot][]*gearSearchCandidate)
+	numFound := int32(0)
+
+	for _, uiItem := range uiItemDatabase.Items {
+		item, ok := ItemsByID[uiItem.Id]
+		if !ok || !gs.isUsable(uiItem) {
+			continue
+		}
+		sources := gs.matchingSources(uiItem)
+		if len(sources) == 0 || (includeItem != nil && !includeItem(uiItem, sources)) {
+			continue
+		}
+
+		found := false
+		for _, slot := range eligibleSlotsForItem(item) {
+			if gs.equipment[slot].ID == uiItem.Id || !gs.canEquipInSlot(item, slot) {
+				continue
+			}
+			candidate := gs.makeCandidate(uiItem, slot, sources)
+			if candidate == nil || (gs.hasWeights && candidate.epDelta <= 0) {
+				continue
+			}
+			candidatesBySlot[slot] = append(candidatesBySlot[slot], candidate)
+			found = true
+		}
+		if found {
+			numFound++
+		}
+	}
+
+	for _, candidates := range candidatesBySlot {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].score > candidates[j].score
+		})
+	}
+	return candidatesBySlot, numFound
+}
+
+func (gs *gearSearch) describeSource(source *proto.UIItemSource) string {
+	switch src := source.Source.(type) {
+	case *proto.UIItemSource_Drop:
+		drop := src.Drop
+		zoneID := drop.ZoneId
+		name := drop.OtherName
+		if npc, ok := gs.npcs[drop.NpcId]; ok {
+			name = npc.Name
+			if zoneID == 0 {
+				zoneID = npc.ZoneId
+			}
+		}
+
+		details := make([]string, 0, 3)
+		if zoneName, ok := gs.zoneNames[zoneID]; ok {
+			details = append(details, zoneName)
+		}
+		if label, ok := difficultyLabels[drop.Difficulty]; ok {
+			details = append(details, label)
+		}
+		if drop.Category != "" {
+			details = append(details, drop.Category)
+		}
+
+		if name == "" {
+			name = "Drop"
+		}
+		if len(details) > 0 {
+			name += " (" + strings.Join(details, ", ") + ")"
+		}
+		return name
+	case *proto.UIItemSource_Crafted:
+		return "Crafted (" + strings.TrimPrefix(src.Crafted.Profession.String(), "Profession") + ")"
+	case *proto.UIItemSource_Rep:
+		return fmt.Sprintf("%s - %s", strings.TrimPrefix(src.Rep.RepFactionId.String(), "RepFaction"), strings.TrimPrefix(src.Rep.RepLevel.String(), "RepLevel"))
+	case *proto.UIItemSource_SoldBy:
+		if zoneName, ok := gs.zoneNames[src.SoldBy.ZoneId]; ok {
+			return fmt.Sprintf("Sold by %s (%s)", src.SoldBy.NpcName, zoneName)
+		}
+		return "Sold by " + src.SoldBy.NpcName
+	case *proto.UIItemSource_Quest:
+		return "Quest: " + src.Quest.Name
+	}
+	return ""
+}
+
+func (gs *gearSearch) candidateToProto(candidate *gearSearchCandidate) *proto.GearSearchItem {
+	return &proto.GearSearchItem{
+		Item: &proto.ItemSpecWithSlot{
+			Item: candidate.spec,
+			Slot: candidate.slot,
+		},
+		Sources:            candidate.sources,
+		SourceDescriptions: MapSlice(candidate.sources, gs.describeSource),
+		EpDelta:            candidate.epDelta,
+	}
+}
+
+func (gs *gearSearch) iterations() int64 {
+	if iterations := gs.settings.IterationsPerCombo; iterations > 0 {
+		return int64(iterations)
+	}
+	return defaultIterationsPerCombo
+}
+
+// simCombos sims each combination of candidates, plus the equipped gear if
+// includeBase is set. Results are in the order of the combos.
+func (gs *gearSearch) simCombos(ctx context.Context, combos [][]*gearSearchCandidate, includeBase bool, progress chan *proto.ProgressMetrics) ([]*itemSubstitutionSimResult, *itemSubstitutionSimResult, error) {
+	runner := &bulkSimRunner{
+		SingleRaidSimRunner: runSimWithEnv,
+		Request: &proto.BulkSimRequest{
+			BaseSettings: gs.request,
+			BulkSettings: &proto.BulkSettings{},
+		},
+	}
+
+	subs := make([]*equipmentSubstitution, 0, len(combos)+1)
+	if includeBase {
+		subs = append(subs, &equipmentSubstitution{})
+	}
+	subToCombo := make(map[*equipmentSubstitution]int, len(combos))
+	var bulkSims []singleBulkSim
+	for _, sub := range subs {
+		req, cl := createNewRequestWithSubstitution(gs.request, sub, false)
+		bulkSims = append(bulkSims, singleBulkSim{req: req, cl: cl, eq: sub})
+	}
+	for i, combo := range combos {
+		sub := &equipmentSubstitution{
+			Items: MapSlice(combo, func(candidate *gearSearchCandidate) *itemWithSlot {
+				return &itemWithSlot{Item: candidate.spec, Slot: candidate.slot}
+			}),
+		}
+		req, cl := createNewRequestWithSubstitution(gs.request, sub, false)
+		if !isValidEquipment(req.Raid.Parties[0].Players[0].Equipment) {
+			continue
+		}
+		subToCombo[sub] = i
+		bulkSims = append(bulkSims, singleBulkSim{req: req, cl: cl, eq: sub})
+	}
+
+	rankedResults, baseResult, err := runner.getRankedResults(ctx, bulkSims, gs.iterations(), progress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]*itemSubstitutionSimResult, len(combos))
+	for _, result := range rankedResults {
+		if i, ok := subToCombo[result.Substitution]; ok {
+			results[i] = result
+		}
+	}
+	return results, baseResult, nil
+}
+
+// gearSearchUpgrade is a candidate which gained dps in a sim.
+type gearSearchUpgrade struct {
+	candidate *gearSearchCandidate
+	upgrade   *proto.GearSearchUpgrade
+}
+
+// simUpgrades sims each candidate on its own and returns those which gain dps,
+// best first, along with the dps of the equipped gear. Items which fit in
+// multiple slots are only kept in their best slot.
+func (gs *gearSearch) simUpgrades(ctx context.Context, candidates []*gearSearchCandidate, progress chan *proto.ProgressMetrics) ([]gearSearchUpgrade, float64, error) {
+	combos := MapSlice(candidates, func(candidate *gearSearchCandidate) []*gearSearchCandidate {
+		return []*gearSearchCandidate{candidate}
+	})
+	simResults, baseResult, err := gs.simCombos(ctx, combos, true, progress)
+	if err != nil {
+		return nil, 0, err
+	}
+	if baseResult == nil {
+		return nil, 0, errors.New("gear search: no result for equipped gear")
+	}
+	baseDps := baseResult.Score()
+
+	bestByItem := make(map[int32]gearSearchUpgrade)
+	for i, simResult := range simResults {
+		if simResult == nil || simResult.Score() <= baseDps {
+			continue
+		}
+		candidate := candidates[i]
+		if prev, ok := bestByItem[candidate.spec.Id]; ok && prev.upgrade.Dps >= simResult.Score() {
+			continue
+		}
+		bestByItem[candidate.spec.Id] = gearSearchUpgrade{
+			candidate: candidate,
+			upgrade: &proto.GearSearchUpgrade{
+				Items:    []*proto.GearSearchItem{gs.candidateToProto(candidate)},
+				Dps:      simResult.Score(),
+				DpsDelta: simResult.Score() - baseDps,
+			},
+		}
+	}
+
+	upgrades := make([]gearSearchUpgrade, 0, len(bestByItem))
+	for _, upgrade := range bestByItem {
+		upgrades = append(upgrades, upgrade)
+	}
+	sort.Slice(upgrades, func(i, j int) bool {
+		if upgrades[i].upgrade.DpsDelta != upgrades[j].upgrade.DpsDelta {
+			return upgrades[i].upgrade.DpsDelta > upgrades[j].upgrade.DpsDelta
+		}
+		return upgrades[i].candidate.spec.Id < upgrades[j].candidate.spec.Id
+	})
+	return upgrades, baseDps, nil
+}
+
+func GearSearch(ctx context.Context, request *proto.GearSearchRequest, progress chan *proto.ProgressMetrics) (result *proto.GearSearchResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			result = &proto.GearSearchResult{
+				ErrorResult: fmt.Sprintf("%v\nStack Trace:\n%s", err, string(debug.Stack())),
+			}
+		}
+	}()
+
+	result, err := runGearSearch(ctx, request, progress)
+	if err != nil {
+		result = &proto.GearSearchResult{
+			ErrorResult: err.Error(),
+		}
+	}
+	return result
+}
+
+func runGearSearch(ctx context.Context, request *proto.GearSearchRequest, progress chan *proto.ProgressMetrics) (*proto.GearSearchResult, error) {
+	settings := request.GetSearchSettings()
+	if settings == nil {
+		return nil, errors.New("gear search: missing search settings")
+	}
+	if len(settings.ZoneIds) == 0 && len(settings.NpcIds) == 0 && !settings.IncludeCrafted && !settings.IncludeReputation && !settings.IncludeVendors && !settings.IncludeQuests {
+		return nil, errors.New("gear search: no item sources selected")
+	}
+
+	gs, err := newGearSearch(request.GetBaseSettings(), settings)
+	if err != nil {
+		return nil, err
+	}
+
+	candidatesBySlot, numFound := gs.findCandidates(nil)
+	result := &proto.GearSearchResult{
+		CandidatesFound: numFound,
+	}
+
+	// Prune each slot to the best candidates by stat weights, then confirm with sims.
+	perSlot := int(settings.CandidatesPerSlot)
+	if perSlot <= 0 {
+		perSlot = defaultGearSearchCandidatesPerSlot
+	}
+	var candidates []*gearSearchCandidate
+	for slot := proto.ItemSlot_ItemSlotHead; slot <= proto.ItemSlot_ItemSlotRanged; slot++ {
+		slotCandidates := candidatesBySlot[slot]
+		candidates = append(candidates, slotCandidates[:min(perSlot, len(slotCandidates))]...)
+	}
+
+	upgrades, baseDps, err := gs.simUpgrades(ctx, candidates, progress)
+	if err != nil {
+		return nil, err
+	}
+	result.EquippedGearDps = baseDps
+	result.Upgrades = MapSlice(upgrades, func(upgrade gearSearchUpgrade) *proto.GearSearchUpgrade {
+		return upgrade.upgrade
+	})
+
+	// Greedily combine the best upgrades of distinct slots within the budget.
+	maxChanges := max(1, int(settings.MaxChanges))
+	var bestSet []*gearSearchCandidate
+	for _, upgrade := range upgrades {
+		if len(bestSet) >= maxChanges {
+			break
+		}
+		candidate := upgrade.candidate
+		if slices.ContainsFunc(bestSet, func(c *gearSearchCandidate) bool { return c.slot == candidate.slot }) {
+			continue
+		}
+		bestSet = append(bestSet, candidate)
+	}
+
+	if len(bestSet) == 1 {
+		result.BestSet = result.Upgrades[0]
+	} else if len(bestSet) > 1 {
+		setResults, _, err := gs.simCombos(ctx, [][]*gearSearchCandidate{bestSet}, false, progress)
+		if err != nil {
+			return nil, err
+		}
+		if setResults[0] != nil {
+			result.BestSet = &proto.GearSearchUpgrade{
+				Items:    MapSlice(bestSet, gs.candidateToProto),
+				Dps:      setResults[0].Score(),
+				DpsDelta: setResults[0].Score() - baseDps,
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package core_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/stats"
+)
+
+func TestGearSearch(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerFeralCat())
+	rsr.Encounter.Duration = 60
+	const blackwingDescent = 5094
+
+	result := core.RunGearSearch(&proto.GearSearchRequest{
+		BaseSettings: rsr,
+		SearchSettings: &proto.GearSearchSettings{
+			ZoneIds:  []int32{blackwingDescent},
+			MaxPhase: 1,
+			StatWeights: &proto.UnitStats{
+				Stats: stats.Stats{
+					stats.Agility:     3,
+					stats.Strength:    1,
+					stats.AttackPower: 1,
+					stats.MeleeHit:    1,
+					stats.MeleeCrit:   1,
+					stats.MeleeHaste:  1,
+					stats.Expertise:   1,
+					stats.Mastery:     1,
+				}.ToFloatArray(),
+			},
+			CandidatesPerSlot:  1,
+			MaxChanges:         2,
+			IterationsPerCombo: 20,
+		},
+	})
+	if result.ErrorResult != "" {
+		t.Fatal(result.ErrorResult)
+	}
+	if result.CandidatesFound == 0 || len(result.Upgrades) == 0 {
+		t.Fatalf("expected upgrades from Blackwing Descent over pre-raid gear, found %d candidates", result.CandidatesFound)
+	}
+
+	for i, upgrade := range result.Upgrades {
+		if i > 0 && upgrade.DpsDelta > result.Upgrades[i-1].DpsDelta {
+			t.Fatalf("upgrades are not ranked by dps")
+		}
+		if upgrade.DpsDelta <= 0 || upgrade.Dps != result.EquippedGearDps+upgrade.DpsDelta {
+			t.Fatalf("unexpected upgrade dps %f (+%f) over %f", upgrade.Dps, upgrade.DpsDelta, result.EquippedGearDps)
+		}
+
+		item := upgrade.Items[0]
+		if len(item.SourceDescriptions) != len(item.Sources) || item.EpDelta <= 0 {
+			t.Fatalf("upgrade %d is missing its sources or ep", item.Item.Item.Id)
+		}
+		if !slices.ContainsFunc(item.Sources, func(source *proto.UIItemSource) bool {
+			return source.GetDrop() != nil
+		}) {
+			t.Fatalf("upgrade %d does not drop in the selected zone", item.Item.Item.Id)
+		}
+	}
+
+	if result.BestSet == nil || len(result.BestSet.Items) == 0 || len(result.BestSet.Items) > 2 {
+		t.Fatalf("expected a best set of at most 2 items, got %v", result.BestSet)
+	}
+}
+
+func TestGearSearchNoSources(t *testing.T) {
+	result := core.RunGearSearch(&proto.GearSearchRequest{
+		BaseSettings:   makeTestCase(getTestPlayerFeralCat()),
+		SearchSettings: &proto.GearSearchSettings{},
+	})
+	if result.ErrorResult == "" {
+		t.Fatalf("expected an error without any item sources")
+	}
+}
+
+func TestGearSearchItemTypes(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerFeralCat())
+	rsr.Encounter.Duration = 60
+	const blackwingDescent = 5094
+
+	result := core.RunGearSearch(&proto.GearSearchRequest{
+		BaseSettings: rsr,
+		SearchSettings: &proto.GearSearchSettings{
+			ZoneIds:            []int32{blackwingDescent},
+			MaxPhase:           1,
+			IterationsPerCombo: 20,
+			ArmorTypes:         []proto.ArmorType{proto.ArmorType_ArmorTypeCloth},
+			WeaponTypes: []*proto.GearSearchWeaponType{
+				{WeaponType: proto.WeaponType_WeaponTypeStaff, CanUseTwoHand: true},
+			},
+			RangedWeaponTypes: []proto.RangedWeaponType{proto.RangedWeaponType_RangedWeaponTypeRelic},
+		},
+	})
+	if result.ErrorResult != "" {
+		t.Fatal(result.ErrorResult)
+	}
+	if result.CandidatesFound == 0 {
+		t.Fatalf("expected candidates from Blackwing Descent")
+	}
+
+	for _, upgrade := range result.Upgrades {
+		item := core.ItemsByID[upgrade.Items[0].Item.Item.Id]
+		switch item.Type {
+		case proto.ItemType_ItemTypeWeapon:
+			if item.WeaponType != proto.WeaponType_WeaponTypeStaff {
+				t.Fatalf("upgrade %d is not a staff", item.ID)
+			}
+		case proto.ItemType_ItemTypeRanged:
+			if item.RangedWeaponType != proto.RangedWeaponType_RangedWeaponTypeRelic {
+				t.Fatalf("upgrade %d is not a relic", item.ID)
+			}
+		case proto.ItemType_ItemTypeNeck, proto.ItemType_ItemTypeFinger, proto.ItemType_ItemTypeTrinket, proto.ItemType_ItemTypeBack:
+		default:
+			if item.ArmorType != proto.ArmorType_ArmorTypeCloth {
+				t.Fatalf("upgrade %d is not cloth", item.ID)
+			}
+		}
+	}
+}
@@ -87,6 +87,17 @@ func (hb *healthBar) RemoveHealth(sim *Simulation, amount float64) {
 	hb.currentHealth = newHealth
 }
 
+// Sets health to a fraction of maximum health without counting it as healing,
+// e.g. when a pet is summoned or revived.
+func (hb *healthBar) SetHealthPercent(sim *Simulation, percent float64) {
+	oldHealth := hb.currentHealth
+	hb.currentHealth = hb.MaxHealth() * percent
+
+	if sim.Log != nil {
+		hb.unit.Log(sim, "Health set to %0.0f%% (%0.3f --> %0.3f) of %0.0f total.", percent*100, oldHealth, hb.currentHealth, hb.MaxHealth())
+	}
+}
+
 // Used for dynamic updates to maximum health from "Last Stand" effects
 func (hb *healthBar) UpdateMaxHealth(sim *Simulation, bonusHealth float64, metrics *ResourceMetrics) {
 	hb.unit.AddStatsDynamic(sim, stats.Stats{stats.Health: bonusHealth})
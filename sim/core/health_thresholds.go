@@ -0,0 +1,202 @@
+package core
+
+import (
+	"math"
+	"time"
+)
+
+// Callback invoked when a target's health drops below a registered threshold.
+type HealthThresholdCallback func(sim *Simulation)
+
+type healthThreshold struct {
+	target   *Target // Target whose health is tracked, or nil to track the encounter as a whole.
+	percent  float64 // Health threshold, as a value from 0-1.
+	callback HealthThresholdCallback
+
+	triggerTime   time.Duration // Used in duration-based fights.
+	triggerDamage float64       // Used in health-based fights.
+	triggered     bool
+}
+
+func (ht *healthThreshold) isReached(sim *Simulation) bool {
+	if sim.CurrentTime >= ht.triggerTime {
+		return true
+	}
+	if ht.target != nil {
+		return ht.target.DamageTaken >= ht.triggerDamage
+	}
+	return sim.Encounter.DamageTaken >= ht.triggerDamage
+}
+
+// Point on the piecewise-linear health curve used by duration-based fights.
+// Elapsed is the fraction of the fight that has passed, from 0-1.
+type healthTimelinePoint struct {
+	elapsed float64
+	health  float64
+}
+
+// Builds the health curve for duration-based fights from the encounter's
+// execute proportions. Phases can never be reached before the preceding one,
+// which matches how the execute proportions have always been applied.
+func newHealthTimeline(encounter *Encounter) []healthTimelinePoint {
+	timeline := []healthTimelinePoint{{elapsed: 0, health: 1}}
+	addPoint := func(health float64, proportion float64) {
+		elapsed := max(min(1-proportion, 1), timeline[len(timeline)-1].elapsed)
+		timeline = append(timeline, healthTimelinePoint{elapsed: elapsed, health: health})
+	}
+	addPoint(0.90, encounter.ExecuteProportion_90)
+	addPoint(0.35, encounter.ExecuteProportion_35)
+	addPoint(0.25, encounter.ExecuteProportion_25)
+	addPoint(0.20, encounter.ExecuteProportion_20)
+	addPoint(0, 0)
+	return timeline
+}
+
+// Returns the fraction of the fight at which health reaches the given percent.
+func (encounter *Encounter) elapsedAtHealthPercent(percent float64) float64 {
+	timeline := encounter.healthTimeline
+	if percent >= timeline[0].health {
+		return timeline[0].elapsed
+	}
+	for i := 1; i < len(timeline); i++ {
+		prev, cur := timeline[i-1], timeline[i]
+		if percent == cur.health {
+			return cur.elapsed
+		} else if percent > cur.health {
+			return prev.elapsed + (cur.elapsed-prev.elapsed)*(prev.health-percent)/(prev.health-cur.health)
+		}
+	}
+	return 1
+}
+
+// Returns the health percent at the given fraction of the fight.
+func (encounter *Encounter) healthPercentAtElapsed(elapsed float64) float64 {
+	timeline := encounter.healthTimeline
+	for i := 1; i < len(timeline); i++ {
+		prev, cur := timeline[i-1], timeline[i]
+		if elapsed < cur.elapsed {
+			return prev.health - (prev.health-cur.health)*(elapsed-prev.elapsed)/(cur.elapsed-prev.elapsed)
+		}
+	}
+	return 0
+}
+
+// Returns the current health of an enemy target as a value from 0-1. In
+// duration-based fights health follows the execute proportions over time, in
+// health-based fights it is derived from the damage the target has taken.
+// A nil target returns the health of the encounter as a whole.
+func (sim *Simulation) GetTargetHealthPercent(target *Unit) float64 {
+	if sim.Encounter.EndFightAtHealth > 0 {
+		if target != nil {
+			if t := sim.Encounter.Targets[target.Index]; t.healthPool() > 0 {
+				return max(1-t.DamageTaken/t.healthPool(), 0)
+			}
+		}
+		return max(1-sim.Encounter.DamageTaken/sim.Encounter.EndFightAtHealth, 0)
+	}
+	if sim.CurrentTime <= 0 {
+		return 1
+	}
+	return sim.Encounter.healthPercentAtElapsed(float64(sim.CurrentTime) / float64(sim.Duration))
+}
+
+func (encounter *Encounter) addDamageTaken(sim *Simulation, unit *Unit, damage float64) {
+	target := encounter.Targets[unit.Index]
+	encounter.DamageTaken += damage
+	target.DamageTaken += damage
+
+	if encounter.DamageTaken >= encounter.nextHealthThresholdDamage || target.DamageTaken >= target.nextHealthThresholdDamage {
+		sim.healthThresholdReached = true
+	}
+}
+
+// Registers a callback which is invoked once, when the health of target drops
+// to or below percent (a value from 0-1). A nil target tracks the health of
+// the encounter as a whole. Callbacks are cleared at the start of each
+// iteration, so this should be called from reset handlers.
+func (sim *Simulation) RegisterHealthThresholdCallback(target *Unit, percent float64, callback HealthThresholdCallback) {
+	ht := &healthThreshold{
+		percent:       percent,
+		callback:      callback,
+		triggerTime:   NeverExpires,
+		triggerDamage: math.MaxFloat64,
+	}
+	if target != nil {
+		ht.target = sim.Encounter.Targets[target.Index]
+	}
+
+	if sim.Encounter.EndFightAtHealth > 0 {
+		if ht.target != nil && ht.target.healthPool() > 0 {
+			ht.triggerDamage = (1 - percent) * ht.target.healthPool()
+			ht.target.nextHealthThresholdDamage = min(ht.target.nextHealthThresholdDamage, ht.triggerDamage)
+		} else {
+			ht.target = nil
+			ht.triggerDamage = (1 - percent) * sim.Encounter.EndFightAtHealth
+			sim.Encounter.nextHealthThresholdDamage = min(sim.Encounter.nextHealthThresholdDamage, ht.triggerDamage)
+		}
+	} else {
+		ht.triggerTime = time.Duration(sim.Encounter.elapsedAtHealthPercent(percent) * float64(sim.Duration))
+		sim.nextHealthThresholdTime = min(sim.nextHealthThresholdTime, ht.triggerTime)
+	}
+
+	sim.healthThresholds = append(sim.healthThresholds, ht)
+}
+
+func (sim *Simulation) resetHealthThresholds() {
+	sim.healthThresholds = sim.healthThresholds[:0]
+	sim.healthThresholdReached = false
+	sim.nextHealthThresholdTime = NeverExpires
+	sim.Encounter.nextHealthThresholdDamage = math.MaxFloat64
+	for _, target := range sim.Encounter.Targets {
+		target.DamageTaken = 0
+		target.nextHealthThresholdDamage = math.MaxFloat64
+	}
+
+	sim.executePhase = 100
+	for _, phase := range []int32{90, 35, 25, 20} {
+		phase := phase
+		sim.RegisterHealthThresholdCallback(nil, float64(phase)/100, func(sim *Simulation) {
+			sim.executePhase = phase
+			for _, callback := range sim.executePhaseCallbacks {
+				callback(sim, phase)
+			}
+		})
+	}
+}
+
+// Fires the callbacks of all thresholds which have been crossed, from highest
+// to lowest health, and recomputes when the next threshold will be reached.
+func (sim *Simulation) processHealthThresholds() {
+	sim.healthThresholdReached = false
+
+	for {
+		var next *healthThreshold
+		for _, ht := range sim.healthThresholds {
+			if !ht.triggered && (next == nil || ht.percent > next.percent) && ht.isReached(sim) {
+				next = ht
+			}
+		}
+		if next == nil {
+			break
+		}
+		next.triggered = true
+		next.callback(sim)
+	}
+
+	sim.nextHealthThresholdTime = NeverExpires
+	sim.Encounter.nextHealthThresholdDamage = math.MaxFloat64
+	for _, target := range sim.Encounter.Targets {
+		target.nextHealthThresholdDamage = math.MaxFloat64
+	}
+	for _, ht := range sim.healthThresholds {
+		if ht.triggered {
+			continue
+		}
+		sim.nextHealthThresholdTime = min(sim.nextHealthThresholdTime, ht.triggerTime)
+		if ht.target != nil {
+			ht.target.nextHealthThresholdDamage = min(ht.target.nextHealthThresholdDamage, ht.triggerDamage)
+		} else {
+			sim.Encounter.nextHealthThresholdDamage = min(sim.Encounter.nextHealthThresholdDamage, ht.triggerDamage)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHealthTimelineMatchesExecuteProportions(t *testing.T) {
+	encounter := &Encounter{
+		ExecuteProportion_20: 0.2,
+		ExecuteProportion_25: 0.25,
+		ExecuteProportion_35: 0.35,
+		ExecuteProportion_90: 0.9,
+	}
+	encounter.healthTimeline = newHealthTimeline(encounter)
+
+	for _, percent := range []float64{0.9, 0.35, 0.25, 0.2} {
+		if elapsed := encounter.elapsedAtHealthPercent(percent); math.Abs(elapsed-(1-percent)) > 1e-9 {
+			t.Fatalf("Expected %0.2f health at %0.4f elapsed, got %0.4f", percent, 1-percent, elapsed)
+		}
+	}
+
+	if health := encounter.healthPercentAtElapsed(0.5); math.Abs(health-0.5) > 1e-9 {
+		t.Fatalf("Expected 0.5 health halfway through the fight, got %0.4f", health)
+	}
+}
+
+func TestHealthTimelineOrdersPhases(t *testing.T) {
+	// 35% is configured to occur before 90%, so it must wait for 90% to be reached.
+	encounter := &Encounter{
+		ExecuteProportion_20: 0.1,
+		ExecuteProportion_25: 0.1,
+		ExecuteProportion_35: 0.8,
+		ExecuteProportion_90: 0.5,
+	}
+	encounter.healthTimeline = newHealthTimeline(encounter)
+
+	if elapsed := encounter.elapsedAtHealthPercent(0.35); math.Abs(elapsed-0.5) > 1e-9 {
+		t.Fatalf("Expected 35%% health at 0.5 elapsed, got %0.4f", elapsed)
+	}
+	if elapsed := encounter.elapsedAtHealthPercent(0.5); math.Abs(elapsed-0.5) > 1e-9 {
+		t.Fatalf("Expected 50%% health at 0.5 elapsed, got %0.4f", elapsed)
+	}
+}
+
+func TestHealthThresholdCallbacksFireInOrder(t *testing.T) {
+	encounter := Encounter{
+		ExecuteProportion_20: 0.2,
+		ExecuteProportion_25: 0.25,
+		ExecuteProportion_35: 0.35,
+		ExecuteProportion_90: 0.9,
+	}
+	encounter.healthTimeline = newHealthTimeline(&encounter)
+	sim := &Simulation{
+		Environment: &Environment{Encounter: encounter},
+		Duration:    time.Minute,
+	}
+	sim.resetHealthThresholds()
+
+	var fired []float64
+	for _, percent := range []float64{0.3, 0.5, 0.4} {
+		percent := percent
+		sim.RegisterHealthThresholdCallback(nil, percent, func(sim *Simulation) {
+			fired = append(fired, percent)
+		})
+	}
+
+	sim.advance(time.Second * 43)
+	if len(fired) != 3 || fired[0] != 0.5 || fired[1] != 0.4 || fired[2] != 0.3 {
+		t.Fatalf("Expected thresholds to fire from highest to lowest, got %v", fired)
+	}
+	if !sim.IsExecutePhase35() || sim.IsExecutePhase25() {
+		t.Fatalf("Expected to be in the 35%% execute phase")
+	}
+}
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/wowsims/cata/sim/core/proto"
@@ -9,7 +11,33 @@ import (
 
 type OnSwapItem func(*Simulation)
 
-const offset = proto.ItemSlot_ItemSlotMainHand
+// Equipping an item with an on-use effect puts that effect on cooldown.
+const itemSwapEquipCooldown = time.Second * 30
+
+// Index of the starting gear in ItemSwap.sets.
+const mainSwapSet = 0
+
+type itemSwapSet struct {
+	name string
+
+	// Slots which this set changes, and the items it puts in them.
+	slots []proto.ItemSlot
+	items Equipment
+
+	// Active while this set is equipped, so metrics show the time spent in it.
+	aura *Aura
+}
+
+// Auras, spells and stats from an effect of an item which is only equipped in
+// some swap sets, so they can follow the item as it is swapped.
+type itemSwapEffect struct {
+	isEquipped func() bool
+	equipped   bool
+
+	auras  []*Aura // Permanent auras, such as proc triggers.
+	spells []*Spell
+	stats  stats.Stats
+}
 
 type ItemSwap struct {
 	character       *Character
@@ -19,12 +47,25 @@ type ItemSwap struct {
 	ohCritMultiplier     float64
 	rangedCritMultiplier float64
 
-	// Which slots to actually swap.
+	// Which slots to actually swap, across all sets.
 	slots []proto.ItemSlot
 
-	// Holds items that are currently not equipped
-	unEquippedItems [3]Item
-	swapped         bool
+	// The main set holds the starting gear in every swapped slot, followed by
+	// the Swap1 set and then any named sets.
+	sets       []*itemSwapSet
+	currentSet int
+
+	// Which set the item equipped in each slot comes from.
+	equippedFrom [proto.ItemSlot_ItemSlotRanged + 1]int
+
+	effects map[itemSwapEffectKey]*itemSwapEffect
+
+	// Enchanted weapons whose enchant effects have been applied.
+	weaponEnchants map[itemSwapWeaponEnchantKey]bool
+
+	// Whether doneIteration put the main set back on, so the swap callbacks
+	// still need to run.
+	swappedBack bool
 }
 
 /*
@@ -33,44 +74,98 @@ TODO All the extra parameters here and the code in multiple places for handling
 	we'll need to figure out something cleaner as this will be quite error-prone
 */
 func (character *Character) enableItemSwap(itemSwap *proto.ItemSwap, mhCritMultiplier float64, ohCritMultiplier float64, rangedCritMultiplier float64) {
+	sets := []*itemSwapSet{
+		{name: proto.APLActionItemSwap_Main.String()},
+		character.newLegacyItemSwapSet(itemSwap),
+	}
+	for _, namedSet := range itemSwap.NamedSets {
+		// Sets are swapped to by name, so a later set with the same name is unreachable.
+		if slices.ContainsFunc(sets, func(set *itemSwapSet) bool { return set.name == namedSet.Name }) {
+			continue
+		}
+		sets = append(sets, character.newItemSwapSet(namedSet))
+	}
+
 	var slots []proto.ItemSlot
+	for _, set := range sets {
+		for _, slot := range set.slots {
+			if !slices.Contains(slots, slot) {
+				slots = append(slots, slot)
+			}
+		}
+	}
+
+	if len(slots) == 0 {
+		return
+	}
+
+	// Swap weapons in slot order, so the off hand is set up after the main hand.
+	slices.Sort(slots)
+	mainSet := sets[mainSwapSet]
+	mainSet.slots = slots
+	for _, slot := range slots {
+		mainSet.items[slot] = character.Equipment[slot]
+	}
+
+	character.ItemSwap = ItemSwap{
+		mhCritMultiplier:     mhCritMultiplier,
+		ohCritMultiplier:     ohCritMultiplier,
+		rangedCritMultiplier: rangedCritMultiplier,
+		slots:                slots,
+		sets:                 sets,
+		effects:              make(map[itemSwapEffectKey]*itemSwapEffect),
+		weaponEnchants:       make(map[itemSwapWeaponEnchantKey]bool),
+	}
+}
+
+// The Swap1 set, from the weapon fields of the ItemSwap proto.
+func (character *Character) newLegacyItemSwapSet(itemSwap *proto.ItemSwap) *itemSwapSet {
+	set := &itemSwapSet{name: proto.APLActionItemSwap_Swap1.String()}
+
 	hasMhSwap := itemSwap.MhItem != nil && itemSwap.MhItem.Id != 0
 	hasOhSwap := itemSwap.OhItem != nil && itemSwap.OhItem.Id != 0
 	hasRangedSwap := itemSwap.RangedItem != nil && itemSwap.RangedItem.Id != 0
 
-	swapItems := [3]Item{
-		toItem(itemSwap.MhItem),
-		toItem(itemSwap.OhItem),
-		toItem(itemSwap.RangedItem),
-	}
+	set.items[proto.ItemSlot_ItemSlotMainHand] = toItem(itemSwap.MhItem)
+	set.items[proto.ItemSlot_ItemSlotOffHand] = toItem(itemSwap.OhItem)
+	set.items[proto.ItemSlot_ItemSlotRanged] = toItem(itemSwap.RangedItem)
 
-	has2H := swapItems[0].HandType == proto.HandType_HandTypeTwoHand
+	has2H := set.items[proto.ItemSlot_ItemSlotMainHand].HandType == proto.HandType_HandTypeTwoHand
 	hasMh := character.HasMHWeapon()
 	hasOh := character.HasOHWeapon()
 
 	// Handle MH and OH together, because present MH + empty OH --> swap MH and unequip OH
 	if hasMhSwap || (hasOhSwap && hasMh) {
-		slots = append(slots, proto.ItemSlot_ItemSlotMainHand)
+		set.slots = append(set.slots, proto.ItemSlot_ItemSlotMainHand)
 	}
 	if hasOhSwap || (has2H && hasOh) {
-		slots = append(slots, proto.ItemSlot_ItemSlotOffHand)
+		set.slots = append(set.slots, proto.ItemSlot_ItemSlotOffHand)
 	}
 	if hasRangedSwap {
-		slots = append(slots, proto.ItemSlot_ItemSlotRanged)
+		set.slots = append(set.slots, proto.ItemSlot_ItemSlotRanged)
 	}
 
-	if len(slots) == 0 {
-		return
+	return set
+}
+
+func (character *Character) newItemSwapSet(setProto *proto.ItemSwapSet) *itemSwapSet {
+	set := &itemSwapSet{name: setProto.Name}
+
+	for i, itemSpec := range setProto.Items {
+		if i >= len(set.items) || itemSpec == nil || itemSpec.Id == 0 {
+			continue
+		}
+		set.items[i] = toItem(itemSpec)
+		set.slots = append(set.slots, proto.ItemSlot(i))
 	}
 
-	character.ItemSwap = ItemSwap{
-		mhCritMultiplier:     mhCritMultiplier,
-		ohCritMultiplier:     ohCritMultiplier,
-		rangedCritMultiplier: rangedCritMultiplier,
-		slots:                slots,
-		unEquippedItems:      swapItems,
-		swapped:              false,
+	// A two-hander also unequips the off hand.
+	mh := set.items[proto.ItemSlot_ItemSlotMainHand]
+	if mh.HandType == proto.HandType_HandTypeTwoHand && character.OffHand().ID != 0 && !slices.Contains(set.slots, proto.ItemSlot_ItemSlotOffHand) {
+		set.slots = append(set.slots, proto.ItemSlot_ItemSlotOffHand)
 	}
+
+	return set
 }
 
 func (swap *ItemSwap) initialize(character *Character) {
@@ -141,55 +236,116 @@ func (swap *ItemSwap) IsEnabled() bool {
 }
 
 func (swap *ItemSwap) IsSwapped() bool {
-	return swap.swapped
+	return swap.currentSet != mainSwapSet
+}
+
+// Returns the name of the currently equipped set.
+func (swap *ItemSwap) CurrentSet() string {
+	return swap.sets[swap.currentSet].name
+}
+
+// Returns the index of the set with the given name, or -1 if there is none.
+func (swap *ItemSwap) findSet(name string) int {
+	return slices.IndexFunc(swap.sets, func(set *itemSwapSet) bool {
+		return set.name == name
+	})
+}
+
+// Returns the item which the named set puts in the given slot, or nil if there
+// is no such set.
+func (swap *ItemSwap) GetItem(setName string, slot proto.ItemSlot) *Item {
+	setIdx := swap.findSet(setName)
+	if setIdx == -1 {
+		return nil
+	}
+	if !slices.Contains(swap.slots, slot) {
+		return &swap.character.Equipment[slot]
+	}
+	return &swap.sets[swap.sourceSet(setIdx, slot)].items[slot]
 }
 
-func (swap *ItemSwap) GetItem(slot proto.ItemSlot) *Item {
-	if slot-offset < 0 {
-		panic("Not able to swap Item " + slot.String() + " not supported")
+// Returns the items in the given slot of every swap set which changes it.
+func (swap *ItemSwap) GetItems(slot proto.ItemSlot) []*Item {
+	var items []*Item
+	for _, set := range swap.sets[mainSwapSet+1:] {
+		if slices.Contains(set.slots, slot) {
+			items = append(items, &set.items[slot])
+		}
 	}
-	return &swap.unEquippedItems[slot-offset]
+	return items
 }
 
-func (swap *ItemSwap) CalcStatChanges(slots []proto.ItemSlot) stats.Stats {
+// Returns the stat change from equipping the named set's items in the given
+// slots over the currently equipped ones.
+func (swap *ItemSwap) CalcStatChanges(setName string, slots []proto.ItemSlot) stats.Stats {
 	newStats := stats.Stats{}
+	if swap.findSet(setName) == -1 {
+		return newStats
+	}
 	for _, slot := range slots {
 		oldItemStats := swap.getItemStats(swap.character.Equipment[slot])
-		newItemStats := swap.getItemStats(*swap.GetItem(slot))
+		newItemStats := swap.getItemStats(*swap.GetItem(setName, slot))
 		newStats = newStats.Add(newItemStats.Subtract(oldItemStats))
 	}
 
 	return newStats
 }
 
-func (swap *ItemSwap) SwapItems(sim *Simulation, slots []proto.ItemSlot) {
-	if !swap.IsEnabled() {
-		return
+// Returns the set whose item goes in the given slot when swapping to setIdx.
+// Slots which setIdx doesn't change hold the main set's item.
+func (swap *ItemSwap) sourceSet(setIdx int, slot proto.ItemSlot) int {
+	if slices.Contains(swap.sets[setIdx].slots, slot) {
+		return setIdx
 	}
+	return mainSwapSet
+}
 
-	character := swap.character
+func isWeaponSlot(slot proto.ItemSlot) bool {
+	return slot == proto.ItemSlot_ItemSlotMainHand || slot == proto.ItemSlot_ItemSlotOffHand || slot == proto.ItemSlot_ItemSlotRanged
+}
 
-	meleeWeaponSwapped := false
-	newStats := stats.Stats{}
-	has2H := swap.GetItem(proto.ItemSlot_ItemSlotMainHand).HandType == proto.HandType_HandTypeTwoHand
-	for _, slot := range slots {
-		//will swap both on the MainHand Slot for 2H.
-		if slot == proto.ItemSlot_ItemSlotOffHand && has2H {
-			continue
+// Whether the character can swap to the given set now. Only weapons can be
+// swapped in combat.
+func (swap *ItemSwap) canSwapTo(sim *Simulation, setIdx int) bool {
+	if swap.currentSet == setIdx {
+		return false
+	}
+	if sim.CurrentTime < 0 {
+		return true
+	}
+	for _, slot := range swap.slots {
+		if !isWeaponSlot(slot) && swap.equippedFrom[slot] != swap.sourceSet(setIdx, slot) {
+			return false
 		}
+	}
+	return true
+}
 
-		if ok, swapStats := swap.swapItem(slot, has2H); ok {
-			newStats = newStats.Add(swapStats)
-			meleeWeaponSwapped = slot == proto.ItemSlot_ItemSlotMainHand || slot == proto.ItemSlot_ItemSlotOffHand || meleeWeaponSwapped
-		}
+func (swap *ItemSwap) swapTo(sim *Simulation, setIdx int) {
+	if !swap.IsEnabled() {
+		return
 	}
 
+	character := swap.character
+
+	newStats, meleeWeaponSwapped := swap.equipSet(setIdx)
+
 	if sim.Log != nil {
 		sim.Log("Item Swap Stats: %v", newStats.FlatString())
 	}
 
 	character.AddStatsDynamic(sim, newStats)
 
+	if aura := swap.sets[swap.currentSet].aura; aura != nil {
+		aura.Deactivate(sim)
+	}
+	swap.currentSet = setIdx
+	if aura := swap.sets[swap.currentSet].aura; aura != nil {
+		aura.Activate(sim)
+	}
+
+	swap.updateEffects(sim)
+
 	for _, onSwap := range swap.onSwapCallbacks {
 		onSwap(sim)
 	}
@@ -203,29 +359,39 @@ func (swap *ItemSwap) SwapItems(sim *Simulation, slots []proto.ItemSlot) {
 		newGCD := sim.CurrentTime + 1500*time.Millisecond
 		character.SetGCDTimer(sim, newGCD)
 	}
-
-	swap.swapped = !swap.swapped
 }
 
-func (swap *ItemSwap) swapItem(slot proto.ItemSlot, has2H bool) (bool, stats.Stats) {
-	oldItem := swap.character.Equipment[slot]
-	newItem := swap.GetItem(slot)
-
-	swap.character.Equipment[slot] = *newItem
-	oldItemStats := swap.getItemStats(oldItem)
-	newItemStats := swap.getItemStats(*newItem)
-	newStats := newItemStats.Subtract(oldItemStats)
+// Equips the items of the given set, and returns the stat change and whether
+// a melee weapon changed.
+func (swap *ItemSwap) equipSet(setIdx int) (stats.Stats, bool) {
+	meleeWeaponSwapped := false
+	newStats := stats.Stats{}
+	for _, slot := range swap.slots {
+		source := swap.sourceSet(setIdx, slot)
+		if swap.equippedFrom[slot] == source {
+			continue
+		}
 
-	//2H will swap out the offhand also.
-	if has2H && slot == proto.ItemSlot_ItemSlotMainHand {
-		_, ohStats := swap.swapItem(proto.ItemSlot_ItemSlotOffHand, has2H)
-		newStats = newStats.Add(ohStats)
+		newStats = newStats.Add(swap.swapItem(slot, source))
+		meleeWeaponSwapped = slot == proto.ItemSlot_ItemSlotMainHand || slot == proto.ItemSlot_ItemSlotOffHand || meleeWeaponSwapped
 	}
+	return newStats, meleeWeaponSwapped
+}
 
-	swap.unEquippedItems[slot-offset] = oldItem
+// Equips the item from the given set in the slot, and returns the stat change.
+func (swap *ItemSwap) swapItem(slot proto.ItemSlot, source int) stats.Stats {
+	character := swap.character
+	oldItem := character.Equipment[slot]
+	newItem := swap.sets[source].items[slot]
+
+	// Store the unequipped item back in its set, keeping any changes made to it
+	// while equipped, such as temporary enchants.
+	swap.sets[swap.equippedFrom[slot]].items[slot] = oldItem
+	character.Equipment[slot] = newItem
+	swap.equippedFrom[slot] = source
 	swap.swapWeapon(slot)
 
-	return true, newStats
+	return swap.getItemStats(newItem).Subtract(swap.getItemStats(oldItem))
 }
 
 func (swap *ItemSwap) getItemStats(item Item) stats.Stats {
@@ -255,24 +421,212 @@ func (swap *ItemSwap) swapWeapon(slot proto.ItemSlot) {
 	}
 }
 
+type itemSwapEffectKind byte
+
+const (
+	itemSwapEffectItem itemSwapEffectKind = iota
+	itemSwapEffectGem
+	itemSwapEffectEnchant
+)
+
+type itemSwapEffectKey struct {
+	kind itemSwapEffectKind
+	id   int32
+}
+
+type itemSwapWeaponEnchantKey struct {
+	slot      proto.ItemSlot
+	itemID    int32
+	enchantID int32
+}
+
+func (character *Character) hasItemEffectEquipped(key itemSwapEffectKey) bool {
+	for _, item := range character.Equipment {
+		switch key.kind {
+		case itemSwapEffectItem:
+			if item.ID == key.id {
+				return true
+			}
+		case itemSwapEffectGem:
+			if slices.ContainsFunc(item.Gems, func(gem Gem) bool { return gem.ID == key.id }) {
+				return true
+			}
+		case itemSwapEffectEnchant:
+			if item.Enchant.EffectID == key.id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Applies an effect of an item in a swapped slot, recording what it registers
+// so the effect can be turned on and off as the item is swapped.
+func (swap *ItemSwap) applyEffect(key itemSwapEffectKey, applyEffect func()) {
+	// Items and enchants may be in several sets, but their effects are only applied once.
+	if _, ok := swap.effects[key]; ok {
+		return
+	}
+
+	character := swap.character
+	effect := &itemSwapEffect{
+		isEquipped: func() bool { return character.hasItemEffectEquipped(key) },
+	}
+	effect.equipped = effect.isEquipped()
+	swap.effects[key] = effect
+
+	numAuras := len(character.auras)
+	numSpells := len(character.Spellbook)
+	statsBefore := character.stats
+
+	applyEffect()
+
+	for _, aura := range character.auras[numAuras:] {
+		if aura.Duration == NeverExpires {
+			effect.auras = append(effect.auras, aura)
+		}
+	}
+
+	for _, spell := range character.Spellbook[numSpells:] {
+		if !spell.Flags.Matches(SpellFlagMCD) {
+			continue
+		}
+		effect.spells = append(effect.spells, spell)
+		extraCastCondition := spell.ExtraCastCondition
+		spell.ExtraCastCondition = func(sim *Simulation, target *Unit) bool {
+			return effect.equipped && (extraCastCondition == nil || extraCastCondition(sim, target))
+		}
+	}
+
+	// Static stats can't be removed later, so take them back out until the item
+	// is equipped.
+	statsDelta := character.stats.Subtract(statsBefore)
+	effect.stats = statsDelta
+	if !effect.equipped {
+		character.AddStats(statsDelta.Invert())
+	}
+}
+
+// Registers the auras which track time spent in each set.
+func (swap *ItemSwap) registerSetAuras() {
+	for i, set := range swap.sets {
+		if len(set.slots) == 0 {
+			continue
+		}
+		set.aura = swap.character.RegisterAura(Aura{
+			Label:    fmt.Sprintf("Item Swap (%s)", set.name),
+			ActionID: ActionID{OtherID: proto.OtherAction_OtherActionItemSwap, Tag: int32(i)},
+			Duration: NeverExpires,
+		})
+	}
+}
+
+// Applies the effects of an item in a swapped slot.
+func (swap *ItemSwap) applySlotEffects(agent Agent, slot proto.ItemSlot, item Item) {
+	if applyItemEffect, ok := itemEffects[item.ID]; ok {
+		swap.applyEffect(itemSwapEffectKey{itemSwapEffectItem, item.ID}, func() { applyItemEffect(agent) })
+	}
+
+	for _, g := range item.Gems {
+		if applyGemEffect, ok := itemEffects[g.ID]; ok {
+			swap.applyEffect(itemSwapEffectKey{itemSwapEffectGem, g.ID}, func() { applyGemEffect(agent) })
+		}
+	}
+
+	if isWeaponSlot(slot) {
+		// Several sets may hold the same weapon, but its enchant is only applied once.
+		key := itemSwapWeaponEnchantKey{slot, item.ID, item.Enchant.EffectID}
+		if swap.weaponEnchants[key] {
+			return
+		}
+		swap.weaponEnchants[key] = true
+	}
+
+	if applyEnchantEffect, ok := enchantEffects[item.Enchant.EffectID]; ok {
+		if isWeaponSlot(slot) {
+			// Weapon enchants follow swaps through RegisterOnItemSwap callbacks.
+			applyEnchantEffect(agent)
+		} else {
+			swap.applyEffect(itemSwapEffectKey{itemSwapEffectEnchant, item.Enchant.EffectID}, func() { applyEnchantEffect(agent) })
+		}
+	}
+
+	if applyWeaponEffect, ok := weaponEffects[item.Enchant.EffectID]; ok {
+		applyWeaponEffect(agent, slot)
+	}
+}
+
+// Turns the effects of swapped items on or off to match the equipped items.
+func (swap *ItemSwap) updateEffects(sim *Simulation) {
+	character := swap.character
+	for _, effect := range swap.effects {
+		equipped := effect.isEquipped()
+		if equipped == effect.equipped {
+			continue
+		}
+		effect.equipped = equipped
+
+		if equipped {
+			character.AddStatsDynamic(sim, effect.stats)
+			for _, aura := range effect.auras {
+				aura.Activate(sim)
+			}
+			for _, spell := range effect.spells {
+				if spell.CD.Timer != nil {
+					spell.CD.Set(max(spell.CD.ReadyAt(), sim.CurrentTime+itemSwapEquipCooldown))
+				}
+			}
+		} else {
+			character.AddStatsDynamic(sim, effect.stats.Invert())
+			for _, aura := range effect.auras {
+				aura.Deactivate(sim)
+			}
+		}
+	}
+}
+
 func (swap *ItemSwap) reset(sim *Simulation) {
-	if !swap.IsEnabled() || !swap.IsSwapped() {
+	if !swap.IsEnabled() {
 		return
 	}
 
-	swap.SwapItems(sim, swap.slots)
+	// Permanent auras are activated on reset, so turn off those of items that
+	// start unequipped.
+	for _, effect := range swap.effects {
+		effect.equipped = effect.isEquipped()
+		if !effect.equipped {
+			for _, aura := range effect.auras {
+				aura.Deactivate(sim)
+			}
+		}
+	}
+
+	swap.sets[swap.currentSet].aura.Activate(sim)
+
+	// Callbacks may toggle auras, so they only run once the new iteration has
+	// started, to catch up with the items put back by doneIteration.
+	if swap.swappedBack {
+		swap.swappedBack = false
+		for _, onSwap := range swap.onSwapCallbacks {
+			onSwap(sim)
+		}
+	}
 }
 
-func (swap *ItemSwap) doneIteration(sim *Simulation) {
+// Puts the main set back on for the next iteration. Stats and auras are reset
+// along with the unit, so this only swaps the items, without firing any auras.
+func (swap *ItemSwap) doneIteration(_ *Simulation) {
 	if !swap.IsEnabled() || !swap.IsSwapped() {
 		return
 	}
 
-	swap.SwapItems(sim, swap.slots)
+	swap.equipSet(mainSwapSet)
+	swap.currentSet = mainSwapSet
+	swap.swappedBack = true
 }
 
 func toItem(itemSpec *proto.ItemSpec) Item {
-	if itemSpec == nil {
+	if itemSpec == nil || itemSpec.Id == 0 {
 		return Item{}
 	}
 
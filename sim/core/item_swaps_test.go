@@ -0,0 +1,230 @@
+package core_test
+
+import (
+	"testing"
+
+	_ "github.com/wowsims/cata/sim/common" // imported to get item effects included.
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/stats"
+)
+
+func getTestPlayerItemSwap() *proto.Player {
+	player := getTestPlayerMM()
+	player.DistanceFromTarget = 20
+	player.EnableItemSwap = true
+
+	items := make([]*proto.ItemSpec, proto.ItemSlot_ItemSlotTrinket1+1)
+	items[proto.ItemSlot_ItemSlotTrinket1] = &proto.ItemSpec{Id: 52199} // Figurine - Demon Panther
+	player.ItemSwap = &proto.ItemSwap{
+		NamedSets: []*proto.ItemSwapSet{
+			{Name: "Burst", Items: items},
+		},
+	}
+	return player
+}
+
+func itemSwapSetMetrics(result *proto.RaidSimResult, setIdx int32) *proto.AuraMetrics {
+	for _, aura := range result.RaidMetrics.Parties[0].Players[0].Auras {
+		if aura.Id.GetOtherId() == proto.OtherAction_OtherActionItemSwap && aura.Id.Tag == setIdx {
+			return aura
+		}
+	}
+	return &proto.AuraMetrics{}
+}
+
+func itemSwapSetUptime(result *proto.RaidSimResult, setIdx int32) float64 {
+	return itemSwapSetMetrics(result, setIdx).UptimeSecondsAvg
+}
+
+func TestItemSwapNamedSetPrepull(t *testing.T) {
+	player := getTestPlayerItemSwap()
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"prepullActions": [
+			{"action": {"itemSwap": {"setName": "Burst"}}, "doAtValue": {"const": {"val": "-5s"}}}
+		],
+		"priorityList": [
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	rsr.SimOptions.Iterations = 5
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+
+	// Named sets come after the main and legacy swap sets.
+	if uptime := itemSwapSetUptime(result, 2); uptime < float64(rsr.Encounter.Duration) {
+		t.Errorf("expected the Burst set to be worn for the whole fight, got %0.1fs", uptime)
+	}
+	if uptime := itemSwapSetUptime(result, 0); uptime != 0 {
+		t.Errorf("expected the main set to never be worn in combat, got %0.1fs", uptime)
+	}
+	// The main set is put back on between iterations without activating its aura.
+	if procs := itemSwapSetMetrics(result, 0).ProcsAvg; procs != 1 {
+		t.Errorf("expected the main set to be equipped once per iteration, got %0.2f", procs)
+	}
+}
+
+func TestItemSwapNamedSetWarnings(t *testing.T) {
+	player := getTestPlayerItemSwap()
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"priorityList": [
+			{"action": {"itemSwap": {"setName": "Burst"}}},
+			{"action": {"itemSwap": {"setName": "Missing"}}},
+			{"action": {"itemSwap": {"swapSet": "Main"}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	result := core.ComputeStats(&proto.ComputeStatsRequest{Raid: rsr.Raid, Encounter: rsr.Encounter})
+	if result.ErrorResult != "" {
+		t.Fatalf("compute stats failed: %s", result.ErrorResult)
+	}
+
+	priorityList := result.RaidStats.Parties[0].Players[0].RotationStats.PriorityList
+	if len(priorityList[0].Warnings) == 0 {
+		t.Errorf("expected a warning for swapping a trinket in combat")
+	}
+	if len(priorityList[1].Warnings) == 0 {
+		t.Errorf("expected a warning for an unknown set name")
+	}
+	if len(priorityList[2].Warnings) != 0 {
+		t.Errorf("expected no warnings for swapping back to the main set, got %v", priorityList[2].Warnings)
+	}
+}
+
+func TestItemSwapMainAfterPrepullWarning(t *testing.T) {
+	player := getTestPlayerItemSwap()
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"prepullActions": [
+			{"action": {"itemSwap": {"setName": "Burst"}}, "doAtValue": {"const": {"val": "-5s"}}}
+		],
+		"priorityList": [
+			{"action": {"itemSwap": {"swapSet": "Main"}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	result := core.ComputeStats(&proto.ComputeStatsRequest{Raid: rsr.Raid, Encounter: rsr.Encounter})
+	if result.ErrorResult != "" {
+		t.Fatalf("compute stats failed: %s", result.ErrorResult)
+	}
+
+	// The trinket swapped in before combat can't be taken off again.
+	if warnings := result.RaidStats.Parties[0].Players[0].RotationStats.PriorityList[0].Warnings; len(warnings) == 0 {
+		t.Errorf("expected a warning for swapping back to the main set after a prepull trinket swap")
+	}
+}
+
+func TestItemSwapStatChanges(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerItemSwap())
+	env, _, _ := core.NewEnvironment(rsr.Raid, rsr.Encounter, false)
+	character := env.Raid.Parties[0].Players[0].GetCharacter()
+	swap := &character.ItemSwap
+
+	trinket := proto.ItemSlot_ItemSlotTrinket1
+	if item := swap.GetItem("Burst", trinket); item == nil || item.ID != 52199 {
+		t.Fatalf("expected the Burst set to hold Figurine - Demon Panther, got %v", item)
+	}
+	if item := swap.GetItem("Burst", proto.ItemSlot_ItemSlotRanged); item == nil || item.ID != character.Ranged().ID {
+		t.Errorf("expected the Burst set to keep the equipped ranged weapon, got %v", item)
+	}
+	if item := swap.GetItem("Missing", trinket); item != nil {
+		t.Errorf("expected no item for an unknown set, got %v", item)
+	}
+
+	expected := core.ItemEquipmentStats(*swap.GetItem("Burst", trinket)).Subtract(core.ItemEquipmentStats(character.Equipment[trinket]))
+	slots := []proto.ItemSlot{trinket, proto.ItemSlot_ItemSlotRanged}
+	if changes := swap.CalcStatChanges("Burst", slots); changes != expected {
+		t.Errorf("expected stat changes of %s, got %s", expected.FlatString(), changes.FlatString())
+	}
+	if changes := swap.CalcStatChanges("Main", slots); !changes.Equals(stats.Stats{}) {
+		t.Errorf("expected no stat changes for the main set, got %s", changes.FlatString())
+	}
+}
+
+func TestItemSwapProcs(t *testing.T) {
+	const essenceOfTheCycloneAuraID = 92126
+
+	procUptime := func(prepullSwap bool) float64 {
+		player := getTestPlayerItemSwap()
+		items := make([]*proto.ItemSpec, proto.ItemSlot_ItemSlotTrinket2+1)
+		items[proto.ItemSlot_ItemSlotTrinket2] = &proto.ItemSpec{Id: 59473} // Essence of the Cyclone
+		player.ItemSwap.NamedSets = append(player.ItemSwap.NamedSets, &proto.ItemSwapSet{Name: "Procs", Items: items})
+
+		prepullActions := ""
+		if prepullSwap {
+			prepullActions = `{"action": {"itemSwap": {"setName": "Procs"}}, "doAtValue": {"const": {"val": "-5s"}}}`
+		}
+		player.Rotation = core.APLRotationFromJsonString(`{
+			"type": "TypeAPL",
+			"prepullActions": [` + prepullActions + `],
+			"priorityList": [
+				{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+			]
+		}`)
+
+		rsr := makeTestCase(player)
+		rsr.SimOptions.Iterations = 5
+		result := core.RunRaidSim(rsr)
+		if result.ErrorResult != "" {
+			t.Fatalf("sim failed: %s", result.ErrorResult)
+		}
+
+		uptime := 0.0
+		for _, aura := range result.RaidMetrics.Parties[0].Players[0].Auras {
+			if aura.Id.GetSpellId() == essenceOfTheCycloneAuraID {
+				uptime += aura.UptimeSecondsAvg
+			}
+		}
+		return uptime
+	}
+
+	if uptime := procUptime(true); uptime == 0 {
+		t.Errorf("expected the swapped in trinket to proc")
+	}
+	if uptime := procUptime(false); uptime != 0 {
+		t.Errorf("expected no procs from a trinket which is never equipped, got %0.1fs uptime", uptime)
+	}
+}
+
+func TestItemSwapEquipCooldown(t *testing.T) {
+	player := getTestPlayerItemSwap()
+	player.Rotation = core.APLRotationFromJsonString(`{
+		"type": "TypeAPL",
+		"prepullActions": [
+			{"action": {"itemSwap": {"setName": "Burst"}}, "doAtValue": {"const": {"val": "-5s"}}}
+		],
+		"priorityList": [
+			{"action": {"castSpell": {"spellId": {"itemId": 52199}}}},
+			{"action": {"castSpell": {"spellId": {"spellId": 56641}}}}
+		]
+	}`)
+
+	rsr := makeTestCase(player)
+	rsr.SimOptions.Iterations = 1
+	rsr.SimOptions.TraceApl = true
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+
+	firstUse := -1.0
+	for _, decision := range result.RaidMetrics.Parties[0].Players[0].AplTrace.Decisions {
+		if decision.Items[0].Selected {
+			firstUse = decision.TimeSeconds
+			break
+		}
+	}
+
+	// Equipping the trinket 5s before the pull puts its on-use effect on a 30s cooldown.
+	if firstUse < 25 {
+		t.Errorf("expected Figurine - Demon Panther to be used 25s into the fight at the earliest, got %0.2fs", firstUse)
+	}
+}
@@ -232,7 +232,38 @@ func (unit *Unit) TimeUntilManaRegen(desiredMana float64) time.Duration {
 	return regenTime
 }
 
+func (unit *Unit) manaRegenTicks(sim *Simulation) regenTicks {
+	if sim.manaTickAction == nil {
+		return regenTicks{next: NeverExpires}
+	}
+	next := sim.manaTickAction.NextActionAt
+	amount := unit.manaTickWhileCombat
+	if next <= 0 {
+		amount = unit.manaTickWhileNotCombat
+	}
+	return regenTicks{next: next, period: manaTickInterval, amount: amount}
+}
+
+// Returns the time until regen ticks bring mana to the given amount, or
+// NeverExpires if they never will.
+func (unit *Unit) TimeUntilMana(sim *Simulation, amount float64) time.Duration {
+	return forecastTimeUntil(sim, unit.CurrentMana(), unit.MaxMana(), amount, unit.manaRegenTicks(sim))
+}
+
+// Returns the mana at the given time, from regen ticks alone.
+func (unit *Unit) ManaAt(sim *Simulation, at time.Duration) float64 {
+	return forecastAt(unit.CurrentMana(), unit.MaxMana(), at, unit.manaRegenTicks(sim))
+}
+
+// Returns the mana missing from the maximum.
+func (unit *Unit) ManaDeficit() float64 {
+	return unit.MaxMana() - unit.CurrentMana()
+}
+
+const manaTickInterval = time.Second * 2
+
 func (sim *Simulation) initManaTickAction() {
+	sim.manaTickAction = nil
 	var unitsWithManaBars []*Unit
 
 	for _, party := range sim.Raid.Parties {
@@ -254,7 +285,7 @@ func (sim *Simulation) initManaTickAction() {
 		return
 	}
 
-	interval := time.Second * 2
+	interval := manaTickInterval
 	pa := &PendingAction{
 		NextActionAt: sim.Environment.PrepullStartTime() + interval,
 		Priority:     ActionPriorityRegen,
@@ -270,6 +301,7 @@ func (sim *Simulation) initManaTickAction() {
 		sim.AddPendingAction(pa)
 	}
 	sim.AddPendingAction(pa)
+	sim.manaTickAction = pa
 }
 
 func (mb *manaBar) reset() {
@@ -30,6 +30,11 @@ func (distMetrics *DistributionMetrics) reset() {
 	distMetrics.Total = 0
 }
 
+// Clears the aggregate values as well, for reusing the metrics in a new sim.
+func (distMetrics *DistributionMetrics) clear() {
+	*distMetrics = NewDistributionMetrics()
+}
+
 // This should be called when a Sim iteration is complete.
 func (distMetrics *DistributionMetrics) doneIteration(sim *Simulation) {
 	dps := distMetrics.Total / sim.Duration.Seconds()
@@ -102,6 +107,9 @@ type UnitMetrics struct {
 	oomTimeSum   float64
 	actions      map[ActionID]*ActionMetrics
 	resources    []*ResourceMetrics
+
+	damageTaken        map[damageTakenKey]*DamageTakenMetrics
+	cooldownMitigation []*CooldownMitigationMetrics
 }
 
 // Metrics for the current iteration, for 1 agent. Keep this as a separate
@@ -211,6 +219,8 @@ func NewUnitMetrics() UnitMetrics {
 		hps:     NewDistributionMetrics(),
 		tto:     NewDistributionMetrics(),
 		actions: make(map[ActionID]*ActionMetrics),
+
+		damageTaken: make(map[damageTakenKey]*DamageTakenMetrics),
 	}
 }
 
@@ -241,6 +251,12 @@ func (resourceMetrics *ResourceMetrics) reset() {
 	resourceMetrics.EventsFromPreviousIterations = resourceMetrics.Events
 	resourceMetrics.ActualGainFromPreviousIterations = resourceMetrics.ActualGain
 }
+func (resourceMetrics *ResourceMetrics) clear() {
+	*resourceMetrics = ResourceMetrics{
+		ActionID: resourceMetrics.ActionID,
+		Type:     resourceMetrics.Type,
+	}
+}
 func (resourceMetrics *ResourceMetrics) EventsForCurrentIteration() int32 {
 	return resourceMetrics.Events - resourceMetrics.EventsFromPreviousIterations
 }
@@ -387,6 +403,29 @@ func (unitMetrics *UnitMetrics) reset() {
 	}
 }
 
+// Clears all aggregate values, so the unit can be reused in a new sim. Resource
+// metrics are cleared in place because spells hold pointers to them.
+func (unitMetrics *UnitMetrics) clear() {
+	unitMetrics.dps.clear()
+	unitMetrics.dpasp.clear()
+	unitMetrics.threat.clear()
+	unitMetrics.dtps.clear()
+	unitMetrics.tmi.clear()
+	unitMetrics.hps.clear()
+	unitMetrics.tto.clear()
+	unitMetrics.tmiList = nil
+	unitMetrics.CharacterIterationMetrics = CharacterIterationMetrics{}
+
+	unitMetrics.numItersDead = 0
+	unitMetrics.oomTimeSum = 0
+	unitMetrics.actions = make(map[ActionID]*ActionMetrics)
+	for _, resourceMetrics := range unitMetrics.resources {
+		resourceMetrics.clear()
+	}
+
+	unitMetrics.clearDamageTaken()
+}
+
 // This should be called when a Sim iteration is complete.
 func (unitMetrics *UnitMetrics) doneIteration(unit *Unit, sim *Simulation) {
 	if unit.HasManaBar() {
@@ -529,6 +568,9 @@ func (unitMetrics *UnitMetrics) ToProto() *proto.UnitMetrics {
 		}
 	}
 
+	protoMetrics.DamageTaken = unitMetrics.damageTakenToProto()
+	protoMetrics.CooldownMitigation = unitMetrics.cooldownMitigationToProto()
+
 	return protoMetrics
 }
 
@@ -549,6 +591,10 @@ func (auraMetrics *AuraMetrics) reset() {
 	auraMetrics.Procs = 0
 }
 
+func (auraMetrics *AuraMetrics) clear() {
+	*auraMetrics = AuraMetrics{ID: auraMetrics.ID}
+}
+
 // This should be called when a Sim iteration is complete.
 func (auraMetrics *AuraMetrics) doneIteration() {
 	auraMetrics.add(auraMetrics.Uptime.Seconds())
@@ -0,0 +1,76 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+func makePartyBuffsTestCase() *proto.RaidSimRequest {
+	rsr := makeTestCase(getTestPlayerFeralCat())
+	rsr.Raid.Parties = append(rsr.Raid.Parties, &proto.Party{
+		Players: []*proto.Player{getTestPlayerMM()},
+		Buffs: &proto.PartyBuffs{
+			DevotionAura:       true,
+			ManaTideTotemCount: 1,
+		},
+	})
+	rsr.SimOptions.Iterations = 10
+	return rsr
+}
+
+func TestPartyBuffsApplyToTheirParty(t *testing.T) {
+	result := core.RunRaidSim(makePartyBuffsTestCase())
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+	feral := result.RaidMetrics.Parties[0].Players[0]
+	hunter := result.RaidMetrics.Parties[1].Players[0]
+
+	if uptime := auraUptime(feral.Auras, 465); uptime != 0 {
+		t.Errorf("Devotion Aura from another party should not apply, got %0.1fs uptime", uptime)
+	}
+	if uptime := auraUptime(hunter.Auras, 465); uptime == 0 {
+		t.Errorf("expected Devotion Aura from the hunter's party")
+	}
+	if uptime := auraUptime(hunter.Auras, core.ManaTideTotemActionID.SpellID); uptime == 0 {
+		t.Errorf("expected Mana Tide Totem from the hunter's party")
+	}
+}
+
+func TestInactivePartiesAreIgnored(t *testing.T) {
+	rsr := makePartyBuffsTestCase()
+	rsr.Raid.NumActiveParties = 1
+
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+	if numParties := len(result.RaidMetrics.Parties); numParties != 1 {
+		t.Errorf("expected 1 active party, got %d", numParties)
+	}
+}
+
+func TestDefaultPartnerStaysInParty(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerFeralCat())
+	rsr.Raid.Parties[0].Players = append(rsr.Raid.Parties[0].Players, getTestPlayerMM())
+	rsr.Raid.Parties = append(rsr.Raid.Parties, &proto.Party{
+		Players: []*proto.Player{getTestPlayerBloodDk()},
+	})
+
+	env, _, _ := core.NewEnvironment(rsr.Raid, rsr.Encounter, false)
+	feral := &env.Raid.Parties[0].Players[0].GetCharacter().Unit
+	hunter := &env.Raid.Parties[0].Players[1].GetCharacter().Unit
+	dk := &env.Raid.Parties[1].Players[0].GetCharacter().Unit
+
+	if partner := env.Raid.Parties[0].DefaultPartner(feral); partner != hunter {
+		t.Errorf("expected the hunter as the feral's partner")
+	}
+	if partner := env.Raid.Parties[0].DefaultPartner(hunter); partner != feral {
+		t.Errorf("expected the feral as the hunter's partner")
+	}
+	if partner := env.Raid.Parties[1].DefaultPartner(dk); partner != nil {
+		t.Errorf("expected no partner outside the death knight's party, got %s", partner.Label)
+	}
+}
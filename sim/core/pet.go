@@ -33,6 +33,9 @@ type Pet struct {
 	isGuardian     bool
 	enabledOnStart bool
 
+	// Whether boss abilities that hit the whole raid also hit this pet.
+	TakesRaidDamage bool
+
 	OnPetEnable  OnPetEnable
 	OnPetDisable OnPetDisable
 
@@ -31,13 +31,12 @@ type PresimOptions struct {
 	OnPresimResult func(presimResult *proto.UnitMetrics, iterations int32, duration time.Duration) bool
 }
 
-func (sim *Simulation) runPresims(request *proto.RaidSimRequest) *proto.RaidSimResult {
-	const numPresimIterations = 100
-
-	// Run presims if requested.
+// Returns the presim options for each Agent by character index, and how
+// many Agents requested presims.
+func (env *Environment) getPresimOptions(request *proto.RaidSimRequest) ([]*PresimOptions, int) {
 	raidPresimOptions := make([]*PresimOptions, 25)
 	remainingAgents := 0
-	for _, party := range sim.Raid.Parties {
+	for _, party := range env.Raid.Parties {
 		for _, player := range party.Players {
 			presimmer, ok := player.(Presimmer)
 			if !ok {
@@ -60,6 +59,20 @@ func (sim *Simulation) runPresims(request *proto.RaidSimRequest) *proto.RaidSimR
 			remainingAgents++
 		}
 	}
+	return raidPresimOptions, remainingAgents
+}
+
+// Whether runPresims would run any presims for the request.
+func (env *Environment) hasPresims(request *proto.RaidSimRequest) bool {
+	_, numAgents := env.getPresimOptions(request)
+	return numAgents > 0 || env.Encounter.EndFightAtHealth > 0
+}
+
+func (sim *Simulation) runPresims(request *proto.RaidSimRequest) *proto.RaidSimResult {
+	const numPresimIterations = 100
+
+	// Run presims if requested.
+	raidPresimOptions, remainingAgents := sim.getPresimOptions(request)
 
 	// Base presim request.
 	// Define this outside the loop so that, as Agents iteratively update their
@@ -69,6 +82,7 @@ func (sim *Simulation) runPresims(request *proto.RaidSimRequest) *proto.RaidSimR
 	presimRequest.SimOptions.RandomSeed = 1
 	presimRequest.SimOptions.Debug = false
 	presimRequest.SimOptions.DebugFirstIteration = false
+	presimRequest.SimOptions.ReplayIteration = false
 	presimRequest.SimOptions.Iterations = numPresimIterations
 	duration := DurationFromSeconds(presimRequest.Encounter.Duration)
 
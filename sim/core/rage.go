@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/wowsims/cata/sim/core/proto"
 )
@@ -17,6 +18,10 @@ type rageBar struct {
 	startingRage float64
 	currentRage  float64
 
+	// Rage from a main-hand and off-hand auto attack, used for forecasting.
+	mhSwingRage float64
+	ohSwingRage float64
+
 	RageRefundMetrics *ResourceMetrics
 }
 
@@ -127,6 +132,8 @@ func (unit *Unit) EnableRageBar(options RageBarOptions) {
 	unit.rageBar = rageBar{
 		unit:              unit,
 		startingRage:      max(0, min(options.StartingRage, MaxRage)),
+		mhSwingRage:       6.5 * options.MHSwingSpeed * options.RageMultiplier,
+		ohSwingRage:       6.5 / 2 * options.OHSwingSpeed * options.RageMultiplier,
 		RageRefundMetrics: unit.NewRageMetrics(ActionID{OtherID: proto.OtherAction_OtherActionRefund}),
 	}
 }
@@ -139,6 +146,54 @@ func (rb *rageBar) CurrentRage() float64 {
 	return rb.currentRage
 }
 
+// Returns the chance for an auto attack to land on the current target, i.e.
+// not be missed, dodged or (from the front) parried.
+func (wa *WeaponAttack) expectedLandChance() float64 {
+	target := wa.unit.CurrentTarget
+	if target == nil || wa.spell == nil {
+		return 1
+	}
+	attackTable := wa.unit.AttackTables[target.UnitIndex]
+	avoidChance := wa.spell.GetPhysicalMissChance(attackTable) +
+		max(0, attackTable.BaseDodgeChance-wa.spell.ExpertisePercentage()-wa.unit.PseudoStats.DodgeReduction)
+	if wa.unit.PseudoStats.InFrontOfTarget {
+		avoidChance += max(0, attackTable.BaseParryChance-wa.spell.ExpertisePercentage())
+	}
+	return max(0, 1-avoidChance)
+}
+
+// Rage is forecast from upcoming auto attacks, scaled by their expected chance
+// to land on the current target.
+func (rb *rageBar) rageRegenTicks() []regenTicks {
+	if rb.unit.GetCurrentPowerBar() != RageBar {
+		return nil
+	}
+	aa := rb.unit.AutoAttacks
+	swingTicks := func(wa *WeaponAttack, amount float64) regenTicks {
+		if !wa.enabled {
+			return regenTicks{next: NeverExpires}
+		}
+		return regenTicks{next: wa.swingAt, period: wa.curSwingDuration, amount: amount * wa.expectedLandChance()}
+	}
+	return []regenTicks{swingTicks(&aa.mh, rb.mhSwingRage), swingTicks(&aa.oh, rb.ohSwingRage)}
+}
+
+// Returns the time until auto attacks bring rage to the given amount, or
+// NeverExpires if they never will.
+func (rb *rageBar) TimeUntilRage(sim *Simulation, amount float64) time.Duration {
+	return forecastTimeUntil(sim, rb.currentRage, MaxRage, amount, rb.rageRegenTicks()...)
+}
+
+// Returns the rage at the given time, from auto attacks alone.
+func (rb *rageBar) RageAt(_ *Simulation, at time.Duration) float64 {
+	return forecastAt(rb.currentRage, MaxRage, at, rb.rageRegenTicks()...)
+}
+
+// Returns the rage missing from the rage cap.
+func (rb *rageBar) RageDeficit() float64 {
+	return MaxRage - rb.currentRage
+}
+
 func (rb *rageBar) AddRage(sim *Simulation, amount float64, metrics *ResourceMetrics) {
 	if amount < 0 {
 		panic("Trying to add negative rage!")
@@ -152,7 +207,7 @@ func (rb *rageBar) AddRage(sim *Simulation, amount float64, metrics *ResourceMet
 	}
 
 	rb.currentRage = newRage
-	if !sim.Options.Interactive {
+	if !rb.unit.IsInteractive(sim) {
 		rb.unit.ReactToEvent(sim)
 	}
 }
@@ -59,6 +59,17 @@ func (party *Party) GetPartyBuffs(basePartyBuffs *proto.PartyBuffs) *proto.Party
 	return partyBuffs
 }
 
+// Returns the first other player in the party, used as the default target
+// for single-target party buffs like Tricks of the Trade and Focus Magic.
+func (party *Party) DefaultPartner(unit *Unit) *Unit {
+	for _, player := range party.Players {
+		if partner := &player.GetCharacter().Unit; partner != unit {
+			return partner
+		}
+	}
+	return nil
+}
+
 func (party *Party) AddStats(newStats stats.Stats) {
 	for _, agent := range party.Players {
 		agent.GetCharacter().AddStats(newStats)
@@ -124,6 +135,9 @@ type Raid struct {
 
 	nextPetIndex int32
 
+	// When true, buffs and debuffs come only from the players in the raid.
+	BuffsFromComposition bool
+
 	replenishmentUnits         []*Unit   // All units who can receive replenishment.
 	curReplenishmentUnits      [][]*Unit // Units that currently have replenishment active, separated by source.
 	leftoverReplenishmentUnits []*Unit   // Units without replenishment currently active.
@@ -160,6 +174,8 @@ func NewRaid(raidConfig *proto.Raid) *Raid {
 		dpsMetrics:   NewDistributionMetrics(),
 		hpsMetrics:   NewDistributionMetrics(),
 		nextPetIndex: int32(numParties) * 5,
+
+		BuffsFromComposition: raidConfig.BuffsFromComposition,
 	}
 
 	for partyIndex, partyConfig := range raidConfig.Parties {
@@ -227,10 +243,17 @@ func (raid *Raid) getNextPetIndex() int32 {
 	return petIndex
 }
 
+// Optional interface for agents whose raid buffs are only simulated when the
+// raid buffs come from the composition. Otherwise they are covered by the raid
+// buff toggles.
+type CompositionBuffProvider interface {
+	AddCompositionRaidBuffs(raidBuffs *proto.RaidBuffs)
+}
+
 func (raid *Raid) GetRaidBuffs(baseRaidBuffs *proto.RaidBuffs) *proto.RaidBuffs {
 	// Compute the full raid buffs from the raid.
 	raidBuffs := &proto.RaidBuffs{}
-	if baseRaidBuffs != nil {
+	if baseRaidBuffs != nil && !raid.BuffsFromComposition {
 		raidBuffs = baseRaidBuffs
 	}
 	for _, party := range raid.Parties {
@@ -239,9 +262,52 @@ func (raid *Raid) GetRaidBuffs(baseRaidBuffs *proto.RaidBuffs) *proto.RaidBuffs
 			player.GetCharacter().AddRaidBuffs(raidBuffs)
 		}
 	}
+	if raid.BuffsFromComposition {
+		// Party-scoped auras and totems reach other parties only through
+		// their own party buffs, see withPartyBuffs.
+		for _, pair := range partyScopedBuffs(raidBuffs, &proto.PartyBuffs{}) {
+			*pair[0] = false
+		}
+		// Mana Tide is cast by the shamans themselves, for their own party.
+		raidBuffs.ManaTideTotemCount = 0
+		for _, party := range raid.Parties {
+			for _, player := range party.Players {
+				if provider, ok := player.(CompositionBuffProvider); ok {
+					provider.AddCompositionRaidBuffs(raidBuffs)
+				}
+			}
+		}
+	}
 	return raidBuffs
 }
 
+// Pairs of the auras and totems that only cover the party they come from.
+func partyScopedBuffs(raidBuffs *proto.RaidBuffs, partyBuffs *proto.PartyBuffs) [][2]*bool {
+	return [][2]*bool{
+		{&raidBuffs.DevotionAura, &partyBuffs.DevotionAura},
+		{&raidBuffs.RetributionAura, &partyBuffs.RetributionAura},
+		{&raidBuffs.ResistanceAura, &partyBuffs.ResistanceAura},
+		{&raidBuffs.StrengthOfEarthTotem, &partyBuffs.StrengthOfEarthTotem},
+		{&raidBuffs.StoneskinTotem, &partyBuffs.StoneskinTotem},
+		{&raidBuffs.WindfuryTotem, &partyBuffs.WindfuryTotem},
+		{&raidBuffs.WrathOfAirTotem, &partyBuffs.WrathOfAirTotem},
+		{&raidBuffs.ManaSpringTotem, &partyBuffs.ManaSpringTotem},
+		{&raidBuffs.FlametongueTotem, &partyBuffs.FlametongueTotem},
+		{&raidBuffs.TotemicWrath, &partyBuffs.TotemicWrath},
+		{&raidBuffs.ElementalResistanceTotem, &partyBuffs.ElementalResistanceTotem},
+	}
+}
+
+// Returns the raid buffs as seen by the members of a party, including the
+// auras and totems that only cover that party.
+func withPartyBuffs(raidBuffs *proto.RaidBuffs, partyBuffs *proto.PartyBuffs) *proto.RaidBuffs {
+	merged := googleProto.Clone(raidBuffs).(*proto.RaidBuffs)
+	for _, pair := range partyScopedBuffs(merged, partyBuffs) {
+		*pair[0] = *pair[0] || *pair[1]
+	}
+	return merged
+}
+
 // Precompute the playersAndPets array for each party.
 func (raid *Raid) updatePlayersAndPets() {
 	var raidPlayers []*Unit
@@ -280,29 +346,35 @@ func (raid *Raid) applyCharacterEffects(raidConfig *proto.Raid) *proto.RaidStats
 	raidBuffs := raid.GetRaidBuffs(raidConfig.Buffs)
 	raidStats := &proto.RaidStats{}
 
-	for partyIdx, party := range raid.Parties {
-		partyConfig := raidConfig.Parties[partyIdx]
-		partyBuffs := party.GetPartyBuffs(partyConfig.Buffs)
+	for _, party := range raid.Parties {
+		// Inactive or empty parties are skipped in NewRaid, so look up the config by index.
+		partyConfig := raidConfig.Parties[party.Index]
+		basePartyBuffs := partyConfig.Buffs
+		if raid.BuffsFromComposition {
+			basePartyBuffs = nil
+		}
+		partyBuffs := party.GetPartyBuffs(basePartyBuffs)
+		partyRaidBuffs := withPartyBuffs(raidBuffs, partyBuffs)
 		partyStats := &proto.PartyStats{
 			Players: make([]*proto.PlayerStats, 5),
 		}
 
 		// Apply all buffs to the players in this party.
-		for playerIdx, player := range party.Players {
-			if playerIdx >= len(partyConfig.Players) {
+		for _, player := range party.Players {
+			char := player.GetCharacter()
+			if char.PartyIndex >= len(partyConfig.Players) {
 				// This happens for target dummies.
 				continue
 			}
-			playerConfig := partyConfig.Players[playerIdx]
+			playerConfig := partyConfig.Players[char.PartyIndex]
 			individualBuffs := &proto.IndividualBuffs{}
-			if playerConfig.Buffs != nil {
+			if playerConfig.Buffs != nil && !raid.BuffsFromComposition {
 				individualBuffs = playerConfig.Buffs
 			}
 
-			char := player.GetCharacter()
 			char.EnableHealthBar()
 			char.trackChanceOfDeath(playerConfig.HealingModel)
-			partyStats.Players[char.PartyIndex] = char.applyAllEffects(player, raidBuffs, partyBuffs, individualBuffs)
+			partyStats.Players[char.PartyIndex] = char.applyAllEffects(player, partyRaidBuffs, partyBuffs, individualBuffs)
 
 			for _, pet := range char.Pets {
 				pet.EnableHealthBar()
@@ -0,0 +1,64 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+func makeCompositionTestCase(buffsFromComposition bool) *proto.RaidSimRequest {
+	hunter := getTestPlayerMM()
+	hunter.GetMarksmanshipHunter().Options.ClassOptions.PetType = proto.HunterOptions_CoreHound
+
+	rsr := makeTestCase(getTestPlayerFeralCat())
+	rsr.Raid.Parties[0].Players = append(rsr.Raid.Parties[0].Players, hunter)
+	rsr.Raid.Buffs = &proto.RaidBuffs{ArcaneBrilliance: true}
+	rsr.Raid.Debuffs = &proto.Debuffs{CurseOfElements: true}
+	rsr.Raid.BuffsFromComposition = buffsFromComposition
+	rsr.SimOptions.Iterations = 10
+	return rsr
+}
+
+func auraUptime(auras []*proto.AuraMetrics, spellID int32) float64 {
+	for _, aura := range auras {
+		if aura.Id.GetSpellId() == spellID {
+			return aura.UptimeSecondsAvg
+		}
+	}
+	return 0
+}
+
+func TestBuffsFromComposition(t *testing.T) {
+	result := core.RunRaidSim(makeCompositionTestCase(true))
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+	feral := result.RaidMetrics.Parties[0].Players[0]
+	target := result.EncounterMetrics.Targets[0]
+
+	if uptime := auraUptime(feral.Auras, 1459); uptime != 0 {
+		t.Errorf("Arcane Brilliance toggle should be ignored, got %0.1fs uptime", uptime)
+	}
+	if uptime := auraUptime(target.Auras, 1490); uptime != 0 {
+		t.Errorf("Curse of Elements toggle should be ignored, got %0.1fs uptime", uptime)
+	}
+	if uptime := auraUptime(feral.Auras, 90355); uptime == 0 {
+		t.Errorf("expected Ancient Hysteria from the hunter's Core Hound")
+	}
+}
+
+func TestBuffsFromToggles(t *testing.T) {
+	result := core.RunRaidSim(makeCompositionTestCase(false))
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+	feral := result.RaidMetrics.Parties[0].Players[0]
+
+	if uptime := auraUptime(feral.Auras, 1459); uptime == 0 {
+		t.Errorf("expected Arcane Brilliance from the raid buff toggle")
+	}
+	if uptime := auraUptime(feral.Auras, 90355); uptime != 0 {
+		t.Errorf("Ancient Hysteria should only be cast when buffs come from the composition")
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"math"
+	"time"
+)
+
+// Resource gained in equal amounts at a fixed interval, such as energy ticks
+// or rage from auto attacks. Used to forecast resource regen.
+type regenTicks struct {
+	next   time.Duration // Time of the next tick, or NeverExpires if there is none.
+	period time.Duration
+	amount float64 // Resource gained per tick.
+}
+
+// Returns the number of ticks that happen from now until the given time, inclusive.
+func (rt regenTicks) countBy(at time.Duration) int {
+	if rt.amount <= 0 || rt.next == NeverExpires || at < rt.next {
+		return 0
+	}
+	if rt.period <= 0 {
+		return 1
+	}
+	return 1 + int((at-rt.next)/rt.period)
+}
+
+// Returns the resource gained from ticks until the given time, ignoring any cap.
+func forecastGain(at time.Duration, ticks ...regenTicks) float64 {
+	gain := 0.0
+	for _, rt := range ticks {
+		gain += float64(rt.countBy(at)) * rt.amount
+	}
+	return gain
+}
+
+// Returns the time at which the ticks have gained the given amount of
+// resource, or NeverExpires if they never will.
+func forecastGainedAt(sim *Simulation, amount float64, ticks ...regenTicks) time.Duration {
+	if amount <= 0 {
+		return sim.CurrentTime
+	}
+
+	// With a single source this is a closed form, otherwise walk through the
+	// ticks in order.
+	if len(ticks) == 1 {
+		rt := ticks[0]
+		if rt.amount <= 0 || rt.next == NeverExpires {
+			return NeverExpires
+		}
+		numTicks := math.Ceil(amount/rt.amount - 1e-9)
+		if numTicks > 1 && rt.period <= 0 {
+			return NeverExpires
+		}
+		return rt.next + time.Duration(numTicks-1)*rt.period
+	}
+
+	ticks = append([]regenTicks(nil), ticks...)
+	const maxTicks = 1000
+	for i := 0; i < maxTicks; i++ {
+		nextIdx := -1
+		for j, rt := range ticks {
+			if rt.amount > 0 && rt.next != NeverExpires && (nextIdx == -1 || rt.next < ticks[nextIdx].next) {
+				nextIdx = j
+			}
+		}
+		if nextIdx == -1 {
+			return NeverExpires
+		}
+
+		rt := &ticks[nextIdx]
+		amount -= rt.amount
+		if amount <= 1e-9 {
+			return rt.next
+		}
+		if rt.period <= 0 {
+			rt.next = NeverExpires
+		} else {
+			rt.next += rt.period
+		}
+	}
+	return NeverExpires
+}
+
+// Returns the resource after regen until the given time, starting from current
+// and capped at maximum.
+func forecastAt(current float64, maximum float64, at time.Duration, ticks ...regenTicks) float64 {
+	return max(current, min(current+forecastGain(at, ticks...), maximum))
+}
+
+// Returns the time until the resource regens from current to the given
+// amount, or NeverExpires if it never will.
+func forecastTimeUntil(sim *Simulation, current float64, maximum float64, amount float64, ticks ...regenTicks) time.Duration {
+	if current >= amount {
+		return 0
+	}
+	if amount > maximum {
+		return NeverExpires
+	}
+	gainedAt := forecastGainedAt(sim, amount-current, ticks...)
+	if gainedAt == NeverExpires {
+		return NeverExpires
+	}
+	return max(gainedAt-sim.CurrentTime, 0)
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForecastSingleSource(t *testing.T) {
+	sim := &Simulation{CurrentTime: time.Second}
+	ticks := regenTicks{next: time.Second * 2, period: time.Second, amount: 10}
+
+	if at := forecastAt(50, 100, time.Millisecond*1500, ticks); at != 50 {
+		t.Fatalf("Expected no regen before the next tick, got %0.1f", at)
+	}
+	if at := forecastAt(50, 100, time.Second*4, ticks); at != 80 {
+		t.Fatalf("Expected 3 ticks of regen, got %0.1f", at)
+	}
+	if at := forecastAt(50, 100, time.Second*20, ticks); at != 100 {
+		t.Fatalf("Expected regen to be capped, got %0.1f", at)
+	}
+
+	if ttr := forecastTimeUntil(sim, 50, 100, 75, ticks); ttr != time.Second*3 {
+		t.Fatalf("Expected 3s until 75, got %s", ttr)
+	}
+	if ttr := forecastTimeUntil(sim, 50, 100, 40, ticks); ttr != 0 {
+		t.Fatalf("Expected 0s until an amount already reached, got %s", ttr)
+	}
+	if ttr := forecastTimeUntil(sim, 50, 100, 120, ticks); ttr != NeverExpires {
+		t.Fatalf("Expected an amount over the cap to never be reached, got %s", ttr)
+	}
+	if ttr := forecastTimeUntil(sim, 50, 100, 75); ttr != NeverExpires {
+		t.Fatalf("Expected no regen without ticks, got %s", ttr)
+	}
+}
+
+func TestForecastMultipleSources(t *testing.T) {
+	sim := &Simulation{}
+	mh := regenTicks{next: time.Second, period: time.Second * 3, amount: 20}
+	oh := regenTicks{next: time.Second * 2, period: time.Second * 2, amount: 5}
+
+	// MH at 1s and 4s, OH at 2s and 4s.
+	if at := forecastAt(0, 100, time.Second*4, mh, oh); at != 50 {
+		t.Fatalf("Expected 50 from both sources, got %0.1f", at)
+	}
+	if ttr := forecastTimeUntil(sim, 0, 100, 26, mh, oh); ttr != time.Second*4 {
+		t.Fatalf("Expected 4s until 26, got %s", ttr)
+	}
+	if ttr := forecastTimeUntil(sim, 0, 100, 25, mh, oh); ttr != time.Second*2 {
+		t.Fatalf("Expected 2s until 25, got %s", ttr)
+	}
+}
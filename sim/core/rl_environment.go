@@ -0,0 +1,323 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wowsims/cata/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+const defaultRLWaitDuration = time.Millisecond * 100
+
+// RLEnvironment drives a single player through an encounter one decision at a
+// time, for training rotation policies with reinforcement learning.
+//
+// The request is parsed once; every episode reuses the same environment and
+// only resets the simulation state, like iterations of a normal sim.
+type RLEnvironment struct {
+	sim       *Simulation
+	character *Character
+	settings  *proto.RLEnvironmentSettings
+
+	waitDuration time.Duration
+
+	actions     []*Spell
+	auras       []*Aura
+	targetAuras []*Aura
+
+	episode    int64
+	running    bool
+	done       bool
+	lastReward float64
+}
+
+func NewRLEnvironment(settings *proto.RLEnvironmentSettings) (*RLEnvironment, error) {
+	rsr := settings.Request
+	if rsr == nil || rsr.Raid == nil || rsr.Encounter == nil {
+		return nil, errors.New("request must contain a raid and an encounter")
+	}
+	numPlayers := 0
+	for _, party := range rsr.Raid.Parties {
+		for _, player := range party.Players {
+			if player != nil && player.Class != proto.Class_ClassUnknown {
+				numPlayers++
+			}
+		}
+	}
+	if numPlayers != 1 {
+		return nil, errors.New("request must contain exactly one player")
+	}
+
+	simOptions := &proto.SimOptions{}
+	if rsr.SimOptions != nil {
+		simOptions = googleProto.Clone(rsr.SimOptions).(*proto.SimOptions)
+	}
+	simOptions.Interactive = true
+	if simOptions.RandomSeed == 0 {
+		simOptions.RandomSeed = time.Now().UnixNano()
+	}
+
+	env, _, _ := NewEnvironment(rsr.Raid, rsr.Encounter, false)
+	sim := newSimWithEnv(env, simOptions)
+
+	var character *Character
+	for _, party := range sim.Raid.Parties {
+		if len(party.Players) > 0 {
+			character = party.Players[0].GetCharacter()
+			break
+		}
+	}
+
+	rlEnv := &RLEnvironment{
+		sim:          sim,
+		character:    character,
+		settings:     settings,
+		waitDuration: DurationFromSeconds(settings.WaitDuration),
+	}
+	if rlEnv.waitDuration <= 0 {
+		rlEnv.waitDuration = defaultRLWaitDuration
+	}
+
+	rlEnv.actions = FilterSlice(character.Spellbook, func(spell *Spell) bool {
+		return spell.Flags.Matches(SpellFlagAPL) && !spell.Flags.Matches(SpellFlagPrepullOnly)
+	})
+	rlEnv.auras = FilterSlice(character.auras, func(aura *Aura) bool {
+		return !aura.ActionID.IsEmptyAction()
+	})
+	if character.CurrentTarget != nil {
+		rlEnv.targetAuras = FilterSlice(character.CurrentTarget.auras, func(aura *Aura) bool {
+			return !aura.ActionID.IsEmptyAction()
+		})
+	}
+
+	return rlEnv, nil
+}
+
+// Info describes the action space and the auras included in observations.
+func (rlEnv *RLEnvironment) Info() *proto.RLEnvironmentInfo {
+	metadata := rlEnv.character.GetMetadata()
+	spellStats := make(map[ActionID]*proto.SpellStats, len(metadata.Spells))
+	for i, spell := range rlEnv.character.Spellbook {
+		spellStats[spell.ActionID] = metadata.Spells[i]
+	}
+
+	auraStats := func(auras []*Aura) []*proto.AuraStats {
+		return MapSlice(auras, func(aura *Aura) *proto.AuraStats {
+			return &proto.AuraStats{
+				Id:                 aura.ActionID.ToProto(),
+				MaxStacks:          aura.MaxStacks,
+				HasIcd:             aura.Icd != nil,
+				HasExclusiveEffect: len(aura.ExclusiveEffects) > 0,
+			}
+		})
+	}
+
+	return &proto.RLEnvironmentInfo{
+		ActionSpace: MapSlice(rlEnv.actions, func(spell *Spell) *proto.SpellStats {
+			return spellStats[spell.ActionID]
+		}),
+		Auras:       auraStats(rlEnv.auras),
+		TargetAuras: auraStats(rlEnv.targetAuras),
+	}
+}
+
+// NumActions returns the size of the discrete action space, including the
+// trailing 'wait' action.
+func (rlEnv *RLEnvironment) NumActions() int {
+	return len(rlEnv.actions) + 1
+}
+
+// Reset starts a new episode and returns its first observation. A
+// non-negative seed reseeds the randomness to random_seed + seed, the same as
+// iteration 'seed' of a normal sim with the same random seed. Iteration 0 of a
+// normal sim isn't reseeded and starts from the sim's own seed instead, so it
+// only matches seed 0 when random_seed is set. A negative seed continues with
+// the next episode.
+func (rlEnv *RLEnvironment) Reset(seed int64) *proto.RLObservation {
+	sim := rlEnv.sim
+	if rlEnv.running {
+		sim.Cleanup()
+	}
+
+	if seed < 0 {
+		seed = rlEnv.episode
+	}
+	rlEnv.episode = seed + 1
+
+	sim.reseedRands(seed)
+	sim.reset()
+	sim.NeedsInput = false
+	sim.PrePull()
+
+	rlEnv.running = true
+	rlEnv.done = false
+	rlEnv.advance()
+	rlEnv.lastReward = rlEnv.cumulativeReward()
+
+	return rlEnv.observe()
+}
+
+// Step performs the given action and advances the simulation until the player
+// needs input again or the episode ends.
+func (rlEnv *RLEnvironment) Step(action int) *proto.RLStepResult {
+	if rlEnv.done {
+		return &proto.RLStepResult{
+			Observation: rlEnv.observe(),
+			Done:        true,
+		}
+	}
+	if !rlEnv.running {
+		return &proto.RLStepResult{
+			ErrorResult: "environment must be reset before stepping",
+		}
+	}
+
+	sim := rlEnv.sim
+	character := rlEnv.character
+
+	valid := action == len(rlEnv.actions)
+	if action >= 0 && action < len(rlEnv.actions) {
+		spell := rlEnv.actions[action]
+		target := character.CurrentTarget
+		if spell.Flags.Matches(SpellFlagHelpful) {
+			target = &character.Unit
+		}
+		if spell.CanCast(sim, target) && spell.Cast(sim, target) {
+			valid = true
+			// Casts that start the GCD or a hardcast reschedule the rotation.
+			if !character.GCD.IsReady(sim) || character.Hardcast.Expires > sim.CurrentTime {
+				sim.NeedsInput = false
+			}
+		}
+	}
+
+	reward := 0.0
+	if !valid || action == len(rlEnv.actions) {
+		if !valid {
+			reward -= rlEnv.settings.InvalidActionPenalty
+		}
+		sim.NeedsInput = false
+		character.WaitUntil(sim, sim.CurrentTime+rlEnv.waitDuration)
+	}
+
+	rlEnv.advance()
+
+	cumulativeReward := rlEnv.cumulativeReward()
+	reward += cumulativeReward - rlEnv.lastReward
+	rlEnv.lastReward = cumulativeReward
+
+	return &proto.RLStepResult{
+		Observation: rlEnv.observe(),
+		Reward:      reward,
+		Done:        rlEnv.done,
+		ActionValid: valid,
+	}
+}
+
+// Runs the simulation until the player needs input or the episode ends.
+func (rlEnv *RLEnvironment) advance() {
+	sim := rlEnv.sim
+	for !sim.NeedsInput {
+		if sim.Step() {
+			sim.Cleanup()
+			rlEnv.done = true
+			rlEnv.running = false
+			return
+		}
+	}
+}
+
+func (rlEnv *RLEnvironment) cumulativeReward() float64 {
+	total := 0.0
+	addSpells := func(spellbook []*Spell) {
+		for _, spell := range spellbook {
+			for _, splitMetrics := range spell.splitSpellMetrics {
+				for _, metrics := range splitMetrics {
+					switch rlEnv.settings.RewardType {
+					case proto.RLRewardType_RLRewardThreat:
+						total += metrics.TotalThreat
+					case proto.RLRewardType_RLRewardHealing:
+						total += metrics.TotalHealing + metrics.TotalShielding
+					default:
+						total += metrics.TotalDamage
+					}
+				}
+			}
+		}
+	}
+
+	addSpells(rlEnv.character.Spellbook)
+	for _, petAgent := range rlEnv.character.PetAgents {
+		addSpells(petAgent.GetPet().Spellbook)
+	}
+	return total
+}
+
+func (rlEnv *RLEnvironment) observe() *proto.RLObservation {
+	sim := rlEnv.sim
+	character := rlEnv.character
+
+	obs := &proto.RLObservation{
+		CurrentTime:          sim.CurrentTime.Seconds(),
+		RemainingTime:        max(0, sim.GetRemainingDuration().Seconds()),
+		RemainingTimePercent: max(0, sim.GetRemainingDurationPercent()),
+		GcdTimeToReady:       character.GCD.TimeToReady(sim).Seconds(),
+		IsCasting:            character.Hardcast.Expires > sim.CurrentTime,
+	}
+
+	if character.HasHealthBar() {
+		obs.HealthPercent = character.CurrentHealthPercent()
+	}
+	if character.HasManaBar() {
+		obs.Mana = character.CurrentMana()
+		obs.ManaPercent = character.CurrentManaPercent()
+	}
+	if character.HasRageBar() {
+		obs.Rage = character.CurrentRage()
+	}
+	if character.HasEnergyBar() {
+		obs.Energy = character.CurrentEnergy()
+		obs.ComboPoints = character.ComboPoints()
+	}
+	if character.HasFocusBar() {
+		obs.Focus = character.CurrentFocus()
+	}
+	if character.HasRunicPowerBar() {
+		obs.RunicPower = character.CurrentRunicPower()
+	}
+
+	target := character.CurrentTarget
+	if target != nil {
+		obs.TargetHealthPercent = sim.GetTargetHealthPercent(target)
+	}
+
+	obs.Actions = MapSlice(rlEnv.actions, func(spell *Spell) *proto.RLActionState {
+		spellTarget := target
+		if spell.Flags.Matches(SpellFlagHelpful) {
+			spellTarget = &character.Unit
+		}
+		return &proto.RLActionState{
+			Id:          spell.ActionID.ToProto(),
+			CanCast:     !rlEnv.done && spell.CanCast(sim, spellTarget),
+			TimeToReady: spell.TimeToReady(sim).Seconds(),
+		}
+	})
+
+	auraState := func(aura *Aura) *proto.RLAuraState {
+		state := &proto.RLAuraState{
+			Id:       aura.ActionID.ToProto(),
+			IsActive: aura.IsActive(),
+			Stacks:   aura.GetStacks(),
+		}
+		if state.IsActive {
+			// Permanent auras report the remaining fight time instead of NeverExpires.
+			state.RemainingTime = min(aura.RemainingDuration(sim).Seconds(), obs.RemainingTime)
+		}
+		return state
+	}
+	obs.Auras = MapSlice(rlEnv.auras, auraState)
+	obs.TargetAuras = MapSlice(rlEnv.targetAuras, auraState)
+
+	return obs
+}
@@ -0,0 +1,158 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Plays an episode by always casting the first castable action. Off-GCD
+// actions don't advance time, so each action is used at most once per
+// timestamp.
+func runGreedyRLEpisode(t *testing.T, rlEnv *core.RLEnvironment, seed int64) (float64, int) {
+	obs := rlEnv.Reset(seed)
+	totalReward := 0.0
+	steps := 0
+	usedActions := map[int]bool{}
+	for {
+		action := rlEnv.NumActions() - 1
+		for i, state := range obs.Actions {
+			if state.CanCast && !usedActions[i] {
+				action = i
+				break
+			}
+		}
+		usedActions[action] = true
+
+		result := rlEnv.Step(action)
+		if result.ErrorResult != "" {
+			t.Fatalf("step failed: %s", result.ErrorResult)
+		}
+		if result.Observation.CurrentTime < obs.CurrentTime {
+			t.Fatalf("time went backwards: %f -> %f", obs.CurrentTime, result.Observation.CurrentTime)
+		}
+		totalReward += result.Reward
+		steps++
+		if result.Observation.CurrentTime > obs.CurrentTime {
+			usedActions = map[int]bool{}
+		}
+		obs = result.Observation
+		if result.Done {
+			return totalReward, steps
+		}
+	}
+}
+
+func TestRLEnvironment(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerMM())
+	rsr.Encounter.Duration = 60
+
+	twoPlayerRsr := googleProto.Clone(rsr).(*proto.RaidSimRequest)
+	party := twoPlayerRsr.Raid.Parties[0]
+	party.Players = append(party.Players, party.Players[0])
+	if _, err := core.NewRLEnvironment(&proto.RLEnvironmentSettings{Request: twoPlayerRsr}); err == nil {
+		t.Fatalf("expected an error for a request with two players")
+	}
+
+	rlEnv, err := core.NewRLEnvironment(&proto.RLEnvironmentSettings{
+		Request:    rsr,
+		RewardType: proto.RLRewardType_RLRewardDamage,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := rlEnv.Info()
+	if len(info.ActionSpace) == 0 || len(info.ActionSpace)+1 != rlEnv.NumActions() {
+		t.Fatalf("unexpected action space size %d", len(info.ActionSpace))
+	}
+
+	reward, steps := runGreedyRLEpisode(t, rlEnv, 0)
+	if reward <= 0 || steps < 10 {
+		t.Fatalf("expected damage over many steps, got reward %f in %d steps", reward, steps)
+	}
+
+	if result := rlEnv.Step(0); !result.Done {
+		t.Fatalf("stepping a finished episode should report done")
+	}
+
+	// Resetting with the same seed replays the same episode.
+	reward2, steps2 := runGreedyRLEpisode(t, rlEnv, 0)
+	if reward2 != reward || steps2 != steps {
+		t.Fatalf("seeded episodes differ: %f in %d steps vs %f in %d steps", reward, steps, reward2, steps2)
+	}
+}
+
+func TestRLEnvironmentInvalidAction(t *testing.T) {
+	rlEnv, err := core.NewRLEnvironment(&proto.RLEnvironmentSettings{
+		Request:              makeTestCase(getTestPlayerFeralCat()),
+		InvalidActionPenalty: 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rlEnv.Reset(0)
+	result := rlEnv.Step(rlEnv.NumActions())
+	if result.ActionValid {
+		t.Fatalf("out of range action should be invalid")
+	}
+	if result.Reward >= 0 {
+		t.Fatalf("invalid action should be penalized, got reward %f", result.Reward)
+	}
+	if result.Done || result.Observation.CurrentTime <= 0 {
+		t.Fatalf("invalid action should wait, got time %f", result.Observation.CurrentTime)
+	}
+}
+
+func TestInteractiveModeOnlyControlsPlayers(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerMM())
+	rsr.SimOptions.Iterations = 1
+	rsr.SimOptions.Interactive = true
+
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+
+	// Without input the player only auto attacks, while the pet keeps using
+	// its own rotation.
+	player := result.RaidMetrics.Parties[0].Players[0]
+	if casts := castsBySpell(result); casts[arcaneShotID] != 0 {
+		t.Errorf("expected no Arcane Shots without input, got %d", casts[arcaneShotID])
+	}
+	petAbilityCasts := int32(0)
+	for _, action := range player.Pets[0].Actions {
+		if action.Id.GetSpellId() != 0 {
+			for _, target := range action.Targets {
+				petAbilityCasts += target.Casts
+			}
+		}
+	}
+	if petAbilityCasts == 0 {
+		t.Errorf("expected the pet to use its abilities in interactive mode")
+	}
+}
+
+// Pets keep their own rotations in the environment, so they still use their
+// abilities while the agent only waits.
+func TestRLEnvironmentPetsUseOwnRotation(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerMM())
+	rsr.Encounter.Duration = 60
+	rlEnv, err := core.NewRLEnvironment(&proto.RLEnvironmentSettings{Request: rsr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rlEnv.Reset(0)
+	for i := 0; i < 20; i++ {
+		if result := rlEnv.Step(rlEnv.NumActions() - 1); result.Done {
+			break
+		}
+	}
+	if casts := rlEnv.PetAbilityCasts(); casts == 0 {
+		t.Fatalf("expected the pet to use its abilities while the agent waits")
+	}
+}
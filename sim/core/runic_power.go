@@ -214,6 +214,82 @@ func (rp *runicPowerBar) SpendRunicPower(sim *Simulation, amount float64, metric
 	rp.spendRunicPower(sim, amount, metrics)
 }
 
+// Runic power has no passive regen, so TimeUntilRunicPower is NeverExpires
+// unless the unit already has enough.
+func (rp *runicPowerBar) TimeUntilRunicPower(sim *Simulation, amount float64) time.Duration {
+	return forecastTimeUntil(sim, rp.currentRunicPower, rp.maxRunicPower, amount)
+}
+
+func (rp *runicPowerBar) RunicPowerAt(_ *Simulation, _ time.Duration) float64 {
+	return rp.currentRunicPower
+}
+
+// Returns the runic power missing from the maximum.
+func (rp *runicPowerBar) RunicPowerDeficit() float64 {
+	return rp.maxRunicPower - rp.currentRunicPower
+}
+
+// Returns the times at which each rune of the pair starting at firstSlot will
+// be ready. Only one rune of a pair regenerates at a time, so a rune waiting
+// on the other starts its regen once the other is ready.
+func (rp *runicPowerBar) runePairReadyAt(sim *Simulation, firstSlot int8) [2]time.Duration {
+	var readyAt [2]time.Duration
+	for i := range readyAt {
+		slot := firstSlot + int8(i)
+		if rp.runeStates&isSpents[slot] == 0 {
+			readyAt[i] = sim.CurrentTime
+		} else {
+			readyAt[i] = rp.runeMeta[slot].regenAt
+		}
+	}
+
+	fullRegen := DurationFromSeconds(rp.runeCD.Seconds() * rp.getTotalRegenMultiplier())
+	if readyAt[0] == NeverExpires && readyAt[1] != NeverExpires {
+		readyAt[0] = readyAt[1] + fullRegen
+	} else if readyAt[1] == NeverExpires && readyAt[0] != NeverExpires {
+		readyAt[1] = readyAt[0] + fullRegen
+	}
+	return readyAt
+}
+
+// Returns the time until count runes of the pair starting at firstSlot are
+// ready, including death runes in those slots, or NeverExpires if they never
+// will be.
+func (rp *runicPowerBar) TimeUntilRunes(sim *Simulation, firstSlot int8, count int32) time.Duration {
+	if count <= 0 {
+		return 0
+	} else if count > 2 {
+		return NeverExpires
+	}
+
+	readyAt := rp.runePairReadyAt(sim, firstSlot)
+	at := min(readyAt[0], readyAt[1])
+	if count == 2 {
+		at = max(readyAt[0], readyAt[1])
+	}
+	if at == NeverExpires {
+		return NeverExpires
+	}
+	return at - sim.CurrentTime
+}
+
+// Returns the number of runes of the pair starting at firstSlot, including
+// death runes in those slots, that will be ready at the given time.
+func (rp *runicPowerBar) RunesAt(sim *Simulation, firstSlot int8, at time.Duration) int32 {
+	count := int32(0)
+	for _, readyAt := range rp.runePairReadyAt(sim, firstSlot) {
+		if readyAt <= at {
+			count++
+		}
+	}
+	return count
+}
+
+// Returns the number of spent runes of the pair starting at firstSlot.
+func (rp *runicPowerBar) RuneDeficit(firstSlot int8) int32 {
+	return int32(2 - rs2cd[(rp.runeStates>>(2*firstSlot))&0b0101])
+}
+
 // DeathRuneRegenAt returns the time the given death rune will regen at.
 // If the rune is not death or not spent it returns NeverExpires.
 func (rp *runicPowerBar) DeathRuneRegenAt(slot int32) time.Duration {
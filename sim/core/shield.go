@@ -70,6 +70,22 @@ func (spell *Spell) createShields(config ShieldConfig) {
 		auraConfig.ActionID = shield.Spell.ActionID
 	}
 
+	// Unabsorbable damage bypasses the shield.
+	if onSpellHitTaken := auraConfig.OnSpellHitTaken; onSpellHitTaken != nil {
+		auraConfig.OnSpellHitTaken = func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+			if !spell.Flags.Matches(SpellFlagCannotBeAbsorbed) {
+				onSpellHitTaken(aura, sim, spell, result)
+			}
+		}
+	}
+	if onPeriodicDamageTaken := auraConfig.OnPeriodicDamageTaken; onPeriodicDamageTaken != nil {
+		auraConfig.OnPeriodicDamageTaken = func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
+			if !spell.Flags.Matches(SpellFlagCannotBeAbsorbed) {
+				onPeriodicDamageTaken(aura, sim, spell, result)
+			}
+		}
+	}
+
 	caster := shield.Spell.Unit
 	if config.SelfOnly {
 		shield.Aura = caster.GetOrRegisterAura(auraConfig)
@@ -46,8 +46,9 @@ type Simulation struct {
 
 	executePhaseCallbacks []func(*Simulation, int32) // 2nd parameter is 35 for 35%, 25 for 25% and 20 for 20%
 
-	nextExecuteDuration time.Duration
-	nextExecuteDamage   float64
+	healthThresholds        []*healthThreshold
+	healthThresholdReached  bool // Set when a target takes enough damage to cross a threshold in health-based fights.
+	nextHealthThresholdTime time.Duration
 
 	endOfCombatDuration time.Duration
 	endOfCombatDamage   float64
@@ -60,6 +61,9 @@ type Simulation struct {
 
 	minTaskTime time.Duration
 	tasks       []Task
+
+	// Shared mana regen tick, nil if no unit uses mana.
+	manaTickAction *PendingAction
 }
 
 func (sim *Simulation) rescheduleTracker(trackerTime time.Duration) {
@@ -109,7 +113,15 @@ func RunSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, qui
 	return runSim(rsr, progress, false, quitSignal)
 }
 
-func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool, quitSignal chan bool) (result *proto.RaidSimResult) {
+func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool, quitSignal chan bool) *proto.RaidSimResult {
+	return runSimWithEnv(&Environment{}, rsr, progress, skipPresim, quitSignal)
+}
+
+// Same as runSim, but runs in the given environment. A new environment is
+// built from rsr. An environment which was already finalized is reused as-is,
+// including the effects of its presims, which skips the cost of constructing
+// it again. Callers must make sure a reused environment matches rsr.
+func runSimWithEnv(env *Environment, rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool, quitSignal chan bool) (result *proto.RaidSimResult) {
 	if !rsr.SimOptions.IsTest {
 		defer func() {
 			if err := recover(); err != nil {
@@ -137,13 +149,20 @@ func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, ski
 		}()
 	}
 
-	sim := NewSim(rsr)
+	// A reused environment already had the effects of its presims applied.
+	reused := env.IsFinalized()
+	if reused {
+		env.clearMetrics()
+	} else {
+		env.build(rsr.Raid, rsr.Encounter, false)
+	}
+	sim := newSimWithEnv(env, rsr.SimOptions)
 
 	if quitSignal != nil {
 		sim.QuitChannel = quitSignal
 	}
 
-	if !skipPresim {
+	if !skipPresim && !reused {
 		if progress != nil {
 			progress <- &proto.ProgressMetrics{
 				TotalIterations: sim.Options.Iterations,
@@ -166,9 +185,6 @@ func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, ski
 				TotalIterations: sim.Options.Iterations,
 				PresimRunning:   false,
 			}
-			sim.ProgressReport = func(progMetric *proto.ProgressMetrics) {
-				progress <- progMetric
-			}
 			runtime.Gosched() // allow time for message to make it back out.
 		}
 		// Use pre-sim as estimate for length of fight (when using health fight)
@@ -179,6 +195,12 @@ func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, ski
 		}
 	}
 
+	if !skipPresim && progress != nil {
+		sim.ProgressReport = func(progMetric *proto.ProgressMetrics) {
+			progress <- progMetric
+		}
+	}
+
 	// using a variable here allows us to mutate it in the deferred recover, sending out error info
 	result = sim.run()
 
@@ -233,7 +255,10 @@ func (sim *Simulation) labelRand(label string) Rand {
 }
 
 func (sim *Simulation) reseedRands(i int64) {
-	rseed := sim.Options.RandomSeed + i
+	sim.seedRands(sim.Options.RandomSeed + i)
+}
+
+func (sim *Simulation) seedRands(rseed int64) {
 	sim.rand.Seed(rseed)
 
 	if sim.isTest {
@@ -282,6 +307,10 @@ func (sim *Simulation) Reseed(seed int64) {
 // Run runs the simulation for the configured number of iterations, and
 // collects all the metrics together.
 func (sim *Simulation) run() *proto.RaidSimResult {
+	if sim.Options.ReplayIteration {
+		return sim.runReplay()
+	}
+
 	t0 := time.Now()
 
 	logsBuffer := &strings.Builder{}
@@ -363,6 +392,44 @@ func (sim *Simulation) run() *proto.RaidSimResult {
 	return result
 }
 
+// Runs only the iteration seeded with Options.ReplaySeed, with debug logging.
+// The seeds and label RNGs are set up exactly as in a normal run, so the
+// result matches that iteration of the original sim.
+func (sim *Simulation) runReplay() *proto.RaidSimResult {
+	// Health based encounters estimate their duration from the first iteration.
+	if sim.Encounter.DurationIsEstimate && sim.Options.ReplaySeed != sim.rseed {
+		sim.runOnce()
+		sim.Environment.clearMetrics()
+	}
+
+	logsBuffer := &strings.Builder{}
+	sim.Log = func(message string, vals ...interface{}) {
+		logsBuffer.WriteString(fmt.Sprintf("[%0.2f] "+message+"\n", append([]interface{}{sim.CurrentTime.Seconds()}, vals...)...))
+	}
+
+	sim.seedRands(sim.Options.ReplaySeed)
+	sim.runOnce()
+	duration := sim.Duration
+	if sim.Encounter.EndFightAtHealth != 0 {
+		duration = sim.CurrentTime
+	}
+
+	result := &proto.RaidSimResult{
+		RaidMetrics:      sim.Raid.GetMetrics(),
+		EncounterMetrics: sim.Encounter.GetMetricsProto(),
+
+		Logs:                   logsBuffer.String(),
+		FirstIterationDuration: duration.Seconds(),
+		AvgIterationDuration:   duration.Seconds(),
+	}
+
+	if sim.ProgressReport != nil {
+		sim.ProgressReport(&proto.ProgressMetrics{TotalIterations: 1, CompletedIterations: 1, Dps: result.RaidMetrics.Dps.Avg, FinalRaidResult: result})
+	}
+
+	return result
+}
+
 // RunOnce is the main event loop. It will run the simulation for number of seconds.
 func (sim *Simulation) runOnce() {
 	sim.reset()
@@ -396,9 +463,8 @@ func (sim *Simulation) reset() {
 	sim.pendingActions = sim.pendingActions[:0]
 	sim.pendingActions = append(sim.pendingActions, sentinelPendingAction)
 
-	sim.executePhase = 0
-	sim.nextExecutePhase()
 	sim.executePhaseCallbacks = nil
+	sim.resetHealthThresholds()
 
 	// Use duration as an end check if not using health.
 	sim.endOfCombatDuration = sim.Duration
@@ -553,13 +619,8 @@ func (sim *Simulation) advanceTasks() {
 func (sim *Simulation) advance(nextTime time.Duration) {
 	sim.CurrentTime = nextTime
 
-	// this is a loop to handle duplicate ExecuteProportions, e.g. if they're all set to 100%, you reach
-	// execute phases 35%, 25%, and 20% in the first advance() call.
-	for sim.CurrentTime >= sim.nextExecuteDuration || sim.Encounter.DamageTaken >= sim.nextExecuteDamage {
-		sim.nextExecutePhase()
-		for _, callback := range sim.executePhaseCallbacks {
-			callback(sim, sim.executePhase)
-		}
+	if sim.CurrentTime >= sim.nextHealthThresholdTime || sim.healthThresholdReached {
+		sim.processHealthThresholds()
 	}
 
 	if sim.CurrentTime >= sim.minTrackerTime {
@@ -569,42 +630,6 @@ func (sim *Simulation) advance(nextTime time.Duration) {
 		}
 	}
 }
-func (sim *Simulation) setupReverseExecute(phase int32, activeProportion float64) {
-	sim.executePhase = phase
-	// This calculates the duration for which the phase is active from the start
-	sim.nextExecuteDuration = time.Duration(activeProportion * float64(sim.Duration))
-}
-
-// nextExecutePhase updates nextExecuteDuration and nextExecuteDamage based on executePhase.
-func (sim *Simulation) nextExecutePhase() {
-	setup := func(phase int32, damage float64, health float64) {
-		sim.executePhase = phase
-		if sim.Encounter.EndFightAtHealth > 0 {
-			sim.nextExecuteDamage = (1 - damage) * sim.Encounter.EndFightAtHealth
-		} else {
-			sim.nextExecuteDuration = time.Duration((1 - health) * float64(sim.Duration))
-		}
-	}
-
-	sim.nextExecuteDuration = NeverExpires
-	sim.nextExecuteDamage = math.MaxFloat64
-
-	switch sim.executePhase {
-	case 0: // initially waiting for 90%
-		setup(100, 0.90, sim.Encounter.ExecuteProportion_90)
-	case 100: // at 90%, waiting for 35%
-		setup(90, 0.35, sim.Encounter.ExecuteProportion_35)
-	case 90: // at 35%, waiting for 25%
-		setup(35, 0.25, sim.Encounter.ExecuteProportion_25)
-	case 35: // at 25%, waiting for 20%
-		setup(25, 0.20, sim.Encounter.ExecuteProportion_20)
-	case 25: // at 20%, done waiting
-		sim.executePhase = 20 // could also be used for end of fight handling
-	default:
-		panic(fmt.Sprintf("executePhase = %d invalid", sim.executePhase))
-	}
-}
-
 func (sim *Simulation) AddPendingAction(pa *PendingAction) {
 	//if pa.NextActionAt < sim.CurrentTime {
 	//	panic(fmt.Sprintf("Cant add action in the past: %s", pa.NextActionAt))
@@ -0,0 +1,42 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+func TestReplayIteration(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerFeralCat())
+	rsr.SimOptions.Iterations = 20
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatal(result.ErrorResult)
+	}
+	dps := result.RaidMetrics.Dps
+
+	for _, expected := range []struct {
+		seed int64
+		dps  float64
+	}{
+		{seed: dps.MinSeed, dps: dps.Min},
+		{seed: dps.MaxSeed, dps: dps.Max},
+	} {
+		replayRsr := googleProto.Clone(rsr).(*proto.RaidSimRequest)
+		replayRsr.SimOptions.ReplayIteration = true
+		replayRsr.SimOptions.ReplaySeed = expected.seed
+
+		replay := core.RunConcurrentRaidSimSync(replayRsr)
+		if replay.ErrorResult != "" {
+			t.Fatal(replay.ErrorResult)
+		}
+		if replay.RaidMetrics.Dps.Avg != expected.dps {
+			t.Fatalf("replaying seed %d gave %f dps, expected %f", expected.seed, replay.RaidMetrics.Dps.Avg, expected.dps)
+		}
+		if replay.Logs == "" {
+			t.Fatalf("replay should include the debug log")
+		}
+	}
+}
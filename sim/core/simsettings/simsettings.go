@@ -0,0 +1,393 @@
+// Package simsettings converts between the settings the UI imports and
+// exports (share links, JSON exports) and the requests the sim runs.
+package simsettings
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// Defaults used by the UI when the settings don't specify them.
+const (
+	DefaultIterations = 3000
+	maxRngSeed        = 1<<32 - 1
+)
+
+// Builds the request the individual sim UI sends when simming these settings.
+func IndividualToRequest(settings *proto.IndividualSimSettings) (*proto.RaidSimRequest, error) {
+	if settings.GetPlayer() == nil {
+		return nil, errors.New("individual sim settings have no player")
+	}
+	if err := validatePlayer("player", settings.Player); err != nil {
+		return nil, err
+	}
+
+	settings = goproto.Clone(settings).(*proto.IndividualSimSettings)
+	return &proto.RaidSimRequest{
+		Type: proto.SimType_SimTypeIndividual,
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{settings.Player},
+					Buffs:   settings.PartyBuffs,
+				},
+			},
+			Buffs:         settings.RaidBuffs,
+			Debuffs:       settings.Debuffs,
+			Tanks:         settings.Tanks,
+			TargetDummies: settings.TargetDummies,
+		},
+		Encounter:  settings.Encounter,
+		SimOptions: simOptions(settings.Settings),
+	}, nil
+}
+
+// Builds the request the raid sim UI sends when simming these settings,
+// including the raid buffs from blessings assignments.
+func RaidToRequest(settings *proto.RaidSimSettings) (*proto.RaidSimRequest, error) {
+	if settings.GetRaid() == nil {
+		return nil, errors.New("raid sim settings have no raid")
+	}
+
+	settings = goproto.Clone(settings).(*proto.RaidSimSettings)
+	raid := settings.Raid
+	var errs []error
+	for i, party := range raid.Parties {
+		for j, player := range party.Players {
+			if player.Class == proto.Class_ClassUnknown {
+				continue
+			}
+			errs = append(errs, validatePlayer(fmt.Sprintf("raid.parties[%d].players[%d]", i, j), player))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	applyBlessings(raid, settings.Blessings)
+	return &proto.RaidSimRequest{
+		Type:       proto.SimType_SimTypeRaid,
+		Raid:       raid,
+		Encounter:  settings.Encounter,
+		SimOptions: simOptions(settings.Settings),
+	}, nil
+}
+
+// Converts either kind of settings to a request.
+func ToRequest(settings goproto.Message) (*proto.RaidSimRequest, error) {
+	switch settings := settings.(type) {
+	case *proto.IndividualSimSettings:
+		return IndividualToRequest(settings)
+	case *proto.RaidSimSettings:
+		return RaidToRequest(settings)
+	}
+	return nil, fmt.Errorf("unsupported settings type %T", settings)
+}
+
+// Handles a ConvertSettingsRequest, reporting invalid settings in the result.
+func Convert(request *proto.ConvertSettingsRequest) *proto.ConvertSettingsResult {
+	result := &proto.ConvertSettingsResult{}
+	var err error
+	switch settings := request.Settings.(type) {
+	case *proto.ConvertSettingsRequest_IndividualSettings:
+		result.Request, err = IndividualToRequest(settings.IndividualSettings)
+		if err == nil {
+			result.StatWeightsRequest, err = IndividualToStatWeightsRequest(settings.IndividualSettings)
+		}
+	case *proto.ConvertSettingsRequest_RaidSettings:
+		result.Request, err = RaidToRequest(settings.RaidSettings)
+	default:
+		err = errors.New("no settings to convert")
+	}
+	if err != nil {
+		return &proto.ConvertSettingsResult{ErrorResult: err.Error()}
+	}
+	return result
+}
+
+// Builds the stat weights request the individual sim UI sends for these
+// settings. The stats with a non-zero EP weight are the ones weighed.
+func IndividualToStatWeightsRequest(settings *proto.IndividualSimSettings) (*proto.StatWeightsRequest, error) {
+	request, err := IndividualToRequest(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var tanks []*proto.UnitReference
+	for _, tank := range settings.Tanks {
+		if tank.Type == proto.UnitReference_Player && tank.Index == 0 {
+			tanks = []*proto.UnitReference{{Type: proto.UnitReference_Player, Index: 0}}
+		}
+	}
+
+	swr := &proto.StatWeightsRequest{
+		Player:          request.Raid.Parties[0].Players[0],
+		RaidBuffs:       request.Raid.Buffs,
+		PartyBuffs:      request.Raid.Parties[0].Buffs,
+		Debuffs:         request.Raid.Debuffs,
+		Encounter:       request.Encounter,
+		SimOptions:      request.SimOptions,
+		Tanks:           tanks,
+		EpReferenceStat: settings.GetDpsRefStat(),
+	}
+	for stat, weight := range settings.GetEpWeightsStats().GetStats() {
+		if weight != 0 {
+			swr.StatsToWeigh = append(swr.StatsToWeigh, proto.Stat(stat))
+		}
+	}
+	for pseudoStat, weight := range settings.GetEpWeightsStats().GetPseudoStats() {
+		if weight != 0 {
+			swr.PseudoStatsToWeigh = append(swr.PseudoStatsToWeigh, proto.PseudoStat(pseudoStat))
+		}
+	}
+	return swr, nil
+}
+
+// Builds the settings a share link for this request contains. Requests with a
+// single player become individual sim settings, like the individual sim UIs
+// export, and anything else raid sim settings.
+func RequestToSettings(request *proto.RaidSimRequest) goproto.Message {
+	request = goproto.Clone(request).(*proto.RaidSimRequest)
+	simSettings := &proto.SimSettings{
+		Iterations:   request.GetSimOptions().GetIterations(),
+		FixedRngSeed: request.GetSimOptions().GetRandomSeed(),
+	}
+
+	raid := request.GetRaid()
+	var players []*proto.Player
+	var partyBuffs *proto.PartyBuffs
+	for _, party := range raid.GetParties() {
+		for _, player := range party.Players {
+			if player.Class != proto.Class_ClassUnknown {
+				players = append(players, player)
+				partyBuffs = party.Buffs
+			}
+		}
+	}
+
+	if len(players) == 1 && request.Type != proto.SimType_SimTypeRaid {
+		return &proto.IndividualSimSettings{
+			Settings:      simSettings,
+			RaidBuffs:     raid.Buffs,
+			Debuffs:       raid.Debuffs,
+			Tanks:         raid.Tanks,
+			PartyBuffs:    partyBuffs,
+			Player:        players[0],
+			Encounter:     request.Encounter,
+			TargetDummies: raid.TargetDummies,
+		}
+	}
+
+	return &proto.RaidSimSettings{
+		Settings:  simSettings,
+		Raid:      raid,
+		Encounter: request.Encounter,
+	}
+}
+
+// Encodes settings the same way the UI's link exporters do, i.e. the part of
+// a share link after the '#'.
+func Encode(settings goproto.Message) (string, error) {
+	data, err := goproto.Marshal(settings)
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal settings: %w", err)
+	}
+
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return "", fmt.Errorf("cannot compress settings: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("cannot compress settings: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Decodes settings encoded by Encode or the UI into the given message.
+func Decode(encoded string, settings goproto.Message) error {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("cannot decode proto from link: %w", err)
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("cannot create zlib reader: %w", err)
+	}
+	defer r.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return fmt.Errorf("reading zlib data failed: %w", err)
+	}
+
+	if err := goproto.Unmarshal(buf.Bytes(), settings); err != nil {
+		return fmt.Errorf("cannot unmarshal raw proto: %w", err)
+	}
+	return nil
+}
+
+// Decodes a share link into the settings it contains, RaidSimSettings for
+// raid sim links and IndividualSimSettings otherwise.
+func DecodeLink(link string) (goproto.Message, error) {
+	_, encoded, ok := strings.Cut(link, "#")
+	if !ok || encoded == "" || strings.Contains(encoded, "#") {
+		return nil, errors.New("invalid wowsims export link")
+	}
+
+	var settings goproto.Message
+	if strings.Contains(link, "/raid/") {
+		settings = &proto.RaidSimSettings{}
+	} else {
+		settings = &proto.IndividualSimSettings{}
+	}
+	if err := Decode(encoded, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func simOptions(settings *proto.SimSettings) *proto.SimOptions {
+	iterations := settings.GetIterations()
+	if iterations <= 0 {
+		iterations = DefaultIterations
+	}
+	seed := settings.GetFixedRngSeed()
+	if seed == 0 {
+		seed = rand.Int63n(maxRngSeed)
+	}
+	return &proto.SimOptions{
+		Iterations: iterations,
+		RandomSeed: seed,
+	}
+}
+
+// Blessings are assigned per paladin and spec, but only paladins actually in
+// the raid can cast them.
+func applyBlessings(raid *proto.Raid, blessings *proto.BlessingsAssignments) {
+	specs := make(map[proto.Spec]bool)
+	numPaladins := 0
+	for i, party := range raid.Parties {
+		if raid.NumActiveParties > 0 && int32(i) >= raid.NumActiveParties {
+			break
+		}
+		for _, player := range party.Players {
+			if player.Class == proto.Class_ClassUnknown {
+				continue
+			}
+			specs[PlayerSpec(player)] = true
+			if player.Class == proto.Class_ClassPaladin {
+				numPaladins++
+			}
+		}
+	}
+
+	for i, paladin := range blessings.GetPaladins() {
+		if i >= numPaladins {
+			break
+		}
+		for spec, blessing := range paladin.Blessings {
+			if !specs[proto.Spec(spec)] {
+				continue
+			}
+			if raid.Buffs == nil {
+				raid.Buffs = &proto.RaidBuffs{}
+			}
+			switch blessing {
+			case proto.Blessings_BlessingOfKings:
+				raid.Buffs.BlessingOfKings = true
+			case proto.Blessings_BlessingOfMight:
+				raid.Buffs.BlessingOfMight = true
+			}
+		}
+	}
+}
+
+// Returns the spec of the player's spec options, without requiring the spec
+// to be registered with the sim.
+func PlayerSpec(player *proto.Player) proto.Spec {
+	specField := player.ProtoReflect().WhichOneof(player.ProtoReflect().Descriptor().Oneofs().ByName("spec"))
+	if specField == nil {
+		return proto.Spec_SpecUnknown
+	}
+	return proto.Spec(proto.Spec_value["Spec"+string(specField.Message().Name())])
+}
+
+// Checks that the player's spec matches its class and that all of its gear
+// exists, either in the sim's database or the player's own.
+func validatePlayer(path string, player *proto.Player) error {
+	var errs []error
+
+	spec := PlayerSpec(player)
+	if spec == proto.Spec_SpecUnknown {
+		errs = append(errs, fmt.Errorf("%s: player has no spec", path))
+	} else if specClass := core.SpecClass(spec); specClass != player.Class {
+		errs = append(errs, fmt.Errorf("%s: spec %s doesn't match class %s", path, spec, player.Class))
+	}
+
+	db := player.GetDatabase()
+	hasItem := func(id int32) bool {
+		if _, ok := core.ItemsByID[id]; ok {
+			return true
+		}
+		for _, item := range db.GetItems() {
+			if item.Id == id {
+				return true
+			}
+		}
+		return false
+	}
+	hasEnchant := func(effectID int32) bool {
+		if _, ok := core.EnchantsByEffectID[effectID]; ok {
+			return true
+		}
+		for _, enchant := range db.GetEnchants() {
+			if enchant.EffectId == effectID {
+				return true
+			}
+		}
+		return false
+	}
+	hasGem := func(id int32) bool {
+		if _, ok := core.GemsByID[id]; ok {
+			return true
+		}
+		for _, gem := range db.GetGems() {
+			if gem.Id == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i, item := range player.GetEquipment().GetItems() {
+		if item.Id == 0 {
+			continue
+		}
+		itemPath := fmt.Sprintf("%s.equipment.items[%d]", path, i)
+		if !hasItem(item.Id) {
+			errs = append(errs, fmt.Errorf("%s: unknown item id %d", itemPath, item.Id))
+		}
+		if item.Enchant != 0 && !hasEnchant(item.Enchant) {
+			errs = append(errs, fmt.Errorf("%s: unknown enchant id %d", itemPath, item.Enchant))
+		}
+		for j, gem := range item.Gems {
+			if gem != 0 && !hasGem(gem) {
+				errs = append(errs, fmt.Errorf("%s.gems[%d]: unknown gem id %d", itemPath, j, gem))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
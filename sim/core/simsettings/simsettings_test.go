@@ -0,0 +1,119 @@
+package simsettings
+
+import (
+	"strings"
+	"testing"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// Items are added to the player's own database so the tests don't depend on
+// the sim's item database.
+func testPlayer(class proto.Class, itemID int32) *proto.Player {
+	player := &proto.Player{
+		Name:  "Tester",
+		Class: class,
+		Equipment: &proto.EquipmentSpec{
+			Items: []*proto.ItemSpec{{Id: itemID}},
+		},
+		Database: &proto.SimDatabase{
+			Items: []*proto.SimItem{{Id: 1}},
+		},
+	}
+	switch class {
+	case proto.Class_ClassDruid:
+		player.Spec = &proto.Player_FeralDruid{FeralDruid: &proto.FeralDruid{}}
+	case proto.Class_ClassPaladin:
+		player.Spec = &proto.Player_RetributionPaladin{RetributionPaladin: &proto.RetributionPaladin{}}
+	}
+	return player
+}
+
+func TestIndividualRoundTrip(t *testing.T) {
+	settings := &proto.IndividualSimSettings{
+		Settings:      &proto.SimSettings{Iterations: 100, FixedRngSeed: 42},
+		RaidBuffs:     &proto.RaidBuffs{BlessingOfKings: true},
+		PartyBuffs:    &proto.PartyBuffs{},
+		Debuffs:       &proto.Debuffs{},
+		Player:        testPlayer(proto.Class_ClassDruid, 1),
+		Encounter:     &proto.Encounter{Duration: 180},
+		TargetDummies: 2,
+	}
+
+	request, err := IndividualToRequest(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.SimOptions.Iterations != 100 || request.SimOptions.RandomSeed != 42 {
+		t.Fatalf("sim options not taken from settings: %v", request.SimOptions)
+	}
+	if request.Raid.TargetDummies != 2 {
+		t.Fatalf("expected 2 target dummies, got %d", request.Raid.TargetDummies)
+	}
+
+	encoded, err := Encode(RequestToSettings(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeLink("https://wowsims.github.io/cata/druid/feral/#" + encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !goproto.Equal(decoded, settings) {
+		t.Fatalf("round trip changed settings:\n%v\n%v", decoded, settings)
+	}
+}
+
+func TestRaidBlessings(t *testing.T) {
+	blessings := make([]proto.Blessings, len(proto.Spec_name))
+	blessings[proto.Spec_SpecFeralDruid] = proto.Blessings_BlessingOfMight
+	settings := &proto.RaidSimSettings{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{{Players: []*proto.Player{testPlayer(proto.Class_ClassDruid, 1)}}},
+		},
+		Blessings: &proto.BlessingsAssignments{
+			Paladins: []*proto.BlessingsAssignment{{Blessings: blessings}},
+		},
+	}
+
+	// Blessings need a paladin in the raid to cast them.
+	request, err := RaidToRequest(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Raid.GetBuffs().GetBlessingOfMight() {
+		t.Fatalf("blessing applied without a paladin")
+	}
+
+	settings.Raid.Parties[0].Players = append(settings.Raid.Parties[0].Players, testPlayer(proto.Class_ClassPaladin, 1))
+	request, err = RaidToRequest(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !request.Raid.GetBuffs().GetBlessingOfMight() || request.Raid.GetBuffs().GetBlessingOfKings() {
+		t.Fatalf("expected only blessing of might, got %v", request.Raid.Buffs)
+	}
+	if settings.Raid.Buffs != nil {
+		t.Fatalf("converting modified the settings")
+	}
+}
+
+func TestValidation(t *testing.T) {
+	player := testPlayer(proto.Class_ClassMage, 999999)
+	player.Spec = &proto.Player_FeralDruid{FeralDruid: &proto.FeralDruid{}}
+
+	_, err := IndividualToRequest(&proto.IndividualSimSettings{Player: player})
+	if err == nil {
+		t.Fatalf("expected validation errors")
+	}
+	for _, expected := range []string{
+		"player: spec SpecFeralDruid doesn't match class ClassMage",
+		"player.equipment.items[0]: unknown item id 999999",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error %q, got %q", expected, err)
+		}
+	}
+}
@@ -112,6 +112,8 @@ type Spell struct {
 	splitSpellMetrics [][]SpellMetrics // Used to split metrics by some condition.
 	casts             int              // Sum of casts on all targets, for efficient CPM calculation
 
+	damageTakenMetrics []*DamageTakenMetrics // Cached damage taken metrics, indexed by target
+
 	// Performs the actions of this spell.
 	ApplyEffects ApplySpellResults
 
@@ -303,7 +305,7 @@ func (unit *Unit) RegisterSpell(config SpellConfig) *Spell {
 		spell.MaxRange = config.MaxRange
 		oldExtraCastCondition := spell.ExtraCastCondition
 		spell.ExtraCastCondition = func(sim *Simulation, target *Unit) bool {
-			if ((spell.MinRange != 0) && (spell.Unit.DistanceFromTarget < spell.MinRange)) || ((spell.MaxRange != 0) && (spell.Unit.DistanceFromTarget > spell.MaxRange)) {
+			if spell.outOfRange() {
 				if sim.Log != nil {
 					sim.Log("Cannot cast spell %s, out of range!", spell.ActionID)
 				}
@@ -605,6 +607,51 @@ func (spell *Spell) ExpectedTickDamageFromCurrentSnapshot(sim *Simulation, targe
 	return result.Damage
 }
 
+// Whether this spell has an expected damage calculation, for ExpectedDamage().
+func (spell *Spell) HasExpectedDamage() bool {
+	return spell.expectedInitialDamageInternal != nil || spell.expectedTickDamageInternal != nil
+}
+
+// Returns the dot this spell would apply to the target, if any.
+func (spell *Spell) expectedDamageDot(target *Unit) *Dot {
+	if dot := spell.Dot(target); dot != nil {
+		return dot
+	}
+	return spell.AOEDot()
+}
+
+// Expected damage of casting this spell on the target right now, including
+// every tick of a fresh application of its dot. Has no side effects.
+func (spell *Spell) ExpectedDamage(sim *Simulation, target *Unit) float64 {
+	damage := 0.0
+	if spell.expectedInitialDamageInternal != nil {
+		damage += spell.ExpectedInitialDamage(sim, target)
+	}
+	if spell.expectedTickDamageInternal != nil {
+		if dot := spell.expectedDamageDot(target); dot != nil {
+			damage += spell.ExpectedTickDamage(sim, target) * float64(dot.expectedTickCount())
+		}
+	}
+	return damage
+}
+
+// Time the unit is kept busy by casting this spell right now, i.e. the
+// longest of the GCD, cast time and channel duration.
+func (spell *Spell) ExpectedExecuteTime(target *Unit) time.Duration {
+	executeTime := spell.EffectiveCastTime()
+	if spell.Flags.Matches(SpellFlagChanneled) {
+		if dot := spell.expectedDamageDot(target); dot != nil {
+			executeTime = max(executeTime, dot.expectedTickPeriod()*time.Duration(dot.expectedTickCount()))
+		}
+	}
+	return executeTime
+}
+
+// Whether the unit is outside this spell's range constraints, if it has any.
+func (spell *Spell) outOfRange() bool {
+	return ((spell.MinRange != 0) && (spell.Unit.DistanceFromTarget < spell.MinRange)) || ((spell.MaxRange != 0) && (spell.Unit.DistanceFromTarget > spell.MaxRange))
+}
+
 // Time until either the cast is finished or GCD is ready again, whichever is longer
 func (spell *Spell) EffectiveCastTime() time.Duration {
 	// TODO: this is wrong for spells like shadowfury, that have a GCD of less than 1s
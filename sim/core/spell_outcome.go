@@ -372,6 +372,40 @@ func (spell *Spell) OutcomeEnemyMeleeWhite(sim *Simulation, result *SpellResult,
 	}
 }
 
+// Enemy special attacks can be avoided, but never crit and ignore the dual wield miss penalty.
+func (spell *Spell) OutcomeEnemyMeleeSpecialHit(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
+	roll := sim.RandomFloat("Enemy Special Hit Table")
+	chance := 0.0
+
+	if !result.applyEnemyAttackTableMissNoDWPenalty(spell, attackTable, roll, &chance) &&
+		!result.applyEnemyAttackTableDodge(spell, attackTable, roll, &chance) &&
+		!result.applyEnemyAttackTableParry(spell, attackTable, roll, &chance) &&
+		!result.applyEnemyAttackTableBlock(sim, spell, attackTable, roll, &chance) {
+		result.applyAttackTableHit(spell)
+	}
+}
+
+func (spell *Spell) OutcomeEnemyMeleeSpecialHitNoBlock(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
+	roll := sim.RandomFloat("Enemy Special Hit Table")
+	chance := 0.0
+
+	if !result.applyEnemyAttackTableMissNoDWPenalty(spell, attackTable, roll, &chance) &&
+		!result.applyEnemyAttackTableDodge(spell, attackTable, roll, &chance) &&
+		!result.applyEnemyAttackTableParry(spell, attackTable, roll, &chance) {
+		result.applyAttackTableHit(spell)
+	}
+}
+
+// For enemy abilities which always land, but can still be blocked (e.g. blockable spells).
+func (spell *Spell) OutcomeEnemyBlockOnly(sim *Simulation, result *SpellResult, attackTable *AttackTable) {
+	roll := sim.RandomFloat("Enemy Block Roll")
+	chance := 0.0
+
+	if !result.applyEnemyAttackTableBlock(sim, spell, attackTable, roll, &chance) {
+		result.applyAttackTableHit(spell)
+	}
+}
+
 func (spell *Spell) fixedCritCheck(sim *Simulation, critChance float64) bool {
 	return sim.RandomFloat("Fixed Crit Roll") < critChance
 }
@@ -527,6 +561,19 @@ func (result *SpellResult) applyEnemyAttackTableMiss(spell *Spell, attackTable *
 	return false
 }
 
+func (result *SpellResult) applyEnemyAttackTableMissNoDWPenalty(spell *Spell, attackTable *AttackTable, roll float64, chance *float64) bool {
+	missChance := result.Target.GetTotalChanceToBeMissedAsDefender(attackTable) + spell.Unit.PseudoStats.IncreasedMissChance
+	*chance += max(0, missChance)
+
+	if roll < *chance {
+		result.Outcome = OutcomeMiss
+		spell.SpellMetrics[result.Target.UnitIndex].Misses++
+		result.Damage = 0
+		return true
+	}
+	return false
+}
+
 func (result *SpellResult) applyEnemyAttackTableBlock(sim *Simulation, spell *Spell, attackTable *AttackTable, roll float64, chance *float64) bool {
 	if !result.Target.PseudoStats.CanBlock || result.Target.PseudoStats.Stunned {
 		return false
@@ -68,12 +68,16 @@ func (at *AttackTable) GetArmorDamageModifier(spell *Spell) float64 {
 		return 1.0
 	}
 
-	// Assume target > 80
-	armorConstant := float64(at.Attacker.Level)*2167.5 - 158167.5
+	armorConstant := armorConstant(at.Attacker)
 	defenderArmor := at.Defender.Armor()
 	return 1 - defenderArmor/(defenderArmor+armorConstant)
 }
 
+// Armor constant for attacks from attacker, assuming a target above level 80.
+func armorConstant(attacker *Unit) float64 {
+	return float64(attacker.Level)*2167.5 - 158167.5
+}
+
 /*
  The following calculations are based on
  https://web.archive.org/web/20110207221537/http://elitistjerks.com/f15/t44675-resistance_mechanics_wotlk/
@@ -19,6 +19,12 @@ type SpellResult struct {
 	ResistanceMultiplier float64 // Partial Resists / Armor multiplier
 	PreOutcomeDamage     float64 // Damage done by this cast before Outcome is applied
 
+	// Damage after each calculation step, for damage taken metrics.
+	afterAttackerMods float64
+	afterResistances  float64
+	afterTargetMods   float64
+	afterOutcome      float64
+
 	inUse bool
 }
 
@@ -32,6 +38,10 @@ func (spell *Spell) NewResult(target *Unit) *SpellResult {
 	result.Damage = 0
 	result.Threat = 0
 	result.Outcome = OutcomeEmpty // for blocks
+	result.afterAttackerMods = 0
+	result.afterResistances = 0
+	result.afterTargetMods = 0
+	result.afterOutcome = 0
 	result.inUse = true
 
 	return result
@@ -190,8 +200,11 @@ func (spell *Spell) calcDamageInternal(sim *Simulation, target *Unit, baseDamage
 
 	if sim.Log == nil {
 		result.Damage *= attackerMultiplier
+		result.afterAttackerMods = result.Damage
 		result.applyResistances(sim, spell, isPeriodic, attackTable)
+		result.afterResistances = result.Damage
 		result.applyTargetModifiers(sim, spell, attackTable, isPeriodic)
+		result.afterTargetMods = result.Damage
 
 		// Save partial outcome which comes from applyResistances call
 		partialOutcome := OutcomeEmpty
@@ -200,6 +213,7 @@ func (spell *Spell) calcDamageInternal(sim *Simulation, target *Unit, baseDamage
 		}
 
 		outcomeApplier(sim, result, attackTable)
+		result.afterOutcome = result.Damage
 
 		// Restore partial outcome
 		if partialOutcome != OutcomeEmpty {
@@ -224,6 +238,11 @@ func (spell *Spell) calcDamageInternal(sim *Simulation, target *Unit, baseDamage
 		outcomeApplier(sim, result, attackTable)
 		afterOutcome := result.Damage
 
+		result.afterAttackerMods = afterAttackMods
+		result.afterResistances = afterResistances
+		result.afterTargetMods = afterTargetMods
+		result.afterOutcome = afterOutcome
+
 		// Restore partial outcome
 		if partialOutcome != OutcomeEmpty {
 			result.Outcome |= partialOutcome
@@ -286,7 +305,9 @@ func (spell *Spell) dealDamageInternal(sim *Simulation, isPeriodic bool, result
 	// Mark total damage done in raid so far for health based fights.
 	// Don't include damage done by EnemyUnits to Players
 	if result.Target.Type == EnemyUnit {
-		sim.Encounter.DamageTaken += result.Damage
+		sim.Encounter.addDamageTaken(sim, result.Target, result.Damage)
+	} else if sim.CurrentTime >= 0 {
+		result.Target.Metrics.addDamageTaken(spell, result)
 	}
 
 	if sim.Log != nil {
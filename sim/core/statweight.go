@@ -102,7 +102,44 @@ func (swr *StatWeightsResult) ToProto() *proto.StatWeightsResult {
 	}
 }
 
+// A finalized environment for the stat weights player, which can be reused
+// for each stat by changing the player's bonus stats.
+type statWeightEnv struct {
+	env       *Environment
+	character *Character
+
+	// Initial stats without dependencies for the baseline bonus stats.
+	baselineStats stats.Stats
+}
+
+func newStatWeightEnv(rsr *proto.RaidSimRequest) *statWeightEnv {
+	env, _, _ := NewEnvironment(rsr.Raid, rsr.Encounter, false)
+	character := env.Raid.Parties[0].Players[0].GetCharacter()
+	return &statWeightEnv{
+		env:           env,
+		character:     character,
+		baselineStats: character.initialStatsWithoutDeps,
+	}
+}
+
+// Changes the player's bonus stats to the baseline plus value of stat.
+// Returns false if the change can't be applied without rebuilding.
+func (swEnv *statWeightEnv) setBonusStat(stat stats.UnitStat, value float64) bool {
+	if !stat.IsStat() {
+		return false
+	}
+	var bonus stats.Stats
+	bonus[stat.StatIdx()] = value
+	// Bonus stats are scaled with the equipment, see Character.EquipStats.
+	bonus = bonus.DotProduct(swEnv.character.itemStatMultipliers)
+	return swEnv.character.setInitialStatsWithoutDeps(swEnv.baselineStats.Add(bonus))
+}
+
 func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, progress chan *proto.ProgressMetrics) *StatWeightsResult {
+	return calcStatWeight(swr, referenceStat, progress, true)
+}
+
+func calcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, progress chan *proto.ProgressMetrics, reuseEnvs bool) *StatWeightsResult {
 	if swr.Player.BonusStats == nil {
 		swr.Player.BonusStats = &proto.UnitStats{}
 	}
@@ -170,6 +207,19 @@ func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, pro
 		tickets <- struct{}{}
 	}
 
+	// Environments built from the baseline request, which are reused for each
+	// stat by changing the player's initial stats instead of rebuilding. Presims
+	// depend on the player's stats, so those sims are always rebuilt.
+	envs := make(chan *statWeightEnv, concurrency)
+	if reuseEnvs {
+		firstEnv := newStatWeightEnv(baseSimRequest)
+		if firstEnv.env.hasPresims(baseSimRequest) {
+			reuseEnvs = false
+		} else {
+			envs <- firstEnv
+		}
+	}
+
 	doStat := func(stat stats.UnitStat, value float64, isLow bool) {
 		defer waitGroup.Done()
 		// wait until we have CPU time available.
@@ -179,7 +229,29 @@ func CalcStatWeight(swr *proto.StatWeightsRequest, referenceStat stats.Stat, pro
 		stat.AddToStatsProto(simRequest.Raid.Parties[0].Players[0].BonusStats, value)
 
 		reporter := make(chan *proto.ProgressMetrics, 10)
-		go RunSim(simRequest, reporter, nil) // RunRaidSim(simRequest)
+		var swEnv *statWeightEnv
+		if reuseEnvs {
+			select {
+			case swEnv = <-envs:
+			default:
+				swEnv = newStatWeightEnv(baseSimRequest)
+			}
+			if !swEnv.setBonusStat(stat, value) {
+				swEnv = nil
+			}
+		}
+		if swEnv != nil {
+			go func() {
+				runSimWithEnv(swEnv.env, simRequest, reporter, false, nil)
+				swEnv.setBonusStat(stat, 0)
+				select {
+				case envs <- swEnv:
+				default:
+				}
+			}()
+		} else {
+			go RunSim(simRequest, reporter, nil) // RunRaidSim(simRequest)
+		}
 
 		var localIterations int32
 		var errorStr string
@@ -0,0 +1,34 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+func TestStatWeightsEnvReuse(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerFeralCat())
+	rsr.SimOptions.Iterations = 50
+	core.StatWeightsEnvReuseTest(t.Name(), t, &proto.StatWeightsRequest{
+		Player:     rsr.Raid.Parties[0].Players[0],
+		RaidBuffs:  rsr.Raid.Buffs,
+		PartyBuffs: rsr.Raid.Parties[0].Buffs,
+		Debuffs:    rsr.Raid.Debuffs,
+		Encounter:  rsr.Encounter,
+		SimOptions: rsr.SimOptions,
+		Tanks:      rsr.Raid.Tanks,
+
+		StatsToWeigh: []proto.Stat{
+			proto.Stat_StatStrength,
+			proto.Stat_StatAgility,
+			proto.Stat_StatAttackPower,
+			proto.Stat_StatMeleeHit,
+			proto.Stat_StatMeleeCrit,
+			proto.Stat_StatMeleeHaste,
+			proto.Stat_StatExpertise,
+			proto.Stat_StatMastery,
+		},
+		EpReferenceStat: proto.Stat_StatAttackPower,
+	})
+}
@@ -27,6 +27,11 @@ type Encounter struct {
 	// In health fight: set to true until we get something to base on
 	DurationIsEstimate bool
 
+	// Maps elapsed fight time to target health in duration-based fights.
+	healthTimeline []healthTimelinePoint
+	// Encounter damage taken at which the next encounter-wide health threshold is crossed.
+	nextHealthThresholdDamage float64
+
 	// Value to multiply by, for damage spells which are subject to the aoe cap.
 	aoeCapMultiplier float64
 }
@@ -76,6 +81,7 @@ func NewEncounter(options *proto.Encounter) Encounter {
 		encounter.DurationIsEstimate = true
 	}
 
+	encounter.healthTimeline = newHealthTimeline(&encounter)
 	encounter.updateAOECapMultiplier()
 
 	return encounter
@@ -116,6 +122,17 @@ type Target struct {
 	IsActive bool
 
 	AI TargetAI
+
+	// Damage taken by this target in the current iteration, used for health fights.
+	DamageTaken float64
+	// Damage taken at which the next health threshold on this target is crossed.
+	nextHealthThresholdDamage float64
+
+	// Spell abilities from the target config, cast on a fixed cadence.
+	spellAbilities []*targetSpellAbility
+
+	// Health samples for time to die estimates, only set if something uses them.
+	timeToDie *timeToDieTracker
 }
 
 func NewTarget(options *proto.Target, targetIndex int32) *Target {
@@ -171,9 +188,20 @@ func (target *Target) Reset(sim *Simulation) {
 	target.CurrentTarget = target.defaultTarget
 
 	target.SetGCDTimer(sim, 0)
+	for _, ability := range target.spellAbilities {
+		ability.reset(sim)
+	}
 	if target.AI != nil {
 		target.AI.Reset(sim)
 	}
+	if target.timeToDie != nil {
+		target.timeToDie.reset(sim)
+	}
+}
+
+// Returns the configured health of this target, or 0 if none was set.
+func (target *Target) healthPool() float64 {
+	return target.stats[stats.Health]
 }
 
 func (target *Target) NextTarget() *Target {
@@ -36,6 +36,8 @@ func (target *Target) initialize(config *proto.Target) {
 		target.EnableAutoAttacks(target, aaOptions)
 	}
 
+	target.registerSpellAbilities(config)
+
 	if target.AI != nil {
 		target.AI.Initialize(target, config)
 
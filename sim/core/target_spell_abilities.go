@@ -0,0 +1,158 @@
+package core
+
+import (
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// A spell cast by a target on a fixed cadence, defined by the encounter config
+// rather than by a custom TargetAI.
+type targetSpellAbility struct {
+	target *Target
+	config *proto.TargetSpellAbility
+	spell  *Spell
+
+	// Reused between casts to avoid allocating.
+	victims []*Unit
+}
+
+func (target *Target) registerSpellAbilities(config *proto.Target) {
+	for _, abilityConfig := range config.SpellAbilities {
+		target.spellAbilities = append(target.spellAbilities, target.newSpellAbility(abilityConfig))
+	}
+}
+
+func (target *Target) newSpellAbility(config *proto.TargetSpellAbility) *targetSpellAbility {
+	ability := &targetSpellAbility{
+		target:  target,
+		config:  config,
+		victims: make([]*Unit, 0, len(target.Env.Raid.AllUnits)),
+	}
+
+	spellSchool := SpellSchoolFromProto(config.SpellSchool)
+	procMask := ProcMaskSpellDamage
+	if spellSchool == SpellSchoolPhysical {
+		procMask = ProcMaskMeleeMHSpecial
+	}
+
+	flags := SpellFlagNone
+	if config.Unabsorbable {
+		flags |= SpellFlagCannotBeAbsorbed
+	}
+
+	var outcomeApplier OutcomeApplier
+	ability.spell = target.RegisterSpell(SpellConfig{
+		ActionID:    ActionID{SpellID: config.SpellId},
+		SpellSchool: spellSchool,
+		ProcMask:    procMask,
+		Flags:       flags,
+
+		DamageMultiplier: 1,
+		ThreatMultiplier: 1,
+
+		ApplyEffects: func(sim *Simulation, victim *Unit, spell *Spell) {
+			if config.Targeting != proto.TargetAbilityTargeting_TargetAbilityTargetingRaid {
+				baseDamage := config.MinDamage + config.DamageSpread*sim.RandomFloat("Target Ability Damage")
+				spell.CalcAndDealDamage(sim, victim, baseDamage, outcomeApplier)
+				return
+			}
+
+			// Filled with the active allies by primaryVictim.
+			for _, unit := range ability.victims {
+				baseDamage := config.MinDamage + config.DamageSpread*sim.RandomFloat("Target Ability Damage")
+				spell.CalcAndDealDamage(sim, unit, baseDamage, outcomeApplier)
+			}
+		},
+	})
+
+	switch {
+	case config.CanAvoid && config.CanBlock:
+		outcomeApplier = ability.spell.OutcomeEnemyMeleeSpecialHit
+	case config.CanAvoid:
+		outcomeApplier = ability.spell.OutcomeEnemyMeleeSpecialHitNoBlock
+	case config.CanBlock:
+		outcomeApplier = ability.spell.OutcomeEnemyBlockOnly
+	default:
+		outcomeApplier = ability.spell.OutcomeAlwaysHit
+	}
+
+	return ability
+}
+
+func (ability *targetSpellAbility) reset(sim *Simulation) {
+	StartDelayedAction(sim, DelayedActionOptions{
+		DoAt: DurationFromSeconds(ability.config.InitialDelay),
+		OnAction: func(sim *Simulation) {
+			if ability.config.Cooldown <= 0 {
+				ability.startCast(sim)
+				return
+			}
+
+			StartPeriodicAction(sim, PeriodicActionOptions{
+				Period:          DurationFromSeconds(ability.config.Cooldown),
+				TickImmediately: true,
+				OnAction:        ability.startCast,
+			})
+		},
+	})
+}
+
+func (ability *targetSpellAbility) startCast(sim *Simulation) {
+	target := ability.target
+	if !target.IsEnabled() {
+		return
+	}
+
+	castTime := DurationFromSeconds(ability.config.CastTime)
+	if castTime <= 0 {
+		ability.land(sim)
+		return
+	}
+
+	if sim.Log != nil {
+		target.Log(sim, "Casting %s (Cast Time = %s)", ability.spell.ActionID, castTime)
+	}
+
+	target.AutoAttacks.StopMeleeUntil(sim, sim.CurrentTime+castTime, false)
+	StartDelayedAction(sim, DelayedActionOptions{
+		DoAt:     sim.CurrentTime + castTime,
+		OnAction: ability.land,
+	})
+}
+
+func (ability *targetSpellAbility) land(sim *Simulation) {
+	if victim := ability.primaryVictim(sim); victim != nil {
+		ability.spell.Cast(sim, victim)
+	}
+}
+
+// Returns the unit the ability is cast on, or nil if there is nothing to hit.
+// Raid-wide abilities are cast on the first active ally and hit everyone.
+func (ability *targetSpellAbility) primaryVictim(sim *Simulation) *Unit {
+	switch ability.config.Targeting {
+	case proto.TargetAbilityTargeting_TargetAbilityTargetingRaid:
+		ability.victims = ability.victims[:0]
+		for _, unit := range sim.Raid.AllUnits {
+			if unit.IsActive() && unit.Type != EnemyUnit {
+				ability.victims = append(ability.victims, unit)
+			}
+		}
+		if len(ability.victims) > 0 {
+			return ability.victims[0]
+		}
+	case proto.TargetAbilityTargeting_TargetAbilityTargetingRandomPlayer:
+		ability.victims = ability.victims[:0]
+		for _, unit := range sim.Raid.AllPlayerUnits {
+			if unit.IsActive() {
+				ability.victims = append(ability.victims, unit)
+			}
+		}
+		if len(ability.victims) > 0 {
+			return ability.victims[int(sim.RandomFloat("Target Ability Victim")*float64(len(ability.victims)))]
+		}
+	default:
+		if currentTarget := ability.target.CurrentTarget; currentTarget != nil && currentTarget.IsActive() {
+			return currentTarget
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+const testAbilitySpellID = 99999
+
+func runSpellAbilityTestCase(t *testing.T, ability *proto.TargetSpellAbility, raidBuffs *proto.RaidBuffs) *proto.DamageTakenMetrics {
+	rsr := makeTestCase(getTestPlayerBloodDk())
+	rsr.Raid.Tanks = []*proto.UnitReference{{Type: proto.UnitReference_Player, Index: 0}}
+	rsr.Raid.Buffs = raidBuffs
+	rsr.SimOptions.Iterations = 20
+
+	target := googleProto.Clone(core.NewDefaultTarget()).(*proto.Target)
+	target.SpellAbilities = []*proto.TargetSpellAbility{ability}
+	rsr.Encounter.Targets = []*proto.Target{target}
+
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatal(result.ErrorResult)
+	}
+
+	for _, dtm := range result.RaidMetrics.Parties[0].Players[0].DamageTaken {
+		if dtm.Id.GetSpellId() == testAbilitySpellID {
+			return dtm
+		}
+	}
+	t.Fatalf("expected spell ability in damage taken metrics")
+	return nil
+}
+
+func TestSpellAbilityResistances(t *testing.T) {
+	ability := &proto.TargetSpellAbility{
+		SpellId:      testAbilitySpellID,
+		SpellSchool:  proto.SpellSchool_SpellSchoolShadow,
+		MinDamage:    30000,
+		DamageSpread: 5000,
+		InitialDelay: 5,
+		Cooldown:     10,
+		CastTime:     1.5,
+	}
+
+	withoutProtection := runSpellAbilityTestCase(t, ability, googleProto.Clone(core.FullRaidBuffs).(*proto.RaidBuffs))
+
+	buffs := googleProto.Clone(core.FullRaidBuffs).(*proto.RaidBuffs)
+	buffs.ShadowProtection = true
+	withProtection := runSpellAbilityTestCase(t, ability, buffs)
+
+	if withProtection.School != proto.SpellSchool_SpellSchoolShadow {
+		t.Fatalf("spell ability should be shadow, got %s", withProtection.School)
+	}
+	// 20 iterations of a 300s fight, casting at 5s and every 10s after.
+	if withProtection.Hits != 20*30 {
+		t.Fatalf("expected 600 hits, got %d", withProtection.Hits)
+	}
+	if withProtection.Dodges != 0 || withProtection.Parries != 0 || withProtection.Blocks != 0 {
+		t.Fatalf("unavoidable spell ability was avoided")
+	}
+	if withProtection.ResistedDamage <= withoutProtection.ResistedDamage {
+		t.Fatalf("expected Shadow Protection to increase resisted damage, got %f vs %f", withProtection.ResistedDamage, withoutProtection.ResistedDamage)
+	}
+	if withProtection.Damage >= withoutProtection.Damage {
+		t.Fatalf("expected Shadow Protection to reduce damage taken, got %f vs %f", withProtection.Damage, withoutProtection.Damage)
+	}
+}
+
+func TestSpellAbilityAvoidance(t *testing.T) {
+	ability := &proto.TargetSpellAbility{
+		SpellId:     testAbilitySpellID,
+		SpellSchool: proto.SpellSchool_SpellSchoolPhysical,
+		MinDamage:   50000,
+		Cooldown:    3,
+		CanAvoid:    true,
+	}
+
+	dtm := runSpellAbilityTestCase(t, ability, core.FullRaidBuffs)
+	if dtm.Dodges == 0 || dtm.Parries == 0 {
+		t.Fatalf("expected avoidable spell ability to be dodged and parried, got %d dodges, %d parries", dtm.Dodges, dtm.Parries)
+	}
+	if dtm.Crits != 0 {
+		t.Fatalf("spell abilities should not crit, got %d crits", dtm.Crits)
+	}
+}
+
+func TestUnabsorbableSpellAbilityBypassesAntiMagicShell(t *testing.T) {
+	const antiMagicShellID = 48707
+
+	amsAbsorbed := func(unabsorbable bool) float64 {
+		player := getTestPlayerBloodDk()
+		player.Rotation.PriorityList = append([]*proto.APLListItem{{
+			Action: &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+				SpellId: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: antiMagicShellID}},
+			}}},
+		}}, player.Rotation.PriorityList...)
+
+		rsr := makeTestCase(player)
+		rsr.Raid.Tanks = []*proto.UnitReference{{Type: proto.UnitReference_Player, Index: 0}}
+		rsr.SimOptions.Iterations = 5
+
+		target := googleProto.Clone(core.NewDefaultTarget()).(*proto.Target)
+		target.SpellAbilities = []*proto.TargetSpellAbility{{
+			SpellId:      testAbilitySpellID,
+			SpellSchool:  proto.SpellSchool_SpellSchoolShadow,
+			MinDamage:    20000,
+			Cooldown:     1,
+			Unabsorbable: unabsorbable,
+		}}
+		rsr.Encounter.Targets = []*proto.Target{target}
+
+		result := core.RunRaidSim(rsr)
+		if result.ErrorResult != "" {
+			t.Fatal(result.ErrorResult)
+		}
+
+		absorbed := 0.0
+		for _, resource := range result.RaidMetrics.Parties[0].Players[0].Resources {
+			if resource.Id.GetSpellId() == antiMagicShellID && resource.Type == proto.ResourceType_ResourceTypeHealth {
+				absorbed += resource.Gain
+			}
+		}
+		return absorbed
+	}
+
+	if absorbed := amsAbsorbed(false); absorbed == 0 {
+		t.Fatalf("expected Anti-Magic Shell to absorb the spell ability")
+	}
+	if absorbed := amsAbsorbed(true); absorbed != 0 {
+		t.Fatalf("expected unabsorbable damage to bypass Anti-Magic Shell, got %0.1f absorbed", absorbed)
+	}
+}
@@ -155,6 +155,62 @@ func checkResourceMetrics(t *testing.T, loc string, st []*proto.ResourceMetrics,
 	}
 }
 
+func checkDamageTakenMetrics(t *testing.T, loc string, st []*proto.DamageTakenMetrics, mt []*proto.DamageTakenMetrics, baseFloatTolerance float64) {
+	damageTaken := map[string]*proto.DamageTakenMetrics{}
+
+	dkey := func(d *proto.DamageTakenMetrics) string {
+		return fmt.Sprintf("%d %s", d.SourceUnitIndex, d.Id.String())
+	}
+
+	for _, mtDamageTaken := range mt {
+		key := dkey(mtDamageTaken)
+		_, exists := damageTaken[key]
+		if exists {
+			t.Logf("%s.DamageTaken: %v exists multiple times in multi threaded results!", loc, key)
+			t.Fail()
+			continue
+		}
+		damageTaken[key] = mtDamageTaken
+	}
+
+	if len(st) != len(mt) {
+		t.Logf("%s.DamageTaken: Expected length %d but is %d for multi threaded result!", loc, len(st), len(mt))
+		t.Fail()
+	}
+
+	for _, stDamageTaken := range st {
+		stKey := dkey(stDamageTaken)
+		mtDamageTaken, exists := damageTaken[stKey]
+		if !exists {
+			t.Logf("%s.DamageTaken: %s does not exist in multi threaded results!", loc, stKey)
+			t.Fail()
+			continue
+		}
+
+		// These are totals over all iterations, so summing them in a different order across
+		// threads leaves rounding errors proportional to their size.
+		totalDamage := stDamageTaken.Damage + stDamageTaken.ResistedDamage + stDamageTaken.ReducedDamage +
+			stDamageTaken.AvoidedDamage + stDamageTaken.BlockedDamage + stDamageTaken.AbsorbedDamage
+		tolerance := max(baseFloatTolerance, totalDamage*1e-10)
+
+		compareValue(t, fmt.Sprintf("%s.DamageTaken[%s]", loc, stKey), reflect.ValueOf(stDamageTaken), reflect.ValueOf(mtDamageTaken), tolerance)
+	}
+}
+
+func checkCooldownMitigationMetrics(t *testing.T, loc string, st []*proto.CooldownMitigationMetrics, mt []*proto.CooldownMitigationMetrics, baseFloatTolerance float64) {
+	if len(st) != len(mt) {
+		t.Logf("%s.CooldownMitigation: Expected length %d but is %d for multi threaded result!", loc, len(st), len(mt))
+		t.Fail()
+		return
+	}
+
+	for i := range st {
+		// Totals over all iterations, see checkDamageTakenMetrics.
+		tolerance := max(baseFloatTolerance, (st[i].DamageTaken+st[i].DamageMitigated)*1e-10)
+		compareValue(t, fmt.Sprintf("%s.CooldownMitigation[%d]", loc, i), reflect.ValueOf(st[i]), reflect.ValueOf(mt[i]), tolerance)
+	}
+}
+
 func compareStruct(t *testing.T, loc string, vst reflect.Value, vmt reflect.Value, baseFloatTolerance float64) {
 	for i := 0; i < vst.NumField(); i++ {
 		fieldName := vst.Type().Field(i).Name
@@ -186,6 +242,12 @@ func compareStruct(t *testing.T, loc string, vst reflect.Value, vmt reflect.Valu
 		} else if fieldName == "Resources" {
 			checkResourceMetrics(t, loc, stField.Interface().([]*proto.ResourceMetrics), mtField.Interface().([]*proto.ResourceMetrics), baseFloatTolerance)
 			continue
+		} else if fieldName == "CooldownMitigation" {
+			checkCooldownMitigationMetrics(t, loc, stField.Interface().([]*proto.CooldownMitigationMetrics), mtField.Interface().([]*proto.CooldownMitigationMetrics), baseFloatTolerance)
+			continue
+		} else if fieldName == "DamageTaken" && stField.Kind() == reflect.Slice {
+			checkDamageTakenMetrics(t, loc, stField.Interface().([]*proto.DamageTakenMetrics), mtField.Interface().([]*proto.DamageTakenMetrics), baseFloatTolerance)
+			continue
 		}
 
 		compareValue(t, fmt.Sprintf("%s.%s", loc, fieldName), stField, mtField, baseFloatTolerance)
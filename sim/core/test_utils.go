@@ -187,6 +187,38 @@ func StatWeightsTest(label string, t *testing.T, _swr *proto.StatWeightsRequest,
 	}
 }
 
+// Checks that stat weights from reused environments match the ones from
+// rebuilding the environment for each stat.
+func StatWeightsEnvReuseTest(label string, t *testing.T, swr *proto.StatWeightsRequest) {
+	calc := func(reuseEnvs bool) *StatWeightsResult {
+		// Make a copy since CalcStatWeight changes some fields.
+		return calcStatWeight(googleProto.Clone(swr).(*proto.StatWeightsRequest), stats.Stat(swr.EpReferenceStat), nil, reuseEnvs)
+	}
+	rebuiltWeights := calc(false).Dps.Weights
+	reusedWeights := calc(true).Dps.Weights
+
+	const tolerance = 0.00001
+	if !reusedWeights.Stats.EqualsWithTolerance(rebuiltWeights.Stats, tolerance) {
+		t.Fatalf("%s failed: weights with reused environments = %v, expected %v", label, reusedWeights.Stats, rebuiltWeights.Stats)
+	}
+	for i := range rebuiltWeights.PseudoStats {
+		if rebuiltWeights.PseudoStats[i] != reusedWeights.PseudoStats[i] {
+			t.Fatalf("%s failed: pseudo stat weights with reused environments = %v, expected %v", label, reusedWeights.PseudoStats, rebuiltWeights.PseudoStats)
+		}
+	}
+}
+
+func StatWeightsBenchmark(b *testing.B, _swr *proto.StatWeightsRequest, reuseEnvs bool) {
+	swr := googleProto.Clone(_swr).(*proto.StatWeightsRequest)
+
+	// Use few iterations, where building environments matters most.
+	swr.SimOptions.Iterations = 2
+
+	for i := 0; i < b.N; i++ {
+		calcStatWeight(googleProto.Clone(swr).(*proto.StatWeightsRequest), stats.Stat(swr.EpReferenceStat), nil, reuseEnvs)
+	}
+}
+
 func RaidSimTest(label string, t *testing.T, rsr *proto.RaidSimRequest, expectedDps float64) {
 	result := RunRaidSim(rsr)
 	if result.ErrorResult != "" {
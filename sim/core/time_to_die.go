@@ -0,0 +1,117 @@
+package core
+
+import (
+	"time"
+)
+
+const (
+	timeToDieSamplePeriod = time.Second
+	// Samples older than this are dropped, so estimates follow changes in the damage rate.
+	timeToDieWindow = time.Second * 15
+)
+
+type healthSample struct {
+	time   time.Duration
+	health float64 // Health percent, as a value from 0-1.
+}
+
+// Estimates when a target will die from its recent health, by fitting a line
+// through periodic health samples the way time-to-die addons do.
+type timeToDieTracker struct {
+	target  *Target
+	samples []healthSample
+}
+
+func (tracker *timeToDieTracker) reset(sim *Simulation) {
+	tracker.samples = tracker.samples[:0]
+	StartPeriodicAction(sim, PeriodicActionOptions{
+		Period:          timeToDieSamplePeriod,
+		TickImmediately: true,
+		OnAction:        tracker.sample,
+	})
+}
+
+func (tracker *timeToDieTracker) sample(sim *Simulation) {
+	expired := 0
+	for expired < len(tracker.samples) && tracker.samples[expired].time < sim.CurrentTime-timeToDieWindow {
+		expired++
+	}
+	tracker.samples = append(tracker.samples[:0], tracker.samples[expired:]...)
+
+	tracker.samples = append(tracker.samples, healthSample{
+		time:   sim.CurrentTime,
+		health: sim.GetTargetHealthPercent(&tracker.target.Unit),
+	})
+}
+
+// Returns the estimated time until the target's health reaches 0, or false if
+// there are too few samples or the target's health isn't dropping.
+func (tracker *timeToDieTracker) estimate(sim *Simulation) (time.Duration, bool) {
+	n := float64(len(tracker.samples))
+	if n < 2 {
+		return 0, false
+	}
+
+	// Least squares fit of health = intercept + slope * seconds.
+	var sumT, sumH, sumTT, sumTH float64
+	for _, sample := range tracker.samples {
+		t := sample.time.Seconds()
+		sumT += t
+		sumH += sample.health
+		sumTT += t * t
+		sumTH += t * sample.health
+	}
+	denominator := n*sumTT - sumT*sumT
+	if denominator == 0 {
+		return 0, false
+	}
+	slope := (n*sumTH - sumT*sumH) / denominator
+	if slope >= 0 {
+		return 0, false
+	}
+	intercept := (sumH - slope*sumT) / n
+
+	deathTime := DurationFromSeconds(-intercept / slope)
+	return max(deathTime-sim.CurrentTime, 0), true
+}
+
+// Starts sampling this target's health each iteration, so its time to die can
+// be estimated.
+func (target *Target) enableTimeToDie() {
+	if target.timeToDie == nil {
+		target.timeToDie = &timeToDieTracker{target: target}
+	}
+}
+
+// Returns the estimated time until this target dies. Until there is enough
+// health data to extrapolate from, this is the remaining fight duration.
+func (target *Target) TimeToDie(sim *Simulation) time.Duration {
+	if target.timeToDie != nil {
+		if ttd, ok := target.timeToDie.estimate(sim); ok {
+			return ttd
+		}
+	}
+	return sim.GetRemainingDuration()
+}
+
+// Returns when this target dies from the sim's knowledge of its health, rather
+// than from recent samples. In duration-based fights every target's health
+// reaches 0 at the end of the fight. In health-based fights a target with its
+// own health pool dies once the damage it has taken on average so far uses up
+// its remaining health, or when the fight ends if that is sooner.
+func (target *Target) OracleTimeToDie(sim *Simulation) time.Duration {
+	remainingDuration := sim.GetRemainingDuration()
+	if sim.Encounter.EndFightAtHealth <= 0 || target.healthPool() <= 0 {
+		return remainingDuration
+	}
+
+	remainingHealth := target.healthPool() - target.DamageTaken
+	if remainingHealth <= 0 {
+		return 0
+	}
+	if sim.CurrentTime <= 0 || target.DamageTaken <= 0 {
+		return remainingDuration
+	}
+	dps := target.DamageTaken / sim.CurrentTime.Seconds()
+	return min(DurationFromSeconds(remainingHealth/dps), remainingDuration)
+}
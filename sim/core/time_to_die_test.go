@@ -0,0 +1,90 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/cata/sim/core/stats"
+)
+
+func TestTimeToDieEstimate(t *testing.T) {
+	encounter := Encounter{
+		ExecuteProportion_20: 0.2,
+		ExecuteProportion_25: 0.25,
+		ExecuteProportion_35: 0.35,
+		ExecuteProportion_90: 0.9,
+	}
+	encounter.healthTimeline = newHealthTimeline(&encounter)
+	sim := &Simulation{
+		Environment: &Environment{Encounter: encounter},
+		Duration:    time.Second * 100,
+	}
+	tracker := &timeToDieTracker{target: &Target{}}
+
+	sim.CurrentTime = time.Second * 10
+	tracker.sample(sim)
+	if _, ok := tracker.estimate(sim); ok {
+		t.Fatalf("Expected no estimate from a single sample")
+	}
+
+	// Health drops 1% per second, so the target dies at the end of the fight.
+	for sim.CurrentTime < time.Second*30 {
+		sim.CurrentTime += timeToDieSamplePeriod
+		tracker.sample(sim)
+	}
+	if len(tracker.samples) != 16 {
+		t.Fatalf("Expected samples older than the window to be dropped, got %d samples", len(tracker.samples))
+	}
+	ttd, ok := tracker.estimate(sim)
+	if !ok {
+		t.Fatalf("Expected an estimate")
+	}
+	if diff := ttd - (sim.Duration - sim.CurrentTime); diff < -time.Millisecond || diff > time.Millisecond {
+		t.Fatalf("Expected time to die of %s, got %s", sim.Duration-sim.CurrentTime, ttd)
+	}
+}
+
+func TestOracleTimeToDie(t *testing.T) {
+	sim := &Simulation{
+		Environment: &Environment{Encounter: Encounter{EndFightAtHealth: 3000}},
+		Duration:    time.Second * 100,
+		CurrentTime: time.Second * 10,
+	}
+
+	// Each target dies when its own health runs out at its own damage rate.
+	add := &Target{DamageTaken: 500}
+	add.stats[stats.Health] = 1000
+	boss := &Target{DamageTaken: 500}
+	boss.stats[stats.Health] = 2000
+	if ttd := add.OracleTimeToDie(sim); ttd != time.Second*10 {
+		t.Fatalf("Expected the add to die in 10s, got %s", ttd)
+	}
+	if ttd := boss.OracleTimeToDie(sim); ttd != time.Second*30 {
+		t.Fatalf("Expected the boss to die in 30s, got %s", ttd)
+	}
+
+	add.DamageTaken = 1000
+	if ttd := add.OracleTimeToDie(sim); ttd != 0 {
+		t.Fatalf("Expected a dead add to have no time to die, got %s", ttd)
+	}
+
+	// Targets without a health pool die when the fight ends.
+	if ttd := (&Target{}).OracleTimeToDie(sim); ttd != sim.Duration-sim.CurrentTime {
+		t.Fatalf("Expected time to die of %s, got %s", sim.Duration-sim.CurrentTime, ttd)
+	}
+}
+
+func TestTimeToDieValueIgnoresNonEnemyUnits(t *testing.T) {
+	sim := &Simulation{
+		Environment: &Environment{Encounter: Encounter{Targets: []*Target{{}}}},
+		Duration:    time.Second * 100,
+		CurrentTime: time.Second * 10,
+	}
+
+	// A player's unit index is not a target index, so it must not be used to
+	// look up a target.
+	value := &APLValueTimeToDie{target: UnitReference{fixedUnit: &Unit{Type: PlayerUnit, Index: 3}}}
+	if ttd := value.GetDuration(sim); ttd != sim.Duration-sim.CurrentTime {
+		t.Fatalf("Expected time to die of %s, got %s", sim.Duration-sim.CurrentTime, ttd)
+	}
+}
@@ -198,6 +198,13 @@ func (unit *Unit) IsActive() bool {
 	return unit.IsEnabled() && unit.CurrentHealthPercent() > 0
 }
 
+// Returns whether this unit's actions are chosen externally rather than by its
+// rotation. In interactive mode only players are controlled, pets keep using
+// their own rotations.
+func (unit *Unit) IsInteractive(sim *Simulation) bool {
+	return sim.Options.Interactive && unit.Type == PlayerUnit
+}
+
 func (unit *Unit) IsOpponent(other *Unit) bool {
 	return (unit.Type == EnemyUnit) != (other.Type == EnemyUnit)
 }
@@ -294,6 +301,16 @@ func (unit *Unit) AddDynamicDamageTakenModifier(ddtm DynamicDamageTakenModifier)
 	unit.DynamicDamageTakenModifiers = append(unit.DynamicDamageTakenModifiers, ddtm)
 }
 
+// Adds a dynamic damage taken modifier for an absorb effect, which unabsorbable
+// damage bypasses.
+func (unit *Unit) AddDynamicDamageTakenAbsorb(ddtm DynamicDamageTakenModifier) {
+	unit.AddDynamicDamageTakenModifier(func(sim *Simulation, spell *Spell, result *SpellResult) {
+		if !spell.Flags.Matches(SpellFlagCannotBeAbsorbed) {
+			ddtm(sim, spell, result)
+		}
+	})
+}
+
 func (unit *Unit) AddOnMasteryStatChanged(omsc OnMasteryStatChanged) {
 	if unit.Env != nil && unit.Env.IsFinalized() {
 		panic("Already finalized, cannot add on mastery stat changed callback!")
@@ -544,6 +561,25 @@ func (unit *Unit) finalize() {
 	}
 }
 
+// Replaces the initial stats of a finalized unit, so its environment can be
+// reused with different bonus stats instead of being rebuilt. Haste and mastery
+// changes need the handling in processDynamicBonus, so for those this returns
+// false without changing anything.
+func (unit *Unit) setInitialStatsWithoutDeps(statsWithoutDeps stats.Stats) bool {
+	diff := statsWithoutDeps.Subtract(unit.initialStatsWithoutDeps)
+	if diff[stats.MeleeHaste] != 0 || diff[stats.SpellHaste] != 0 || diff[stats.Mastery] != 0 {
+		return false
+	}
+
+	unit.ResetStatDeps()
+	initialStats := unit.ApplyStatDependencies(statsWithoutDeps)
+	unit.initialStatsWithoutDeps = statsWithoutDeps
+	unit.initialStats = initialStats
+	unit.statsWithoutDeps = unit.initialStatsWithoutDeps
+	unit.stats = unit.initialStats
+	return true
+}
+
 func (unit *Unit) reset(sim *Simulation, _ Agent) {
 	unit.enabled = true
 	unit.resetCDs(sim)
@@ -0,0 +1,23 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wowsims/cata/sim/core/stats"
+)
+
+func TestSetInitialStatsWithoutDepsKeepsDepsOnHasteChange(t *testing.T) {
+	unit := &Unit{StatDependencyManager: stats.NewStatDependencyManager()}
+	dep := unit.NewDynamicMultiplyStat(stats.Agility, 1.1)
+	unit.FinalizeStatDeps()
+	unit.StatDependencyManager.EnableDynamicStatDep(dep)
+	unit.initialStatsWithoutDeps = stats.Stats{stats.Agility: 100}
+
+	if unit.setInitialStatsWithoutDeps(stats.Stats{stats.Agility: 100, stats.MeleeHaste: 10}) {
+		t.Fatalf("Expected haste changes to be rejected")
+	}
+	if agi := unit.ApplyStatDependencies(stats.Stats{stats.Agility: 100})[stats.Agility]; math.Abs(agi-110) > 0.001 {
+		t.Fatalf("Rejected change left agility deps applying %0.2f, expected 110", agi)
+	}
+}
@@ -0,0 +1,381 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/wowsims/cata/sim/core/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Talent points available at max level.
+const MaxTalentPoints = 41
+
+// Number of talents in each tree, per class. Registered by the class packages.
+var talentTreeSizes = make(map[proto.Class][3]int)
+
+func RegisterTalentTreeSizes(class proto.Class, treeSizes [3]int) {
+	talentTreeSizes[class] = treeSizes
+}
+
+// Returns the class a spec belongs to, based on the spec's name.
+func SpecClass(spec proto.Spec) proto.Class {
+	specName := spec.String()
+	for value, className := range proto.Class_name {
+		if value != int32(proto.Class_ClassUnknown) && strings.HasSuffix(specName, strings.TrimPrefix(className, "Class")) {
+			return proto.Class(value)
+		}
+	}
+	return proto.Class_ClassUnknown
+}
+
+type requestValidator struct {
+	issues []*proto.ValidationIssue
+}
+
+func (rv *requestValidator) add(severity proto.ValidationIssue_Severity, path string, message string, vals ...interface{}) {
+	rv.issues = append(rv.issues, &proto.ValidationIssue{
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(message, vals...),
+	})
+}
+
+func (rv *requestValidator) error(path string, message string, vals ...interface{}) {
+	rv.add(proto.ValidationIssue_SeverityError, path, message, vals...)
+}
+
+func (rv *requestValidator) warning(path string, message string, vals ...interface{}) {
+	rv.add(proto.ValidationIssue_SeverityWarning, path, message, vals...)
+}
+
+/**
+ * Checks a request for problems before simming it. Problems that would make
+ * the sim fail are errors, and APL validation warnings are included as
+ * warnings.
+ */
+func ValidateRequest(rsr *proto.RaidSimRequest) *proto.ValidateRequestResult {
+	rv := &requestValidator{}
+
+	numPlayers := 0
+	for i, party := range rsr.GetRaid().GetParties() {
+		for j, player := range party.Players {
+			if player.Class == proto.Class_ClassUnknown {
+				continue
+			}
+			numPlayers++
+			rv.validatePlayer(fmt.Sprintf("raid.parties[%d].players[%d]", i, j), player)
+		}
+	}
+	if numPlayers == 0 {
+		rv.error("raid", "Raid has no players")
+	}
+	if len(rsr.GetEncounter().GetTargets()) == 0 {
+		rv.error("encounter.targets", "Encounter has no targets")
+	}
+	if rsr.GetSimOptions().GetIterations() <= 0 {
+		rv.warning("sim_options.iterations", "Iterations should be positive")
+	}
+
+	// Only build the environment when the request looks valid, otherwise the
+	// panics would just repeat the errors above.
+	if !rv.hasErrors() {
+		rv.validateEnvironment(rsr)
+	}
+
+	return &proto.ValidateRequestResult{
+		Issues: rv.issues,
+	}
+}
+
+func (rv *requestValidator) hasErrors() bool {
+	for _, issue := range rv.issues {
+		if issue.Severity == proto.ValidationIssue_SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (rv *requestValidator) validatePlayer(path string, player *proto.Player) {
+	if player.GetSpec() == nil {
+		rv.error(path, "Player has no spec")
+	} else if spec := PlayerProtoToSpec(player); spec == proto.Spec_SpecUnknown {
+		rv.error(path, "Spec %T is not supported", player.GetSpec())
+	} else if specClass := SpecClass(spec); specClass != player.Class {
+		rv.error(path, "Spec %s doesn't match class %s", spec, player.Class)
+	}
+
+	if player.Database != nil {
+		addToDatabase(player.Database)
+	}
+	rv.validateEquipment(path+".equipment", player)
+	rv.validateTalents(path+".talents_string", player)
+	rv.validateGlyphs(path+".glyphs", player)
+
+	rotation := player.GetRotation()
+	if rotation == nil || (rotation.Type == proto.APLRotation_TypeAPL && len(rotation.PriorityList) == 0) {
+		rv.warning(path+".rotation", "Player has no rotation")
+	}
+}
+
+func (rv *requestValidator) validateEquipment(path string, player *proto.Player) {
+	equipment := player.GetEquipment()
+	if len(equipment.GetItems()) > len(proto.ItemSlot_name) {
+		rv.error(path+".items", "Equipment has %d items, but there are only %d slots", len(equipment.Items), len(proto.ItemSlot_name))
+		return
+	}
+
+	for i, itemSpec := range equipment.GetItems() {
+		if itemSpec.Id == 0 {
+			continue
+		}
+		itemPath := fmt.Sprintf("%s.items[%d]", path, i)
+		slot := proto.ItemSlot(i)
+
+		item, ok := ItemsByID[itemSpec.Id]
+		if !ok {
+			rv.error(itemPath, "No item with id %d", itemSpec.Id)
+			continue
+		}
+
+		titansGrip := player.Class == proto.Class_ClassWarrior && slot == proto.ItemSlot_ItemSlotOffHand && item.HandType == proto.HandType_HandTypeTwoHand
+		if eligibleSlots := eligibleSlotsForItem(item); !titansGrip && !slices.Contains(eligibleSlots, slot) {
+			rv.error(itemPath, "%s can't be equipped in %s", item.Name, slot)
+		}
+
+		if itemSpec.RandomSuffix != 0 {
+			if _, ok := RandomSuffixesByID[itemSpec.RandomSuffix]; !ok {
+				rv.error(itemPath+".random_suffix", "No random suffix with id %d", itemSpec.RandomSuffix)
+			}
+		}
+
+		if itemSpec.Enchant != 0 {
+			if _, ok := EnchantsByEffectID[itemSpec.Enchant]; !ok {
+				rv.warning(itemPath+".enchant", "No enchant with id %d, it will be ignored", itemSpec.Enchant)
+			}
+		}
+
+		if itemSpec.Reforging > 112 {
+			reforge, ok := ReforgeStatsByID[itemSpec.Reforging]
+			if !ok {
+				rv.error(itemPath+".reforging", "No reforge with id %d", itemSpec.Reforging)
+			} else {
+				if itemSpec.RandomSuffix != 0 {
+					item.RandomSuffix = RandomSuffixesByID[itemSpec.RandomSuffix]
+				}
+				if !validateReforging(&item, reforge) {
+					rv.error(itemPath+".reforging", "%s can't be reforged with id %d", item.Name, itemSpec.Reforging)
+				}
+			}
+		}
+
+		for gemIdx, gemID := range itemSpec.Gems {
+			if gemID == 0 {
+				continue
+			}
+			gemPath := fmt.Sprintf("%s.gems[%d]", itemPath, gemIdx)
+			gem, ok := GemsByID[gemID]
+			if !ok {
+				rv.error(gemPath, "No gem with id %d", gemID)
+				continue
+			}
+
+			// Extra sockets (belt buckle, blacksmithing) only take regular gems.
+			socketColor := proto.GemColor_GemColorPrismatic
+			if gemIdx < len(item.GemSockets) {
+				socketColor = item.GemSockets[gemIdx]
+			}
+			if !gemFitsSocket(gem.Color, socketColor) {
+				rv.error(gemPath, "%s gem %d doesn't fit in a %s socket", gem.Color, gemID, socketColor)
+			}
+		}
+	}
+
+	for _, issue := range equipmentConflicts(equipment) {
+		issue.Path = path + "." + issue.Path
+		rv.issues = append(rv.issues, issue)
+	}
+}
+
+// Meta and cogwheel gems need their own sockets, every other gem fits in any
+// socket apart from those.
+func gemFitsSocket(gemColor proto.GemColor, socketColor proto.GemColor) bool {
+	switch {
+	case gemColor == proto.GemColor_GemColorMeta || socketColor == proto.GemColor_GemColorMeta:
+		return gemColor == socketColor
+	case gemColor == proto.GemColor_GemColorCogwheel || socketColor == proto.GemColor_GemColorCogwheel:
+		return gemColor == socketColor
+	}
+	return true
+}
+
+// Returns conflicts between equipped items, e.g. a two-hander with an
+// off-hand or the same ring twice. Paths are relative to the equipment.
+func equipmentConflicts(equipment *proto.EquipmentSpec) []*proto.ValidationIssue {
+	var issues []*proto.ValidationIssue
+	conflict := func(slot proto.ItemSlot, message string, vals ...interface{}) {
+		issues = append(issues, &proto.ValidationIssue{
+			Severity: proto.ValidationIssue_SeverityError,
+			Path:     fmt.Sprintf("items[%d]", slot),
+			Message:  fmt.Sprintf(message, vals...),
+		})
+	}
+
+	itemID := func(slot proto.ItemSlot) int32 {
+		if int(slot) < len(equipment.GetItems()) {
+			return equipment.Items[slot].Id
+		}
+		return 0
+	}
+
+	// Validate weapons
+	var usesTwoHander, usesOffhand bool
+	if knownItem, ok := ItemsByID[itemID(proto.ItemSlot_ItemSlotMainHand)]; ok {
+		usesTwoHander = knownItem.HandType == proto.HandType_HandTypeTwoHand
+	}
+	if knownItem, ok := ItemsByID[itemID(proto.ItemSlot_ItemSlotOffHand)]; ok {
+		usesOffhand = knownItem.HandType == proto.HandType_HandTypeOffHand
+	}
+	if usesTwoHander && usesOffhand {
+		conflict(proto.ItemSlot_ItemSlotOffHand, "Off-hand can't be used with a two-handed main hand")
+	}
+
+	// Validate rings/trinkets for duplicates, including heroic/non-heroic
+	// versions (matching name)
+	for _, pair := range [][2]proto.ItemSlot{
+		{proto.ItemSlot_ItemSlotFinger1, proto.ItemSlot_ItemSlotFinger2},
+		{proto.ItemSlot_ItemSlotTrinket1, proto.ItemSlot_ItemSlotTrinket2},
+	} {
+		id1, id2 := itemID(pair[0]), itemID(pair[1])
+		if id1 == 0 || id2 == 0 {
+			continue
+		}
+		if id1 == id2 {
+			conflict(pair[1], "Item %d is already equipped in %s", id2, pair[0])
+			continue
+		}
+		item1, ok1 := ItemsByID[id1]
+		item2, ok2 := ItemsByID[id2]
+		if ok1 && ok2 && item1.Name == item2.Name {
+			conflict(pair[1], "%s is already equipped in %s", item2.Name, pair[0])
+		}
+	}
+
+	return issues
+}
+
+func (rv *requestValidator) validateTalents(path string, player *proto.Player) {
+	if player.TalentsString == "" {
+		return
+	}
+	treeSizes, ok := talentTreeSizes[player.Class]
+	if !ok {
+		return
+	}
+
+	trees := strings.Split(player.TalentsString, "-")
+	if len(trees) > len(treeSizes) {
+		rv.error(path, "Talent string has %d trees, expected at most %d", len(trees), len(treeSizes))
+		return
+	}
+
+	points := 0
+	for treeIdx, tree := range trees {
+		if len(tree) > treeSizes[treeIdx] {
+			rv.error(path, "Talent tree %d has %d talents, expected at most %d", treeIdx+1, len(tree), treeSizes[treeIdx])
+		}
+		for _, c := range tree {
+			if c < '0' || c > '5' {
+				rv.error(path, "Invalid talent rank %q in tree %d", c, treeIdx+1)
+				return
+			}
+			points += int(c - '0')
+		}
+	}
+	if points > MaxTalentPoints {
+		rv.error(path, "Talent string spends %d points, only %d are available", points, MaxTalentPoints)
+	}
+}
+
+func (rv *requestValidator) validateGlyphs(path string, player *proto.Player) {
+	glyphs := player.GetGlyphs()
+	if glyphs == nil {
+		return
+	}
+
+	className := strings.TrimPrefix(player.Class.String(), "Class")
+	seen := make(map[int32]bool)
+	fields := glyphs.ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		glyphID := int32(glyphs.ProtoReflect().Get(field).Int())
+		if glyphID == 0 {
+			continue
+		}
+		glyphPath := path + "." + string(field.Name())
+
+		// Fields are named e.g. prime1, and the glyphs for each type are in an
+		// enum named e.g. DruidPrimeGlyph.
+		glyphType := strings.TrimRight(string(field.Name()), "0123456789")
+		enumName := protoreflect.FullName("proto." + className + strings.ToUpper(glyphType[:1]) + glyphType[1:] + "Glyph")
+		if enumType, err := protoregistry.GlobalTypes.FindEnumByName(enumName); err == nil {
+			if enumType.Descriptor().Values().ByNumber(protoreflect.EnumNumber(glyphID)) == nil {
+				rv.error(glyphPath, "%d is not a %s %s glyph", glyphID, className, glyphType)
+				continue
+			}
+		}
+
+		if seen[glyphID] {
+			rv.warning(glyphPath, "Glyph %d is used more than once", glyphID)
+		}
+		seen[glyphID] = true
+	}
+}
+
+// Builds the environment like ComputeStats does, reporting failures as errors
+// and the APL validation warnings of each player.
+func (rv *requestValidator) validateEnvironment(rsr *proto.RaidSimRequest) {
+	var raidStats *proto.RaidStats
+	func() {
+		defer func() {
+			if err := recover(); err != nil {
+				message, _, _ := strings.Cut(fmt.Sprintf("%v", err), "\n")
+				rv.error("raid", "Sim setup failed: %s", message)
+			}
+		}()
+		encounter := rsr.Encounter
+		if encounter == nil {
+			encounter = &proto.Encounter{}
+		}
+		_, raidStats, _ = NewEnvironment(rsr.Raid, encounter, true)
+	}()
+
+	for i, party := range raidStats.GetParties() {
+		for j, player := range party.Players {
+			path := fmt.Sprintf("raid.parties[%d].players[%d].rotation", i, j)
+			for k, action := range player.GetRotationStats().GetPrepullActions() {
+				for _, warning := range action.Warnings {
+					rv.warning(fmt.Sprintf("%s.prepull_actions[%d]", path, k), "%s", warning)
+				}
+			}
+			for k, action := range player.GetRotationStats().GetPriorityList() {
+				for _, warning := range action.Warnings {
+					rv.warning(fmt.Sprintf("%s.priority_list[%d]", path, k), "%s", warning)
+				}
+			}
+			for k, variable := range player.GetRotationStats().GetVariables() {
+				for _, warning := range variable.Warnings {
+					rv.warning(fmt.Sprintf("%s.variables[%d]", path, k), "%s", warning)
+				}
+			}
+			for k, list := range player.GetRotationStats().GetActionLists() {
+				for _, warning := range list.Warnings {
+					rv.warning(fmt.Sprintf("%s.action_lists[%d]", path, k), "%s", warning)
+				}
+			}
+		}
+	}
+}
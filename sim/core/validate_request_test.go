@@ -0,0 +1,62 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+func TestValidateRequest(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerFeralCat())
+	for _, issue := range core.ValidateRequest(rsr).Issues {
+		if issue.Severity == proto.ValidationIssue_SeverityError {
+			t.Fatalf("unexpected error for valid request at %s: %s", issue.Path, issue.Message)
+		}
+	}
+}
+
+func TestValidateRequestIssues(t *testing.T) {
+	rsr := makeTestCase(getTestPlayerFeralCat())
+	player := rsr.Raid.Parties[0].Players[0]
+
+	var offHandID int32
+	for id, item := range core.ItemsByID {
+		if item.HandType == proto.HandType_HandTypeOffHand && item.Type == proto.ItemType_ItemTypeWeapon {
+			offHandID = id
+			break
+		}
+	}
+	if core.ItemsByID[player.Equipment.Items[proto.ItemSlot_ItemSlotMainHand].Id].HandType != proto.HandType_HandTypeTwoHand {
+		t.Fatalf("test gear should use a two-hander")
+	}
+
+	player.Equipment.Items[proto.ItemSlot_ItemSlotHead].Id = 999999
+	player.Equipment.Items[proto.ItemSlot_ItemSlotOffHand] = &proto.ItemSpec{Id: offHandID}
+	player.TalentsString = "-2320322312012121202301000-020301"
+	player.Glyphs.Prime1 = int32(proto.DruidMajorGlyph_GlyphOfThorns)
+	player.Rotation = nil
+
+	expected := map[string]proto.ValidationIssue_Severity{
+		"raid.parties[0].players[0].equipment.items[0]":  proto.ValidationIssue_SeverityError,
+		"raid.parties[0].players[0].equipment.items[15]": proto.ValidationIssue_SeverityError,
+		"raid.parties[0].players[0].talents_string":      proto.ValidationIssue_SeverityError,
+		"raid.parties[0].players[0].glyphs.prime1":       proto.ValidationIssue_SeverityError,
+		"raid.parties[0].players[0].rotation":            proto.ValidationIssue_SeverityWarning,
+	}
+
+	for _, issue := range core.ValidateRequest(rsr).Issues {
+		severity, ok := expected[issue.Path]
+		if !ok {
+			t.Errorf("unexpected issue at %s: %s", issue.Path, issue.Message)
+			continue
+		}
+		if issue.Severity != severity {
+			t.Errorf("expected %s at %s, got %s: %s", severity, issue.Path, issue.Severity, issue.Message)
+		}
+		delete(expected, issue.Path)
+	}
+	for path := range expected {
+		t.Errorf("expected an issue at %s", path)
+	}
+}
@@ -95,7 +95,8 @@ func (bdk *BloodDeathKnight) ApplyTalents() {
 	// Mastery: Blood Shield
 	shieldAmount := 0.0
 	currentShield := 0.0
-	shieldSpell := bdk.GetOrRegisterSpell(core.SpellConfig{
+	var shieldSpell *core.Spell
+	shieldSpell = bdk.GetOrRegisterSpell(core.SpellConfig{
 		ActionID:    core.ActionID{SpellID: 77535},
 		ProcMask:    core.ProcMaskSpellHealing,
 		SpellSchool: core.SpellSchoolPhysical,
@@ -108,6 +109,25 @@ func (bdk *BloodDeathKnight) ApplyTalents() {
 			Aura: core.Aura{
 				Label:    "Blood Shield",
 				Duration: core.NeverExpires,
+
+				OnSpellHitTaken: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
+					if !spell.SpellSchool.Matches(core.SpellSchoolPhysical) {
+						return
+					}
+
+					if currentShield <= 0 || result.Damage <= 0 {
+						return
+					}
+
+					damageReduced := min(result.Damage, currentShield)
+					currentShield -= damageReduced
+
+					bdk.GainHealth(sim, damageReduced, shieldSpell.HealthMetrics(result.Target))
+
+					if currentShield <= 0 {
+						shieldSpell.SelfShield().Deactivate(sim)
+					}
+				},
 			},
 		},
 
@@ -126,24 +146,6 @@ func (bdk *BloodDeathKnight) ApplyTalents() {
 			shieldAmount = 0.0
 			currentShield = 0.0
 		},
-		OnSpellHitTaken: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
-			if !spell.SpellSchool.Matches(core.SpellSchoolPhysical) {
-				return
-			}
-
-			if currentShield <= 0 || result.Damage <= 0 {
-				return
-			}
-
-			damageReduced := min(result.Damage, currentShield)
-			currentShield -= damageReduced
-
-			bdk.GainHealth(sim, damageReduced, shieldSpell.HealthMetrics(result.Target))
-
-			if currentShield <= 0 {
-				shieldSpell.SelfShield().Deactivate(sim)
-			}
-		},
 		OnHealDealt: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
 			if spell.ClassSpellMask&death_knight.DeathKnightSpellDeathStrikeHeal == 0 {
 				return
@@ -16,6 +16,10 @@ const (
 
 var TalentTreeSizes = [3]int{20, 20, 20}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassDeathKnight, TalentTreeSizes)
+}
+
 // Damage Done By Caster setup
 const (
 	DDBC_MercilessCombat   int = 0
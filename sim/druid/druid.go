@@ -15,6 +15,10 @@ const (
 
 var TalentTreeSizes = [3]int{20, 22, 21}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassDruid, TalentTreeSizes)
+}
+
 type Druid struct {
 	core.Character
 	SelfBuffs
@@ -204,6 +208,12 @@ func (druid *Druid) AddRaidBuffs(raidBuffs *proto.RaidBuffs) {
 	raidBuffs.MarkOfTheWild = true
 }
 
+func (druid *Druid) AddCompositionRaidBuffs(raidBuffs *proto.RaidBuffs) {
+	if druid.InForm(Moonkin) && druid.Talents.MoonkinForm {
+		raidBuffs.MoonkinForm = true
+	}
+}
+
 func (druid *Druid) BalanceCritMultiplier() float64 {
 	return druid.SpellCritMultiplier(1, 0)
 }
@@ -20,6 +20,18 @@ func (druid *Druid) registerFerociousBiteSpell() {
 	scalingPerComboPoint := 0.125
 	ripRefreshChance := 0.5 * float64(druid.Talents.BloodInTheWater)
 
+	// roll picks the damage within its spread, 0.5 being the average.
+	calcBaseDamage := func(spell *core.Spell, roll float64, excessEnergy float64) float64 {
+		comboPoints := float64(druid.ComboPoints())
+		attackPower := spell.MeleeAttackPower()
+
+		baseDamage := minBaseDamage +
+			roll*damageSpread +
+			dmgPerComboPoint*comboPoints +
+			attackPower*scalingPerComboPoint*comboPoints
+		return baseDamage * (1.0 + excessEnergy/25)
+	}
+
 	druid.FerociousBite = druid.RegisterSpell(Cat, core.SpellConfig{
 		ActionID:    core.ActionID{SpellID: 22568},
 		SpellSchool: core.SpellSchoolPhysical,
@@ -47,15 +59,8 @@ func (druid *Druid) registerFerociousBiteSpell() {
 		MaxRange:         core.MaxMeleeRange,
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-			comboPoints := float64(druid.ComboPoints())
-			attackPower := spell.MeleeAttackPower()
 			excessEnergy := min(druid.CurrentEnergy(), 25)
-
-			baseDamage := minBaseDamage +
-				sim.RandomFloat("Ferocious Bite")*damageSpread +
-				dmgPerComboPoint*comboPoints +
-				attackPower*scalingPerComboPoint*comboPoints
-			baseDamage *= 1.0 + excessEnergy/25
+			baseDamage := calcBaseDamage(spell, sim.RandomFloat("Ferocious Bite"), excessEnergy)
 
 			result := spell.CalcAndDealDamage(sim, target, baseDamage, spell.OutcomeMeleeSpecialHitAndCrit)
 
@@ -77,9 +82,7 @@ func (druid *Druid) registerFerociousBiteSpell() {
 
 		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
 			// Assume no excess Energy spend, let the user handle that
-			comboPoints := float64(druid.ComboPoints())
-			attackPower := spell.MeleeAttackPower()
-			baseDamage := avgBaseDamage + comboPoints*(dmgPerComboPoint+attackPower*scalingPerComboPoint)
+			baseDamage := calcBaseDamage(spell, 0.5, 0)
 			result := spell.CalcDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicAlwaysHit)
 			attackTable := spell.Unit.AttackTables[target.UnitIndex]
 			critChance := spell.PhysicalCritChance(attackTable)
@@ -8,6 +8,8 @@ import (
 )
 
 func (druid *Druid) registerInsectSwarmSpell() {
+	baseDamage := core.CalcScalingSpellAverageEffect(proto.Class_ClassDruid, 0.138)
+
 	druid.InsectSwarm = druid.RegisterSpell(Humanoid|Moonkin, core.SpellConfig{
 		ActionID:       core.ActionID{SpellID: 5570},
 		SpellSchool:    core.SpellSchoolNature,
@@ -40,7 +42,6 @@ func (druid *Druid) registerInsectSwarmSpell() {
 			BonusCoefficient:    0.13,
 
 			OnSnapshot: func(sim *core.Simulation, target *core.Unit, dot *core.Dot, isRollover bool) {
-				baseDamage := core.CalcScalingSpellAverageEffect(proto.Class_ClassDruid, 0.138)
 				dot.Snapshot(target, baseDamage)
 			},
 			OnTick: func(sim *core.Simulation, target *core.Unit, dot *core.Dot) {
@@ -60,7 +61,6 @@ func (druid *Druid) registerInsectSwarmSpell() {
 		},
 
 		ExpectedTickDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, useSnapshot bool) *core.SpellResult {
-			baseDamage := core.CalcScalingSpellAverageEffect(proto.Class_ClassDruid, 0.138)
 			return spell.CalcPeriodicDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicCrit)
 		},
 	})
@@ -17,6 +17,10 @@ func (druid *Druid) registerRakeSpell() {
 	// Set bonuses can scale up the ticks relative to the initial hit
 	tickDamageMultiplier := core.TernaryFloat64(druid.HasSetBonus(ItemSetStormridersBattlegarb, 2), 1.1, 1)
 
+	calcTickDamage := func(spell *core.Spell) float64 {
+		return (flatBaseDamage + 0.147*spell.MeleeAttackPower()) * tickDamageMultiplier
+	}
+
 	druid.Rake = druid.RegisterSpell(Cat, core.SpellConfig{
 		ActionID:    core.ActionID{SpellID: 1822},
 		SpellSchool: core.SpellSchoolPhysical,
@@ -47,7 +51,7 @@ func (druid *Druid) registerRakeSpell() {
 			NumberOfTicks: 3 + druid.Talents.EndlessCarnage,
 			TickLength:    time.Second * 3,
 			OnSnapshot: func(sim *core.Simulation, target *core.Unit, dot *core.Dot, isRollover bool) {
-				dot.SnapshotBaseDamage = (flatBaseDamage + 0.147*dot.Spell.MeleeAttackPower()) * tickDamageMultiplier
+				dot.SnapshotBaseDamage = calcTickDamage(dot.Spell)
 				attackTable := dot.Spell.Unit.AttackTables[target.UnitIndex]
 				dot.SnapshotCritChance = dot.Spell.PhysicalCritChance(attackTable)
 				dot.SnapshotAttackerMultiplier = dot.Spell.AttackerDamageMultiplier(attackTable, true)
@@ -74,6 +78,7 @@ func (druid *Druid) registerRakeSpell() {
 		},
 
 		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
+			// The initial hit's bleed bonus is left out of the estimate.
 			baseDamage := flatBaseDamage + 0.147*spell.MeleeAttackPower()
 			initial := spell.CalcPeriodicDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicAlwaysHit)
 
@@ -84,8 +89,7 @@ func (druid *Druid) registerRakeSpell() {
 			return initial
 		},
 		ExpectedTickDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
-			tickBase := (flatBaseDamage + 0.147*spell.MeleeAttackPower()) * tickDamageMultiplier
-			ticks := spell.CalcPeriodicDamage(sim, target, tickBase, spell.OutcomeExpectedMagicAlwaysHit)
+			ticks := spell.CalcPeriodicDamage(sim, target, calcTickDamage(spell), spell.OutcomeExpectedMagicAlwaysHit)
 
 			attackTable := spell.Unit.AttackTables[target.UnitIndex]
 			critChance := spell.PhysicalCritChance(attackTable)
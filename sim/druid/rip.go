@@ -16,6 +16,12 @@ func (druid *Druid) registerRipSpell() {
 	attackPowerCoeff := 0.0207
 	glyphMulti := core.TernaryFloat64(druid.HasPrimeGlyph(proto.DruidPrimeGlyph_GlyphOfRip), 1.15, 1.0)
 
+	calcTickDamage := func(spell *core.Spell, comboPoints int32) float64 {
+		cp := float64(comboPoints)
+		ap := spell.MeleeAttackPower()
+		return baseDamage + comboPointCoeff*cp + attackPowerCoeff*cp*ap
+	}
+
 	// Blood in the Water refreshes use the CP value from the last "raw" Rip cast, so we need to store that here.
 	var comboPointSnapshot int32
 
@@ -53,10 +59,7 @@ func (druid *Druid) registerRipSpell() {
 			TickLength:    time.Second * 2,
 
 			OnSnapshot: func(sim *core.Simulation, target *core.Unit, dot *core.Dot, isRollover bool) {
-				cp := float64(comboPointSnapshot)
-				ap := dot.Spell.MeleeAttackPower()
-
-				dot.SnapshotBaseDamage = baseDamage + comboPointCoeff*cp + attackPowerCoeff*cp*ap
+				dot.SnapshotBaseDamage = calcTickDamage(dot.Spell, comboPointSnapshot)
 
 				if !isRollover {
 					attackTable := dot.Spell.Unit.AttackTables[target.UnitIndex]
@@ -86,9 +89,7 @@ func (druid *Druid) registerRipSpell() {
 		},
 
 		ExpectedTickDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
-			cp := float64(druid.ComboPoints())
-			ap := spell.MeleeAttackPower()
-			baseTickDamage := baseDamage + comboPointCoeff*cp + attackPowerCoeff*cp*ap
+			baseTickDamage := calcTickDamage(spell, druid.ComboPoints())
 			result := spell.CalcPeriodicDamage(sim, target, baseTickDamage, spell.OutcomeExpectedMagicAlwaysHit)
 			attackTable := spell.Unit.AttackTables[target.UnitIndex]
 			critChance := spell.PhysicalCritChance(attackTable)
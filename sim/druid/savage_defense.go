@@ -20,7 +20,7 @@ func (druid *Druid) registerSavageDefensePassive() {
 
 	var shieldStrength float64
 
-	druid.AddDynamicDamageTakenModifier(func(sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
+	druid.AddDynamicDamageTakenAbsorb(func(sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
 		if druid.SavageDefenseAura.IsActive() && (result.Damage > 0) && spell.SpellSchool.Matches(core.SpellSchoolPhysical) {
 			absorbedDamage := min(shieldStrength, result.Damage)
 			result.Damage -= absorbedDamage
@@ -47,6 +47,10 @@ func (druid *Druid) registerSwipeBearSpell() {
 func (druid *Druid) registerSwipeCatSpell() {
 	weaponMulti := 5.25
 
+	calcBaseDamage := func(sim *core.Simulation, weaponDamage float64) float64 {
+		return weaponDamage * sim.Encounter.AOECapMultiplier()
+	}
+
 	druid.SwipeCat = druid.RegisterSpell(Cat, core.SpellConfig{
 		ActionID:    core.ActionID{SpellID: 62078},
 		SpellSchool: core.SpellSchoolPhysical,
@@ -69,16 +73,14 @@ func (druid *Druid) registerSwipeCatSpell() {
 		BonusCoefficient: 1,
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-			baseDamage := spell.Unit.MHWeaponDamage(sim, spell.MeleeAttackPower())
-			baseDamage *= sim.Encounter.AOECapMultiplier()
+			baseDamage := calcBaseDamage(sim, spell.Unit.MHWeaponDamage(sim, spell.MeleeAttackPower()))
 			for _, aoeTarget := range sim.Encounter.TargetUnits {
 				spell.CalcAndDealDamage(sim, aoeTarget, baseDamage, spell.OutcomeMeleeWeaponSpecialHitAndCrit)
 			}
 		},
 
 		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
-			baseDamage := spell.Unit.AutoAttacks.MH().CalculateAverageWeaponDamage(spell.MeleeAttackPower())
-			baseDamage *= sim.Encounter.AOECapMultiplier()
+			baseDamage := calcBaseDamage(sim, spell.Unit.AutoAttacks.MH().CalculateAverageWeaponDamage(spell.MeleeAttackPower()))
 			return spell.CalcDamage(sim, target, baseDamage, spell.OutcomeExpectedMeleeWeaponSpecialHitAndCrit)
 		},
 	})
@@ -18,6 +18,10 @@ func (druid *Druid) registerThrashBearSpell() {
 	flatBaseDamage := avgBaseDamage - damageSpread/2
 	flatBleedDamage := bleedCoefficient * druid.ClassSpellScaling
 
+	calcTickDamage := func(spell *core.Spell) float64 {
+		return flatBleedDamage + 0.0167*spell.MeleeAttackPower()
+	}
+
 	druid.Thrash = druid.RegisterSpell(Bear, core.SpellConfig{
 		ActionID:    core.ActionID{SpellID: 77758},
 		SpellSchool: core.SpellSchoolPhysical,
@@ -50,7 +54,7 @@ func (druid *Druid) registerThrashBearSpell() {
 			NumberOfTicks: 3,
 			TickLength:    time.Second * 2,
 			OnSnapshot: func(sim *core.Simulation, target *core.Unit, dot *core.Dot, isRollover bool) {
-				dot.SnapshotBaseDamage = flatBleedDamage + 0.0167*dot.Spell.MeleeAttackPower()
+				dot.SnapshotBaseDamage = calcTickDamage(dot.Spell)
 				attackTable := dot.Spell.Unit.AttackTables[target.UnitIndex]
 				dot.SnapshotCritChance = dot.Spell.PhysicalCritChance(attackTable)
 				dot.SnapshotAttackerMultiplier = dot.Spell.AttackerDamageMultiplier(attackTable, true)
@@ -75,6 +79,7 @@ func (druid *Druid) registerThrashBearSpell() {
 		},
 
 		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
+			// The bleed bonus and AoE cap are left out of the estimate.
 			baseDamage := avgBaseDamage + 0.0982*spell.MeleeAttackPower()
 			initial := spell.CalcPeriodicDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicAlwaysHit)
 
@@ -85,8 +90,7 @@ func (druid *Druid) registerThrashBearSpell() {
 			return initial
 		},
 		ExpectedTickDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
-			tickBase := (flatBleedDamage + 0.0167*spell.MeleeAttackPower())
-			ticks := spell.CalcPeriodicDamage(sim, target, tickBase, spell.OutcomeExpectedMagicAlwaysHit)
+			ticks := spell.CalcPeriodicDamage(sim, target, calcTickDamage(spell), spell.OutcomeExpectedMagicAlwaysHit)
 
 			attackTable := spell.Unit.AttackTables[target.UnitIndex]
 			critChance := spell.PhysicalCritChance(attackTable)
@@ -393,6 +393,17 @@ func (ai *MagmawAI) registerSpells() {
 					dot.Spell.CalcAndDealDamage(sim, aoeTarget, lavaSpewDamageRoll(sim), dot.Spell.OutcomeAlwaysHit)
 				}
 
+				// Lava Spew also hits pets whose health is modeled, which can die to it.
+				for _, party := range sim.Raid.Parties {
+					for _, player := range party.Players {
+						for _, pet := range player.GetCharacter().Pets {
+							if pet.TakesRaidDamage && pet.IsEnabled() {
+								dot.Spell.CalcAndDealDamage(sim, &pet.Unit, lavaSpewDamageRoll(sim), dot.Spell.OutcomeAlwaysHit)
+							}
+						}
+					}
+				}
+
 				// This tick delays melees by up to 300ms after it lands
 				meleeMinAt := sim.CurrentTime + time.Millisecond*300
 				nextMeleeAt := dot.Spell.Unit.AutoAttacks.NextAttackAt()
@@ -0,0 +1,41 @@
+package hunter
+
+import (
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// Core Hound pet ability, which the hunter triggers like any other cooldown.
+func (hunter *Hunter) registerAncientHysteriaCD() {
+	// Otherwise Ancient Hysteria is covered by the Bloodlust raid buff toggles.
+	if !hunter.Env.Raid.BuffsFromComposition || hunter.Pet == nil || hunter.Options.PetType != proto.HunterOptions_CoreHound {
+		return
+	}
+
+	actionID := core.ActionID{SpellID: 90355, Tag: hunter.Index}
+	ahAuras := core.RaidBloodlustAuras(hunter.Env.Raid, actionID)
+
+	spell := hunter.RegisterSpell(core.SpellConfig{
+		ActionID: actionID,
+		Flags:    core.SpellFlagAPL | core.SpellFlagNoOnCastComplete,
+		Cast: core.CastConfig{
+			CD: core.Cooldown{
+				Timer:    hunter.NewTimer(),
+				Duration: core.BloodlustCD,
+			},
+		},
+		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
+			return hunter.Pet.IsEnabled() && core.RaidMissingSated(hunter.Env.Raid)
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+			core.ActivateRaidBloodlust(sim, ahAuras)
+		},
+	})
+
+	hunter.AddMajorCooldown(core.MajorCooldown{
+		Spell:    spell,
+		Type:     core.CooldownTypeDPS,
+		Priority: core.CooldownPriorityBloodlust,
+	})
+}
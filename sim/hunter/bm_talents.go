@@ -91,7 +91,9 @@ func (hunter *Hunter) applySpiritBond() {
 			Period: time.Second * 10,
 			OnAction: func(sim *core.Simulation) {
 				hunter.GainHealth(sim, hunter.MaxHealth()*healthMultiplier, healthMetrics)
-				hunter.Pet.GainHealth(sim, hunter.Pet.MaxHealth()*healthMultiplier, petHealthMetrics)
+				if hunter.Pet.IsEnabled() {
+					hunter.Pet.GainHealth(sim, hunter.Pet.MaxHealth()*healthMultiplier, petHealthMetrics)
+				}
 			},
 		})
 	})
@@ -268,6 +270,9 @@ func (hunter *Hunter) applyFocusFireCD() {
 				Duration: time.Second * 15,
 			},
 		},
+		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
+			return hunter.Pet.IsEnabled()
+		},
 
 		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
 			if focusFireAura.IsActive() {
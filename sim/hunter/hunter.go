@@ -10,6 +10,10 @@ import (
 
 var TalentTreeSizes = [3]int{19, 19, 20}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassHunter, TalentTreeSizes)
+}
+
 const ThoridalTheStarsFuryItemID = 34334
 
 type Hunter struct {
@@ -158,6 +162,8 @@ func (hunter *Hunter) RegisterSpells() {
 	hunter.registerExplosiveTrapSpell(hunter.FireTrapTimer)
 	hunter.registerCobraShotSpell()
 	hunter.registerRapidFireCD()
+	hunter.registerPetRevivalSpells()
+	hunter.registerAncientHysteriaCD()
 	hunter.registerSilencingShotSpell()
 	hunter.registerRaptorStrikeSpell()
 	hunter.registerTrapLauncher()
@@ -32,6 +32,10 @@ func (hunter *Hunter) registerKillCommandSpell() {
 				Duration: time.Second * 6,
 			},
 		},
+		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
+			return hunter.Pet.IsEnabled()
+		},
+
 		DamageMultiplierAdditive: 1,
 		CritMultiplier:           hunter.CritMultiplier(false, false, false),
 		ThreatMultiplier:         1,
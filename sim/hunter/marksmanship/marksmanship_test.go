@@ -81,6 +81,51 @@ func BenchmarkSimulate(b *testing.B) {
 	core.RaidBenchmark(b, rsr)
 }
 
+func statWeightsRequest() *proto.StatWeightsRequest {
+	return &proto.StatWeightsRequest{
+		Player: &proto.Player{
+			Race:           proto.Race_RaceOrc,
+			Class:          proto.Class_ClassHunter,
+			Equipment:      core.GetGearSet("../../../ui/hunter/marksmanship/gear_sets", "preraid_mm").GearSet,
+			Consumes:       FullConsumes,
+			Spec:           PlayerOptionsBasic,
+			Glyphs:         MMGlyphs,
+			TalentsString:  MMTalents,
+			Rotation:       core.GetAplRotation("../../../ui/hunter/marksmanship/apls", "mm").Rotation,
+			Buffs:          core.FullIndividualBuffs,
+			ReactionTimeMs: 100,
+		},
+		RaidBuffs:  core.FullRaidBuffs,
+		PartyBuffs: core.FullPartyBuffs,
+		Debuffs:    core.FullDebuffs,
+		Encounter:  core.MakeSingleTargetEncounter(0),
+		SimOptions: core.StatWeightsDefaultSimTestOptions,
+
+		StatsToWeigh: []proto.Stat{
+			proto.Stat_StatAgility,
+			proto.Stat_StatRangedAttackPower,
+			proto.Stat_StatMeleeHit,
+			proto.Stat_StatMeleeCrit,
+			proto.Stat_StatMeleeHaste,
+			proto.Stat_StatMastery,
+		},
+		EpReferenceStat: proto.Stat_StatRangedAttackPower,
+	}
+}
+
+func TestMMStatWeightsEnvReuse(t *testing.T) {
+	core.StatWeightsEnvReuseTest(t.Name(), t, statWeightsRequest())
+}
+
+func BenchmarkStatWeights(b *testing.B) {
+	b.Run("Rebuild", func(b *testing.B) {
+		core.StatWeightsBenchmark(b, statWeightsRequest(), false)
+	})
+	b.Run("Reuse", func(b *testing.B) {
+		core.StatWeightsBenchmark(b, statWeightsRequest(), true)
+	})
+}
+
 var FullConsumes = &proto.Consumes{
 	Flask:         proto.Flask_FlaskOfTheWinds,
 	DefaultPotion: proto.Potions_PotionOfTheTolvir,
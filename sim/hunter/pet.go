@@ -23,6 +23,7 @@ type HunterPet struct {
 	exoticAbility  *core.Spell
 
 	uptimePercent    float64
+	isDead           bool
 	wolverineBite    *core.Spell
 	frostStormBreath *core.Spell
 	hasOwnerCooldown bool
@@ -119,10 +120,16 @@ func (hp *HunterPet) Initialize() {
 	hp.specialAbility = hp.NewPetAbility(hp.config.SpecialAbility, true)
 	hp.focusDump = hp.NewPetAbility(hp.config.FocusDump, false)
 	hp.exoticAbility = hp.NewPetAbility(hp.config.ExoticAbility, false)
+
+	if hp.hunterOwner.Options.ModelPetHealth {
+		hp.TakesRaidDamage = true
+		hp.registerPetHealth()
+	}
 }
 
 func (hp *HunterPet) Reset(_ *core.Simulation) {
 	hp.uptimePercent = min(1, max(0, hp.hunterOwner.Options.PetUptime))
+	hp.isDead = false
 }
 
 func (hp *HunterPet) ExecuteCustomRotation(sim *core.Simulation) {
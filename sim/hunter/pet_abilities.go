@@ -231,6 +231,10 @@ func (hp *HunterPet) newSpecialAbility(config PetSpecialAbilityConfig) *core.Spe
 	})
 }
 
+func frostStormTickDamage(spell *core.Spell) float64 {
+	return 206 + (spell.MeleeAttackPower() * 0.40)
+}
+
 func (hp *HunterPet) getFrostStormTickSpell() *core.Spell {
 	config := core.SpellConfig{
 		ActionID:    core.ActionID{SpellID: 92380},
@@ -246,8 +250,7 @@ func (hp *HunterPet) getFrostStormTickSpell() *core.Spell {
 		CritMultiplier:           hp.DefaultSpellCritMultiplier(),
 	}
 	config.ApplyEffects = func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-		damage := 206 + (spell.MeleeAttackPower() * 0.40)
-		spell.CalcAndDealDamage(sim, target, damage, spell.OutcomeMagicHitAndCrit)
+		spell.CalcAndDealDamage(sim, target, frostStormTickDamage(spell), spell.OutcomeMagicHitAndCrit)
 	}
 	return hp.RegisterSpell(config)
 }
@@ -293,8 +296,7 @@ func (hp *HunterPet) newFrostStormBreath() *core.Spell {
 			}
 		},
 		ExpectedTickDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
-			baseDamage := 206 + (spell.MeleeAttackPower() * 0.40)
-			return spell.CalcPeriodicDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicCrit)
+			return spell.CalcPeriodicDamage(sim, target, frostStormTickDamage(spell), spell.OutcomeExpectedMagicCrit)
 		},
 	})
 	return hp.frostStormBreath
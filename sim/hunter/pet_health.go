@@ -0,0 +1,168 @@
+package hunter
+
+import (
+	"time"
+
+	"github.com/wowsims/cata/sim/core"
+)
+
+// The pet takes damage like any other unit and dies at 0 health, after which
+// it stays dismissed until the hunter brings it back.
+func (hp *HunterPet) registerPetHealth() {
+	takeDamage := func(sim *core.Simulation, damage float64) {
+		if damage <= 0 || hp.isDead {
+			return
+		}
+
+		hp.RemoveHealth(sim, damage)
+		if hp.CurrentHealth() > 0 {
+			return
+		}
+
+		hp.isDead = true
+		// Dismiss after the current damage event has finished processing.
+		core.StartDelayedAction(sim, core.DelayedActionOptions{
+			DoAt: sim.CurrentTime,
+			OnAction: func(sim *core.Simulation) {
+				if sim.Log != nil {
+					hp.Log(sim, "Dead")
+				}
+				hp.Disable(sim)
+			},
+		})
+	}
+
+	healthAura := hp.RegisterAura(core.Aura{
+		Label:    "Pet Health",
+		Duration: core.NeverExpires,
+		OnSpellHitTaken: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
+			takeDamage(sim, result.Damage)
+		},
+		OnPeriodicDamageTaken: func(aura *core.Aura, sim *core.Simulation, spell *core.Spell, result *core.SpellResult) {
+			takeDamage(sim, result.Damage)
+		},
+	})
+
+	hp.OnPetEnable = func(sim *core.Simulation) {
+		hp.SetHealthPercent(sim, 1)
+		healthAura.Activate(sim)
+	}
+}
+
+func (hp *HunterPet) IsDead() bool {
+	return hp.isDead
+}
+
+func (hunter *Hunter) registerPetRevivalSpells() {
+	if hunter.Pet == nil {
+		return
+	}
+	hp := hunter.Pet
+
+	hunter.RegisterSpell(core.SpellConfig{
+		ActionID: core.ActionID{SpellID: 982},
+		Flags:    core.SpellFlagAPL | core.SpellFlagHelpful,
+
+		FocusCost: core.FocusCostOptions{
+			Cost: 35,
+		},
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				GCD:      core.GCDDefault,
+				CastTime: time.Second * 6,
+			},
+		},
+		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
+			return hp.isDead
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+			hp.isDead = false
+			hp.Enable(sim, hp)
+		},
+	})
+
+	// Resummons the pet after it was dismissed, e.g. by the pet uptime setting.
+	hunter.RegisterSpell(core.SpellConfig{
+		ActionID: core.ActionID{SpellID: 883},
+		Flags:    core.SpellFlagAPL | core.SpellFlagHelpful,
+
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				GCD: core.GCDDefault,
+			},
+		},
+		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
+			return !hp.isDead && !hp.IsEnabled()
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+			hp.Enable(sim, hp)
+		},
+	})
+
+	// Heart of the Phoenix is a pet ability which can be used while the pet is dead.
+	if hp.Talents().HeartOfThePhoenix {
+		hunter.RegisterSpell(core.SpellConfig{
+			ActionID: core.ActionID{SpellID: 55709},
+			Flags:    core.SpellFlagAPL | core.SpellFlagHelpful,
+
+			Cast: core.CastConfig{
+				CD: core.Cooldown{
+					Timer:    hunter.NewTimer(),
+					Duration: time.Minute * 8,
+				},
+			},
+			ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
+				return hp.isDead
+			},
+
+			ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+				hp.isDead = false
+				hp.Enable(sim, hp)
+			},
+		})
+	}
+
+	mendPetActionID := core.ActionID{SpellID: 136}
+	mendPetMetrics := hp.NewHealthMetrics(mendPetActionID)
+	var mendPetTicks *core.PendingAction
+	mendPetAura := hp.RegisterAura(core.Aura{
+		Label:    "Mend Pet",
+		ActionID: mendPetActionID,
+		Duration: time.Second * 10,
+		OnGain: func(aura *core.Aura, sim *core.Simulation) {
+			// Heals 25% of the pet's health over the duration.
+			mendPetTicks = core.StartPeriodicAction(sim, core.PeriodicActionOptions{
+				Period:   time.Second * 2,
+				NumTicks: 5,
+				OnAction: func(sim *core.Simulation) {
+					hp.GainHealth(sim, hp.MaxHealth()*0.05*hp.PseudoStats.HealingTakenMultiplier, mendPetMetrics)
+				},
+			})
+		},
+		OnExpire: func(aura *core.Aura, sim *core.Simulation) {
+			mendPetTicks.Cancel(sim)
+		},
+	})
+
+	hunter.RegisterSpell(core.SpellConfig{
+		ActionID: mendPetActionID,
+		Flags:    core.SpellFlagAPL | core.SpellFlagHelpful,
+
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				GCD: core.GCDDefault,
+			},
+		},
+		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
+			return hp.IsEnabled()
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+			// Recasting restarts the heal.
+			mendPetAura.Deactivate(sim)
+			mendPetAura.Activate(sim)
+		},
+	})
+}
@@ -0,0 +1,152 @@
+package hunter_test
+
+import (
+	"testing"
+
+	_ "github.com/wowsims/cata/sim/common" // imported to get item effects included.
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+	_ "github.com/wowsims/cata/sim/encounters"
+	"github.com/wowsims/cata/sim/hunter/beast_mastery"
+	"github.com/wowsims/cata/sim/hunter/marksmanship"
+)
+
+func init() {
+	beast_mastery.RegisterBeastMasteryHunter()
+	marksmanship.RegisterMarksmanshipHunter()
+}
+
+func TestPetDiesAndIsRevived(t *testing.T) {
+	const revivePetID = 982
+
+	rotation := core.GetAplRotation("../../ui/hunter/marksmanship/apls", "mm").Rotation
+	rotation.PriorityList = append([]*proto.APLListItem{{
+		Action: &proto.APLAction{Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{
+			SpellId: &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: revivePetID}},
+		}}},
+	}}, rotation.PriorityList...)
+
+	// Magmaw's Lava Spew hits the whole raid, including pets.
+	magmaw := core.GetPresetTargetWithID(41573)
+	if magmaw == nil {
+		t.Fatalf("missing Magmaw preset")
+	}
+
+	rsr := &proto.RaidSimRequest{
+		Raid: core.SinglePlayerRaidProto(
+			&proto.Player{
+				Race:      proto.Race_RaceOrc,
+				Class:     proto.Class_ClassHunter,
+				Equipment: core.GetGearSet("../../ui/hunter/marksmanship/gear_sets", "preraid_mm").GearSet,
+				Spec: &proto.Player_MarksmanshipHunter{
+					MarksmanshipHunter: &proto.MarksmanshipHunter{
+						Options: &proto.MarksmanshipHunter_Options{
+							ClassOptions: &proto.HunterOptions{
+								PetType:        proto.HunterOptions_Wolf,
+								PetUptime:      1,
+								ModelPetHealth: true,
+							},
+						},
+					},
+				},
+				TalentsString:  "032002-2302320032120231221-03",
+				Rotation:       rotation,
+				Buffs:          core.FullIndividualBuffs,
+				ReactionTimeMs: 100,
+			},
+			core.FullPartyBuffs,
+			core.FullRaidBuffs,
+			core.FullDebuffs),
+		Encounter: &proto.Encounter{
+			Duration: 300,
+			Targets:  []*proto.Target{magmaw.Config},
+		},
+		SimOptions: &proto.SimOptions{
+			Iterations: 5,
+			IsTest:     true,
+			RandomSeed: 101,
+		},
+	}
+
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+
+	var revives int32
+	for _, action := range result.RaidMetrics.Parties[0].Players[0].Actions {
+		if action.Id.GetSpellId() == revivePetID {
+			for _, target := range action.Targets {
+				revives += target.Casts
+			}
+		}
+	}
+	if revives == 0 {
+		t.Errorf("expected the pet to die and be revived")
+	}
+}
+
+func bmCastsWithPetUptime(t *testing.T, petUptime float64) map[int32]int32 {
+	rsr := &proto.RaidSimRequest{
+		Raid: core.SinglePlayerRaidProto(
+			&proto.Player{
+				Race:      proto.Race_RaceOrc,
+				Class:     proto.Class_ClassHunter,
+				Equipment: core.GetGearSet("../../ui/hunter/beast_mastery/gear_sets", "preraid_bm").GearSet,
+				Spec: &proto.Player_BeastMasteryHunter{
+					BeastMasteryHunter: &proto.BeastMasteryHunter{
+						Options: &proto.BeastMasteryHunter_Options{
+							ClassOptions: &proto.HunterOptions{
+								PetType:   proto.HunterOptions_Wolf,
+								PetUptime: petUptime,
+							},
+						},
+					},
+				},
+				TalentsString:  "2330230311320112121-2302-03",
+				Rotation:       core.GetAplRotation("../../ui/hunter/beast_mastery/apls", "bm").Rotation,
+				Buffs:          core.FullIndividualBuffs,
+				ReactionTimeMs: 100,
+			},
+			core.FullPartyBuffs,
+			core.FullRaidBuffs,
+			core.FullDebuffs),
+		Encounter: core.MakeSingleTargetEncounter(0),
+		SimOptions: &proto.SimOptions{
+			Iterations: 5,
+			IsTest:     true,
+			RandomSeed: 101,
+		},
+	}
+
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		t.Fatalf("sim failed: %s", result.ErrorResult)
+	}
+
+	casts := make(map[int32]int32)
+	for _, action := range result.RaidMetrics.Parties[0].Players[0].Actions {
+		for _, target := range action.Targets {
+			casts[action.Id.GetSpellId()] += target.Casts
+		}
+	}
+	return casts
+}
+
+func TestPetAbilitiesNeedLivePet(t *testing.T) {
+	const killCommandID = 34026
+	const focusFireID = 82692
+
+	fullUptime := bmCastsWithPetUptime(t, 1)
+	halfUptime := bmCastsWithPetUptime(t, 0.5)
+
+	for _, spellID := range []int32{killCommandID, focusFireID} {
+		if fullUptime[spellID] == 0 {
+			t.Fatalf("expected casts of %d with the pet always up", spellID)
+		}
+		// The pet is dismissed for the second half of the fight.
+		if halfUptime[spellID] > fullUptime[spellID]*6/10 {
+			t.Errorf("expected %d to need a live pet: %d casts with half pet uptime, %d with full", spellID, halfUptime[spellID], fullUptime[spellID])
+		}
+	}
+}
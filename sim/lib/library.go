@@ -3,16 +3,15 @@ package main
 // #include <stdlib.h>
 import "C"
 import (
-	"bytes"
-	"compress/zlib"
-	"encoding/base64"
 	"encoding/json"
 	"log"
+	"sync"
 	"unsafe"
 
 	"github.com/wowsims/cata/sim"
 	"github.com/wowsims/cata/sim/core"
 	"github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/simsettings"
 	"google.golang.org/protobuf/encoding/protojson"
 	goproto "google.golang.org/protobuf/proto"
 )
@@ -80,15 +79,51 @@ func encodeSettings(json *C.char) *C.char {
 		Player:     input.Raid.Parties[0].Players[0],
 		Encounter:  input.Encounter,
 	}
-	var buffer bytes.Buffer
-	data, err := goproto.Marshal(settings)
+	out, err := simsettings.Encode(settings)
+	if err != nil {
+		panic(err)
+	}
+	return C.CString(out)
+}
+
+// Like encodeSettings, but keeps the whole request: raid sims and requests with
+// several players are encoded as RaidSimSettings.
+//
+//export encodeSimSettings
+func encodeSimSettings(json *C.char) *C.char {
+	input := &proto.RaidSimRequest{}
+	err := protojson.Unmarshal([]byte(C.GoString(json)), input)
+	if err != nil {
+		log.Fatalf("failed to load input json file: %s", err)
+	}
+	out, err := simsettings.Encode(simsettings.RequestToSettings(input))
+	if err != nil {
+		panic(err)
+	}
+	return C.CString(out)
+}
+
+// Decodes a share link and returns the JSON ConvertSettingsResult with the
+// requests the UI would send for it.
+//
+//export decodeLink
+func decodeLink(link *C.char) *C.char {
+	sim.RegisterAll()
+	result := &proto.ConvertSettingsResult{}
+	settings, err := simsettings.DecodeLink(C.GoString(link))
+	switch settings := settings.(type) {
+	case *proto.IndividualSimSettings:
+		result = simsettings.Convert(&proto.ConvertSettingsRequest{Settings: &proto.ConvertSettingsRequest_IndividualSettings{IndividualSettings: settings}})
+	case *proto.RaidSimSettings:
+		result = simsettings.Convert(&proto.ConvertSettingsRequest{Settings: &proto.ConvertSettingsRequest_RaidSettings{RaidSettings: settings}})
+	}
+	if err != nil {
+		result.ErrorResult = err.Error()
+	}
+	out, err := protojson.Marshal(result)
 	if err != nil {
 		panic(err)
 	}
-	writer := zlib.NewWriter(&buffer)
-	writer.Write(data)
-	writer.Close()
-	out := base64.StdEncoding.EncodeToString(buffer.Bytes())
 	return C.CString(string(out))
 }
 
@@ -171,17 +206,6 @@ func trySpell(act int) bool {
 	target := player.GetCharacter().CurrentTarget
 	casted := false
 
-	// FIXME : This is a hack to allow Heroic strike to work
-	if spell.ActionID.SpellID == 47450 {
-		aura := player.GetCharacter().GetAura("HS Queue Aura")
-		if aura.IsActive() {
-			return false
-		}
-		aura.Activate(_active_sim)
-		return true
-	}
-	// End of Heroic strike hack
-
 	if spell.CanCast(_active_sim, target) {
 		casted = spell.Cast(_active_sim, target)
 		if casted && spell.CurCast.GCD > 0 {
@@ -352,6 +376,95 @@ func cleanup() {
 	_active_sim.Cleanup()
 }
 
+var _rl_environments = map[int32]*core.RLEnvironment{}
+var _rl_next_handle int32 = 1
+var _rl_lock sync.Mutex
+
+func getRLEnvironment(handle int32) *core.RLEnvironment {
+	_rl_lock.Lock()
+	defer _rl_lock.Unlock()
+	return _rl_environments[handle]
+}
+
+func marshalRLResult(result goproto.Message) *C.char {
+	out, err := protojson.Marshal(result)
+	if err != nil {
+		panic(err)
+	}
+	return C.CString(string(out))
+}
+
+// Creates a new reinforcement learning environment from a JSON
+// RLEnvironmentSettings and returns its handle, or -1 if the settings are
+// invalid. Each handle can be used from its own thread.
+//
+//export rlNew
+func rlNew(json *C.char) int32 {
+	input := &proto.RLEnvironmentSettings{}
+	jsonString := C.GoString(json)
+	err := protojson.Unmarshal([]byte(jsonString), input)
+	if err != nil {
+		log.Printf("failed to load input json: %s", err)
+		return -1
+	}
+	sim.RegisterAll()
+	rlEnv, err := core.NewRLEnvironment(input)
+	if err != nil {
+		log.Printf("failed to create environment: %s", err)
+		return -1
+	}
+
+	_rl_lock.Lock()
+	defer _rl_lock.Unlock()
+	handle := _rl_next_handle
+	_rl_next_handle += 1
+	_rl_environments[handle] = rlEnv
+	return handle
+}
+
+// Returns the JSON RLEnvironmentInfo describing the action space and observed
+// auras of an environment.
+//
+//export rlInfo
+func rlInfo(handle int32) *C.char {
+	rlEnv := getRLEnvironment(handle)
+	if rlEnv == nil {
+		return nil
+	}
+	return marshalRLResult(rlEnv.Info())
+}
+
+// Starts a new episode and returns the first JSON RLObservation. A negative
+// seed continues with the next episode seed.
+//
+//export rlReset
+func rlReset(handle int32, seed int64) *C.char {
+	rlEnv := getRLEnvironment(handle)
+	if rlEnv == nil {
+		return nil
+	}
+	return marshalRLResult(rlEnv.Reset(seed))
+}
+
+// Performs an action and returns the JSON RLStepResult. The last action index
+// (the size of the action space) waits instead of casting.
+//
+//export rlStep
+func rlStep(handle int32, action int32) *C.char {
+	rlEnv := getRLEnvironment(handle)
+	if rlEnv == nil {
+		return marshalRLResult(&proto.RLStepResult{ErrorResult: "invalid environment handle"})
+	}
+	return marshalRLResult(rlEnv.Step(int(action)))
+}
+
+//export rlClose
+func rlClose(handle int32) {
+	_rl_lock.Lock()
+	defer _rl_lock.Unlock()
+	delete(_rl_environments, handle)
+}
+
 //export FreeCString
 func FreeCString(s *C.char) {
 	C.free(unsafe.Pointer(s))
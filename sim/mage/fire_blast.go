@@ -7,6 +7,8 @@ import (
 )
 
 func (mage *Mage) registerFireBlastSpell() {
+	baseDamage := 1.113 * mage.ClassSpellScaling
+
 	mage.FireBlast = mage.RegisterSpell(core.SpellConfig{
 		ActionID:       core.ActionID{SpellID: 2136},
 		SpellSchool:    core.SpellSchoolFire,
@@ -33,8 +35,10 @@ func (mage *Mage) registerFireBlastSpell() {
 		ThreatMultiplier:         1,
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-			baseDamage := 1.113 * mage.ClassSpellScaling
 			spell.CalcAndDealDamage(sim, target, baseDamage, spell.OutcomeMagicHitAndCrit)
 		},
+		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
+			return spell.CalcDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicHitAndCrit)
+		},
 	})
 }
@@ -21,6 +21,10 @@ func (mage *Mage) applyFocusMagic() {
 	}
 
 	focusMagicTarget := mage.GetUnit(mage.ArcaneOptions.FocusMagicTarget)
+	if focusMagicTarget == nil && mage.Env.Raid.BuffsFromComposition {
+		// Without an explicit target, Focus Magic goes to a party member.
+		focusMagicTarget = mage.Party.DefaultPartner(&mage.Unit)
+	}
 	if focusMagicTarget == nil {
 		return
 	} else if focusMagicTarget == &mage.Unit {
@@ -10,6 +10,10 @@ import (
 
 var TalentTreeSizes = [3]int{21, 21, 19}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassMage, TalentTreeSizes)
+}
+
 type Mage struct {
 	core.Character
 
@@ -73,6 +77,12 @@ func (mage *Mage) AddRaidBuffs(raidBuffs *proto.RaidBuffs) {
 	raidBuffs.ArcaneBrilliance = true
 }
 
+func (mage *Mage) AddCompositionRaidBuffs(raidBuffs *proto.RaidBuffs) {
+	if mage.Talents.ArcaneTactics {
+		raidBuffs.ArcaneTactics = true
+	}
+}
+
 func (mage *Mage) AddPartyBuffs(partyBuffs *proto.PartyBuffs) {
 }
 
@@ -109,6 +119,7 @@ func (mage *Mage) Initialize() {
 	mage.registerEvocation()
 	mage.registerManaGemsCD()
 	mage.registerMirrorImageCD()
+	mage.registerTimeWarpCD()
 	mage.registerCombustionSpell()
 	mage.registerBlastWaveSpell()
 	mage.registerDragonsBreathSpell()
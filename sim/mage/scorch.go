@@ -7,6 +7,8 @@ import (
 )
 
 func (mage *Mage) registerScorchSpell() {
+	baseDamage := 0.781 * mage.ClassSpellScaling
+
 	mage.RegisterSpell(core.SpellConfig{
 		ActionID:       core.ActionID{SpellID: 2948},
 		SpellSchool:    core.SpellSchoolFire,
@@ -33,9 +35,11 @@ func (mage *Mage) registerScorchSpell() {
 		ThreatMultiplier: 1,
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-			baseDamage := 0.781 * mage.ClassSpellScaling
 			result := spell.CalcDamage(sim, target, baseDamage, spell.OutcomeMagicHitAndCrit)
 			spell.DealDamage(sim, result)
 		},
+		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
+			return spell.CalcDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicHitAndCrit)
+		},
 	})
 }
@@ -0,0 +1,46 @@
+package mage
+
+import (
+	"github.com/wowsims/cata/sim/core"
+)
+
+func (mage *Mage) registerTimeWarpCD() {
+	// Otherwise Time Warp is covered by the Bloodlust raid buff toggles.
+	if !mage.Env.Raid.BuffsFromComposition {
+		return
+	}
+
+	actionID := core.ActionID{SpellID: 80353, Tag: mage.Index}
+	twAuras := core.RaidBloodlustAuras(mage.Env.Raid, actionID)
+
+	spell := mage.RegisterSpell(core.SpellConfig{
+		ActionID:    actionID,
+		SpellSchool: core.SpellSchoolArcane,
+		Flags:       core.SpellFlagAPL,
+		ManaCost: core.ManaCostOptions{
+			BaseCost: 0.16,
+		},
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				GCD: core.GCDDefault,
+			},
+			CD: core.Cooldown{
+				Timer:    mage.NewTimer(),
+				Duration: core.BloodlustCD,
+			},
+		},
+		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
+			return core.RaidMissingSated(mage.Env.Raid)
+		},
+
+		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
+			core.ActivateRaidBloodlust(sim, twAuras)
+		},
+	})
+
+	mage.AddMajorCooldown(core.MajorCooldown{
+		Spell:    spell,
+		Type:     core.CooldownTypeDPS,
+		Priority: core.CooldownPriorityBloodlust,
+	})
+}
@@ -44,6 +44,10 @@ const SpellMaskSingleTarget = SpellMaskCrusaderStrike | SpellMaskTemplarsVerdict
 
 var TalentTreeSizes = [3]int{20, 20, 20}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassPaladin, TalentTreeSizes)
+}
+
 type Paladin struct {
 	core.Character
 	HolyPowerBar
@@ -145,7 +149,19 @@ func (paladin *Paladin) AddRaidBuffs(raidBuffs *proto.RaidBuffs) {
 	//}
 }
 
-func (paladin *Paladin) AddPartyBuffs(_ *proto.PartyBuffs) {
+func (paladin *Paladin) AddCompositionRaidBuffs(raidBuffs *proto.RaidBuffs) {
+	if paladin.Talents.Communion {
+		raidBuffs.Communion = true
+	}
+}
+
+func (paladin *Paladin) AddPartyBuffs(partyBuffs *proto.PartyBuffs) {
+	switch paladin.PaladinAura {
+	case proto.PaladinAura_DevotionAura:
+		partyBuffs.DevotionAura = true
+	case proto.PaladinAura_RetributionAura:
+		partyBuffs.RetributionAura = true
+	}
 }
 
 func (paladin *Paladin) Initialize() {
@@ -8,6 +8,8 @@ import (
 
 func (priest *Priest) registerDevouringPlagueSpell() {
 	actionID := core.ActionID{SpellID: 2944, Tag: 0}
+	baseDamage := 0.144 * priest.ClassSpellScaling
+
 	priest.DevouringPlague = priest.RegisterSpell(core.SpellConfig{
 		ActionID:       actionID,
 		SpellSchool:    core.SpellSchoolShadow,
@@ -41,7 +43,7 @@ func (priest *Priest) registerDevouringPlagueSpell() {
 			BonusCoefficient: 0.163,
 
 			OnSnapshot: func(sim *core.Simulation, target *core.Unit, dot *core.Dot, _ bool) {
-				dot.Snapshot(target, 0.144*priest.ClassSpellScaling)
+				dot.Snapshot(target, baseDamage)
 			},
 			OnTick: func(sim *core.Simulation, target *core.Unit, dot *core.Dot) {
 				dot.CalcAndDealPeriodicSnapshotDamage(sim, target, dot.OutcomeSnapshotCrit)
@@ -63,7 +65,6 @@ func (priest *Priest) registerDevouringPlagueSpell() {
 				dot := spell.Dot(target)
 				return dot.CalcSnapshotDamage(sim, target, dot.OutcomeExpectedMagicSnapshotCrit)
 			} else {
-				baseDamage := 0.144 * priest.ClassSpellScaling
 				return spell.CalcPeriodicDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicCrit)
 			}
 		},
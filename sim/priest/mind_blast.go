@@ -7,6 +7,9 @@ import (
 )
 
 func (priest *Priest) registerMindBlastSpell() {
+	coefficient := 1.557
+	variance := 0.055
+
 	priest.RegisterSpell(core.SpellConfig{
 		ActionID:       core.ActionID{SpellID: 8092},
 		SpellSchool:    core.SpellSchoolShadow,
@@ -37,12 +40,13 @@ func (priest *Priest) registerMindBlastSpell() {
 		BonusCoefficient: 1.104,
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-			baseDamage := priest.calcBaseDamage(sim, 1.557, 0.055)
+			baseDamage := priest.calcBaseDamage(sim, coefficient, variance)
 			result := spell.CalcDamage(sim, target, baseDamage, spell.OutcomeMagicHitAndCrit)
 			spell.DealDamage(sim, result)
 		},
 		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
-			baseDamage := priest.calcBaseDamage(sim, 1.557, 0.055)
+			// Without variance, so the expectation is the average and rolls nothing.
+			baseDamage := priest.calcBaseDamage(sim, coefficient, 0)
 			return spell.CalcDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicHitAndCrit)
 		},
 	})
@@ -7,6 +7,8 @@ import (
 )
 
 func (priest *Priest) newMindFlaySpell() *core.Spell {
+	baseDamage := 187.147
+
 	return priest.RegisterSpell(core.SpellConfig{
 		ActionID:       core.ActionID{SpellID: 15407},
 		SpellSchool:    core.SpellSchoolShadow,
@@ -37,7 +39,7 @@ func (priest *Priest) newMindFlaySpell() *core.Spell {
 			AffectedByCastSpeed: true,
 			BonusCoefficient:    0.288,
 			OnSnapshot: func(sim *core.Simulation, target *core.Unit, dot *core.Dot, isRollover bool) {
-				dot.Snapshot(target, 187.147)
+				dot.Snapshot(target, baseDamage)
 			},
 			OnTick: func(sim *core.Simulation, target *core.Unit, dot *core.Dot) {
 				dot.CalcAndDealPeriodicSnapshotDamage(sim, target, dot.OutcomeSnapshotCrit)
@@ -51,7 +53,6 @@ func (priest *Priest) newMindFlaySpell() *core.Spell {
 			}
 		},
 		ExpectedTickDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
-			baseDamage := 187.147
 			return spell.CalcPeriodicDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicCrit)
 		},
 	})
@@ -19,12 +19,15 @@ func (priest *Priest) getMindSearBaseConfig() core.SpellConfig {
 	}
 }
 
+func (priest *Priest) mindSearTickDamage() float64 {
+	return priest.ClassSpellScaling * 0.23
+}
+
 func (priest *Priest) getMindSearTickSpell() *core.Spell {
 	config := priest.getMindSearBaseConfig()
 	config.ActionID = core.ActionID{SpellID: 48045}
 	config.ApplyEffects = func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-		damage := priest.ClassSpellScaling * 0.23
-		spell.CalcAndDealDamage(sim, target, damage, spell.OutcomeMagicHitAndCrit)
+		spell.CalcAndDealDamage(sim, target, priest.mindSearTickDamage(), spell.OutcomeMagicHitAndCrit)
 	}
 	return priest.RegisterSpell(config)
 }
@@ -67,8 +70,7 @@ func (priest *Priest) newMindSearSpell() *core.Spell {
 		}
 	}
 	config.ExpectedTickDamage = func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
-		baseDamage := priest.ClassSpellScaling * 0.23
-		return spell.CalcPeriodicDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicCrit)
+		return spell.CalcPeriodicDamage(sim, target, priest.mindSearTickDamage(), spell.OutcomeExpectedMagicCrit)
 	}
 
 	return priest.RegisterSpell(config)
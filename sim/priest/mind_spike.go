@@ -33,6 +33,9 @@ func (priest *Priest) registerMindSpike() {
 		},
 	})
 
+	coefficient := 1.178
+	variance := 0.055
+
 	priest.RegisterSpell(core.SpellConfig{
 		ActionID:       core.ActionID{SpellID: 73510},
 		SpellSchool:    core.SpellSchoolShadow,
@@ -59,7 +62,7 @@ func (priest *Priest) registerMindSpike() {
 		BonusCoefficient: 0.8355,
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-			baseDamage := priest.calcBaseDamage(sim, 1.178, 0.055)
+			baseDamage := priest.calcBaseDamage(sim, coefficient, variance)
 			result := spell.CalcAndDealDamage(sim, target, baseDamage, spell.OutcomeMagicHitAndCrit)
 			if result.Outcome.Matches(core.OutcomeLanded) {
 				priest.ShadowWordPain.Dot(target).Deactivate(sim)
@@ -76,7 +79,8 @@ func (priest *Priest) registerMindSpike() {
 			}
 		},
 		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
-			baseDamage := priest.calcBaseDamage(sim, 1.557, 0.055)
+			// Without variance, so the expectation is the average and rolls nothing.
+			baseDamage := priest.calcBaseDamage(sim, coefficient, 0)
 			return spell.CalcDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicHitAndCrit)
 		},
 	})
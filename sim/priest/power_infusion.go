@@ -12,6 +12,9 @@ func (priest *Priest) registerPowerInfusionSpell() {
 	actionID := core.ActionID{SpellID: 10060, Tag: priest.Index}
 
 	powerInfusionTarget := priest.GetUnit(priest.SelfBuffs.PowerInfusionTarget)
+	if powerInfusionTarget == nil && priest.Env.Raid.BuffsFromComposition {
+		powerInfusionTarget = priest.defaultPowerInfusionTarget()
+	}
 	powerInfusionAuras := priest.NewAllyAuraArray(func(unit *core.Unit) *core.Aura {
 		if unit.Type == core.PetUnit {
 			return nil
@@ -59,3 +62,16 @@ func (priest *Priest) registerPowerInfusionSpell() {
 		},
 	})
 }
+
+// Without an explicit target, Power Infusion goes to another caster in the
+// priest's party, then anywhere in the raid.
+func (priest *Priest) defaultPowerInfusionTarget() *core.Unit {
+	for _, party := range append([]*core.Party{priest.Party}, priest.Env.Raid.Parties...) {
+		for _, player := range party.Players {
+			if unit := &player.GetCharacter().Unit; unit != &priest.Unit && unit.HasManaBar() {
+				return unit
+			}
+		}
+	}
+	return nil
+}
@@ -8,6 +8,10 @@ import (
 
 var TalentTreeSizes = [3]int{21, 21, 21}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassPriest, TalentTreeSizes)
+}
+
 type Priest struct {
 	core.Character
 	SelfBuffs
@@ -81,6 +85,15 @@ func (priest *Priest) GetCharacter() *core.Character {
 // 		priest.Talents.ImprovedPowerWordFortitude == 2))
 // }
 
+func (priest *Priest) AddCompositionRaidBuffs(raidBuffs *proto.RaidBuffs) {
+	raidBuffs.PowerWordFortitude = true
+	raidBuffs.ShadowProtection = true
+
+	if priest.Talents.Shadowform {
+		raidBuffs.ShadowForm = true
+	}
+}
+
 func (priest *Priest) AddPartyBuffs(_ *proto.PartyBuffs) {
 }
 
@@ -0,0 +1,59 @@
+package shadow
+
+import (
+	"testing"
+
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+func powerInfusionUptime(buffsFromComposition bool) float64 {
+	newPriest := func() *proto.Player {
+		return &proto.Player{
+			Race:          proto.Race_RaceTroll,
+			Class:         proto.Class_ClassPriest,
+			Equipment:     core.GetGearSet("../../../ui/priest/shadow/gear_sets", "p1").GearSet,
+			Consumes:      FullConsumes,
+			Spec:          PlayerOptionsBasic,
+			Glyphs:        DefaultGlyphs,
+			TalentsString: "032212001--322032210201222100231",
+			Rotation:      core.GetAplRotation("../../../ui/priest/shadow/apls", "default").Rotation,
+		}
+	}
+
+	rsr := &proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{Players: []*proto.Player{newPriest(), newPriest()}},
+			},
+			BuffsFromComposition: buffsFromComposition,
+		},
+		Encounter: core.MakeSingleTargetEncounter(0),
+		SimOptions: &proto.SimOptions{
+			Iterations: 10,
+			RandomSeed: 101,
+		},
+	}
+
+	result := core.RunRaidSim(rsr)
+	if result.ErrorResult != "" {
+		panic(result.ErrorResult)
+	}
+
+	var uptime float64
+	for _, aura := range result.RaidMetrics.Parties[0].Players[1].Auras {
+		if aura.Id.GetSpellId() == 10060 {
+			uptime += aura.UptimeSecondsAvg
+		}
+	}
+	return uptime
+}
+
+func TestPowerInfusionFromComposition(t *testing.T) {
+	if uptime := powerInfusionUptime(true); uptime == 0 {
+		t.Errorf("expected Power Infusion on the other priest in the party")
+	}
+	if uptime := powerInfusionUptime(false); uptime != 0 {
+		t.Errorf("Power Infusion without a target should only be cast when buffs come from the composition, got %0.1fs uptime", uptime)
+	}
+}
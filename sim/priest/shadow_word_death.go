@@ -7,6 +7,17 @@ import (
 )
 
 func (priest *Priest) registerShadowWordDeathSpell() {
+	calcDamage := func(sim *core.Simulation, target *core.Unit, spell *core.Spell, outcomeApplier core.OutcomeApplier) *core.SpellResult {
+		if sim.IsExecutePhase25() {
+			spell.DamageMultiplier *= 3
+		}
+		result := spell.CalcDamage(sim, target, priest.ClassSpellScaling*0.357, outcomeApplier)
+		if sim.IsExecutePhase25() {
+			spell.DamageMultiplier /= 3
+		}
+		return result
+	}
+
 	priest.RegisterSpell(core.SpellConfig{
 		ActionID:       core.ActionID{SpellID: 32379},
 		SpellSchool:    core.SpellSchoolShadow,
@@ -35,23 +46,11 @@ func (priest *Priest) registerShadowWordDeathSpell() {
 			},
 		},
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-			if sim.IsExecutePhase25() {
-				spell.DamageMultiplier *= 3
-			}
-			spell.CalcAndDealDamage(sim, target, priest.ClassSpellScaling*0.357, spell.OutcomeMagicHitAndCrit)
-			if sim.IsExecutePhase25() {
-				spell.DamageMultiplier /= 3
-			}
+			result := calcDamage(sim, target, spell, spell.OutcomeMagicHitAndCrit)
+			spell.DealDamage(sim, result)
 		},
 		ExpectedInitialDamage: func(sim *core.Simulation, target *core.Unit, spell *core.Spell, _ bool) *core.SpellResult {
-			if sim.IsExecutePhase25() {
-				spell.DamageMultiplier *= 3
-			}
-			result := spell.CalcDamage(sim, target, priest.ClassSpellScaling*0.357, spell.OutcomeExpectedMagicHitAndCrit)
-			if sim.IsExecutePhase25() {
-				spell.DamageMultiplier /= 3
-			}
-			return result
+			return calcDamage(sim, target, spell, spell.OutcomeExpectedMagicHitAndCrit)
 		},
 	})
 }
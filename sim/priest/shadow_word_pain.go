@@ -7,6 +7,8 @@ import (
 )
 
 func (priest *Priest) registerShadowWordPainSpell() {
+	baseDamage := 194.709
+
 	priest.ShadowWordPain = priest.RegisterSpell(core.SpellConfig{
 		ActionID:       core.ActionID{SpellID: 589},
 		SpellSchool:    core.SpellSchoolShadow,
@@ -43,7 +45,7 @@ func (priest *Priest) registerShadowWordPainSpell() {
 			BonusCoefficient: 0.161,
 
 			OnSnapshot: func(sim *core.Simulation, target *core.Unit, dot *core.Dot, isRollover bool) {
-				dot.Snapshot(target, 194.709)
+				dot.Snapshot(target, baseDamage)
 			},
 			OnTick: func(sim *core.Simulation, target *core.Unit, dot *core.Dot) {
 				if priest.Talents.Shadowform {
@@ -68,7 +70,6 @@ func (priest *Priest) registerShadowWordPainSpell() {
 				dot := spell.Dot(target)
 				return dot.CalcSnapshotDamage(sim, target, dot.OutcomeExpectedMagicSnapshotCrit)
 			} else {
-				baseDamage := 194.709
 				return spell.CalcPeriodicDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicCrit)
 			}
 		},
@@ -12,6 +12,7 @@ func (priest *Priest) registerVampiricTouchSpell() {
 	}
 
 	replSrc := priest.Env.Raid.NewReplenishmentSource(core.ActionID{SpellID: 34914})
+	baseDamage := priest.ClassSpellScaling * 0.101
 
 	priest.VampiricTouch = priest.RegisterSpell(core.SpellConfig{
 		ActionID:       core.ActionID{SpellID: 34914},
@@ -51,7 +52,7 @@ func (priest *Priest) registerVampiricTouchSpell() {
 			BonusCoefficient: 0.352,
 
 			OnSnapshot: func(sim *core.Simulation, target *core.Unit, dot *core.Dot, _ bool) {
-				dot.Snapshot(target, priest.ClassSpellScaling*0.101)
+				dot.Snapshot(target, baseDamage)
 			},
 
 			OnTick: func(sim *core.Simulation, target *core.Unit, dot *core.Dot) {
@@ -76,7 +77,6 @@ func (priest *Priest) registerVampiricTouchSpell() {
 				dot := spell.Dot(target)
 				return dot.CalcSnapshotDamage(sim, target, dot.OutcomeExpectedMagicSnapshotCrit)
 			} else {
-				baseDamage := priest.ClassSpellScaling * 0.101
 				return spell.CalcPeriodicDamage(sim, target, baseDamage, spell.OutcomeExpectedMagicCrit)
 			}
 		},
@@ -16,6 +16,10 @@ const (
 
 var TalentTreeSizes = [3]int{19, 19, 19}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassRogue, TalentTreeSizes)
+}
+
 const RogueBleedTag = "RogueBleed"
 
 type Rogue struct {
@@ -19,6 +19,10 @@ func (rogue *Rogue) registerTricksOfTheTradeSpell() {
 	if rogue.Options.TricksOfTheTradeTarget != nil {
 		tottTarget = rogue.GetUnit(rogue.Options.TricksOfTheTradeTarget)
 	}
+	if tottTarget == nil && rogue.Env.Raid.BuffsFromComposition {
+		// Without an explicit target, Tricks goes to a party member.
+		tottTarget = rogue.Party.DefaultPartner(&rogue.Unit)
+	}
 
 	tricksOfTheTradeThreatTransferAura := rogue.GetOrRegisterAura(core.Aura{
 		ActionID: core.ActionID{SpellID: 59628},
@@ -14,12 +14,7 @@ func (shaman *Shaman) BloodlustActionID() core.ActionID {
 func (shaman *Shaman) registerBloodlustCD() {
 	actionID := shaman.BloodlustActionID()
 
-	blAuras := []*core.Aura{}
-	for _, party := range shaman.Env.Raid.Parties {
-		for _, partyMember := range party.Players {
-			blAuras = append(blAuras, core.BloodlustAura(partyMember.GetCharacter(), actionID.Tag))
-		}
-	}
+	blAuras := core.RaidBloodlustAuras(shaman.Env.Raid, actionID)
 
 	spell := shaman.RegisterSpell(core.SpellConfig{
 		ActionID: actionID,
@@ -40,22 +35,11 @@ func (shaman *Shaman) registerBloodlustCD() {
 		},
 		ExtraCastCondition: func(sim *core.Simulation, target *core.Unit) bool {
 			// Only cast if there is a player missing Sated.
-			for _, playerUnit := range shaman.Env.Raid.AllPlayerUnits {
-				if !playerUnit.HasActiveAura(core.SatedAuraLabel) {
-					return true
-				}
-			}
-			return false
+			return core.RaidMissingSated(shaman.Env.Raid)
 		},
 
 		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
-			for _, blAura := range blAuras {
-				target := blAura.Unit
-				// Only activate bloodlust on units without sated.
-				if !target.HasActiveAura(core.SatedAuraLabel) {
-					blAura.Activate(sim)
-				}
-			}
+			core.ActivateRaidBloodlust(sim, blAuras)
 		},
 	})
 
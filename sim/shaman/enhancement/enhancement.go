@@ -102,10 +102,12 @@ func (enh *EnhancementShaman) Initialize() {
 	enh.RegisterWindfuryImbue(enh.getImbueProcMask(proto.ShamanImbue_WindfuryWeapon))
 
 	if enh.ItemSwap.IsEnabled() {
-		mh := enh.ItemSwap.GetItem(proto.ItemSlot_ItemSlotMainHand)
-		enh.ApplyFlametongueImbueToItem(mh)
-		oh := enh.ItemSwap.GetItem(proto.ItemSlot_ItemSlotOffHand)
-		enh.ApplyFlametongueImbueToItem(oh)
+		for _, mh := range enh.ItemSwap.GetItems(proto.ItemSlot_ItemSlotMainHand) {
+			enh.ApplyFlametongueImbueToItem(mh)
+		}
+		for _, oh := range enh.ItemSwap.GetItems(proto.ItemSlot_ItemSlotOffHand) {
+			enh.ApplyFlametongueImbueToItem(oh)
+		}
 		enh.RegisterOnItemSwap(func(_ *core.Simulation) {
 			enh.ApplySyncType(proto.ShamanSyncType_Auto)
 		})
@@ -10,6 +10,10 @@ import (
 
 var TalentTreeSizes = [3]int{19, 19, 20}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassShaman, TalentTreeSizes)
+}
+
 // Start looking to refresh 5 minute totems at 4:55.
 const TotemRefreshTime5M = time.Second * 295
 
@@ -233,6 +237,35 @@ func (shaman *Shaman) AddRaidBuffs(raidBuffs *proto.RaidBuffs) {
 	}
 }
 
+// Totems only cover the shaman's party when buffs come from the composition.
+func (shaman *Shaman) AddPartyBuffs(partyBuffs *proto.PartyBuffs) {
+	if shaman.Totems.Fire != proto.FireTotem_NoFireTotem && shaman.Talents.TotemicWrath {
+		partyBuffs.TotemicWrath = true
+	}
+
+	if shaman.Totems.Fire == proto.FireTotem_FlametongueTotem {
+		partyBuffs.FlametongueTotem = true
+	}
+
+	if shaman.Totems.Water == proto.WaterTotem_ManaSpringTotem {
+		partyBuffs.ManaSpringTotem = true
+	}
+
+	switch shaman.Totems.Air {
+	case proto.AirTotem_WrathOfAirTotem:
+		partyBuffs.WrathOfAirTotem = true
+	case proto.AirTotem_WindfuryTotem:
+		partyBuffs.WindfuryTotem = true
+	}
+
+	switch shaman.Totems.Earth {
+	case proto.EarthTotem_StrengthOfEarthTotem:
+		partyBuffs.StrengthOfEarthTotem = true
+	case proto.EarthTotem_StoneskinTotem:
+		partyBuffs.StoneskinTotem = true
+	}
+}
+
 func (shaman *Shaman) Initialize() {
 	shaman.registerChainLightningSpell()
 	shaman.registerFireElementalTotem()
@@ -690,7 +690,16 @@ func (shaman *Shaman) registerManaTideTotemCD() {
 		return
 	}
 
-	mttAura := core.ManaTideTotemAura(shaman.GetCharacter(), shaman.Index)
+	// Mana Tide affects the shaman's own party, but without buffs from the
+	// composition the rest of the party is covered by the buff toggles.
+	mttAuras := []*core.Aura{core.ManaTideTotemAura(shaman.GetCharacter(), shaman.Index)}
+	if shaman.Env.Raid.BuffsFromComposition {
+		for _, agent := range shaman.Party.Players {
+			if agent.GetCharacter() != shaman.GetCharacter() {
+				mttAuras = append(mttAuras, core.ManaTideTotemAura(agent.GetCharacter(), shaman.Index))
+			}
+		}
+	}
 	mttSpell := shaman.RegisterSpell(core.SpellConfig{
 		ActionID: core.ManaTideTotemActionID,
 		Flags:    core.SpellFlagNoOnCastComplete,
@@ -705,7 +714,9 @@ func (shaman *Shaman) registerManaTideTotemCD() {
 			},
 		},
 		ApplyEffects: func(sim *core.Simulation, _ *core.Unit, _ *core.Spell) {
-			mttAura.Activate(sim)
+			for _, mttAura := range mttAuras {
+				mttAura.Activate(sim)
+			}
 
 			// If healing stream is active, cancel it while mana tide is up.
 			if shaman.HealingStreamTotem.Hot(&shaman.Unit).IsActive() {
@@ -0,0 +1,29 @@
+package warlock
+
+import (
+	"github.com/wowsims/cata/sim/core"
+	"github.com/wowsims/cata/sim/core/proto"
+)
+
+// Dark Intent is cast before the pull and lasts the whole fight, so it is
+// modeled as a permanent aura on both the warlock and its target.
+func (warlock *Warlock) registerDarkIntent() {
+	// Otherwise Dark Intent is covered by the buff toggles.
+	if !warlock.Env.Raid.BuffsFromComposition {
+		return
+	}
+
+	target := warlock.GetUnit(warlock.Options.DarkIntentTarget)
+	if target == nil || target == &warlock.Unit || target.Type != core.PlayerUnit {
+		return
+	}
+
+	// Two warlocks may pick the same target; the buff doesn't stack.
+	if target.GetAura("Dark Intent") == nil {
+		targetIsWarlock := warlock.Env.Raid.GetPlayerFromUnit(target).GetCharacter().Class == proto.Class_ClassWarlock
+		core.MakePermanent(core.DarkIntentAura(target, targetIsWarlock))
+	}
+	if warlock.GetAura("Dark Intent") == nil {
+		core.MakePermanent(core.DarkIntentAura(&warlock.Unit, true))
+	}
+}
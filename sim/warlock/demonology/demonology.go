@@ -69,6 +69,13 @@ func (demonology *DemonologyWarlock) AddRaidBuffs(raidBuffs *proto.RaidBuffs) {
 	raidBuffs.DemonicPact = demonology.Talents.DemonicPact && demonology.Options.Summon != proto.WarlockOptions_NoSummon
 }
 
+func (demonology *DemonologyWarlock) AddCompositionRaidBuffs(raidBuffs *proto.RaidBuffs) {
+	demonology.Warlock.AddCompositionRaidBuffs(raidBuffs)
+	if demonology.Talents.DemonicPact && demonology.Options.Summon != proto.WarlockOptions_NoSummon {
+		raidBuffs.DemonicPact = true
+	}
+}
+
 func (demonology *DemonologyWarlock) Reset(sim *core.Simulation) {
 	demonology.Warlock.Reset(sim)
 }
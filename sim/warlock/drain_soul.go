@@ -28,6 +28,11 @@ func (warlock *Warlock) calcSoulSiphonMult(target *core.Unit) float64 {
 
 // TODO: Check damage and coefficients
 func (warlock *Warlock) registerDrainSoul() {
+	calcTickDamage := func(target *core.Unit, spell *core.Spell) float64 {
+		baseDmg := warlock.CalcScalingSpellDmg(0.07999999821) + 0.37799999118*spell.SpellPower()
+		return baseDmg * warlock.calcSoulSiphonMult(target)
+	}
+
 	warlock.RegisterSpell(core.SpellConfig{
 		ActionID:       core.ActionID{SpellID: 1120},
 		SpellSchool:    core.SpellSchoolShadow,
@@ -52,8 +57,7 @@ func (warlock *Warlock) registerDrainSoul() {
 			AffectedByCastSpeed: true,
 
 			OnSnapshot: func(sim *core.Simulation, target *core.Unit, dot *core.Dot, isRollover bool) {
-				baseDmg := warlock.CalcScalingSpellDmg(0.07999999821) + 0.37799999118*dot.Spell.SpellPower()
-				dot.SnapshotBaseDamage = baseDmg * warlock.calcSoulSiphonMult(target)
+				dot.SnapshotBaseDamage = calcTickDamage(target, dot.Spell)
 				dot.SnapshotCritChance = dot.Spell.SpellCritChance(target)
 				dot.SnapshotAttackerMultiplier = dot.Spell.AttackerDamageMultiplier(dot.Spell.Unit.AttackTables[target.UnitIndex], true)
 			},
@@ -76,9 +80,7 @@ func (warlock *Warlock) registerDrainSoul() {
 				dot := spell.Dot(target)
 				return dot.CalcSnapshotDamage(sim, target, spell.OutcomeExpectedMagicCrit)
 			} else {
-				baseDmg := warlock.CalcScalingSpellDmg(0.07999999821) + 0.37799999118*spell.SpellPower()
-				baseDmg *= warlock.calcSoulSiphonMult(target)
-				return spell.CalcPeriodicDamage(sim, target, baseDmg, spell.OutcomeExpectedMagicCrit)
+				return spell.CalcPeriodicDamage(sim, target, calcTickDamage(target, spell), spell.OutcomeExpectedMagicCrit)
 			}
 		},
 	})
@@ -6,6 +6,12 @@ import (
 	"github.com/wowsims/cata/sim/core/stats"
 )
 
+var TalentTreeSizes = [3]int{18, 19, 19}
+
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassWarlock, TalentTreeSizes)
+}
+
 type Warlock struct {
 	core.Character
 	Talents *proto.WarlockTalents
@@ -65,6 +71,7 @@ func (warlock *Warlock) Initialize() {
 	warlock.registerCurseOfElements()
 	warlock.registerCurseOfTongues()
 	warlock.registerCurseOfWeakness()
+	warlock.registerDarkIntent()
 	warlock.registerDemonSoul()
 	warlock.registerDrainLife()
 	warlock.registerDrainSoul()
@@ -110,7 +117,7 @@ func (warlock *Warlock) Initialize() {
 		// if itemswap is enabled, correct for any possible haste changes
 		var correction stats.Stats
 		if warlock.ItemSwap.IsEnabled() {
-			correction = warlock.ItemSwap.CalcStatChanges([]proto.ItemSlot{proto.ItemSlot_ItemSlotMainHand,
+			correction = warlock.ItemSwap.CalcStatChanges(proto.APLActionItemSwap_Swap1.String(), []proto.ItemSlot{proto.ItemSlot_ItemSlotMainHand,
 				proto.ItemSlot_ItemSlotOffHand, proto.ItemSlot_ItemSlotRanged})
 
 			warlock.AddStats(correction)
@@ -124,6 +131,17 @@ func (warlock *Warlock) AddRaidBuffs(raidBuffs *proto.RaidBuffs) {
 	raidBuffs.FelIntelligence = warlock.Options.Summon == proto.WarlockOptions_Felhunter
 }
 
+// The toggle assignments above would clobber the buffs of other warlocks in the
+// raid, so reapply this warlock's pet buff on top of them.
+func (warlock *Warlock) AddCompositionRaidBuffs(raidBuffs *proto.RaidBuffs) {
+	if warlock.Options.Summon == proto.WarlockOptions_Imp {
+		raidBuffs.BloodPact = true
+	}
+	if warlock.Options.Summon == proto.WarlockOptions_Felhunter {
+		raidBuffs.FelIntelligence = true
+	}
+}
+
 func (warlock *Warlock) Reset(sim *core.Simulation) {
 	warlock.SoulShards = 3
 }
@@ -134,7 +152,7 @@ func NewWarlock(character *core.Character, options *proto.Player, warlockOptions
 		Talents:   &proto.WarlockTalents{},
 		Options:   warlockOptions,
 	}
-	core.FillTalentsProto(warlock.Talents.ProtoReflect(), options.TalentsString, [3]int{18, 19, 19})
+	core.FillTalentsProto(warlock.Talents.ProtoReflect(), options.TalentsString, TalentTreeSizes)
 	warlock.EnableManaBar()
 
 	warlock.AddStatDependency(stats.Strength, stats.AttackPower, 1)
@@ -10,6 +10,10 @@ import (
 
 var TalentTreeSizes = [3]int{20, 21, 20}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassWarrior, TalentTreeSizes)
+}
+
 type WarriorInputs struct {
 	StanceSnapshot bool
 }
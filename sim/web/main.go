@@ -25,6 +25,7 @@ import (
 	"github.com/wowsims/cata/sim"
 	"github.com/wowsims/cata/sim/core"
 	proto "github.com/wowsims/cata/sim/core/proto"
+	"github.com/wowsims/cata/sim/core/simsettings"
 
 	googleProto "google.golang.org/protobuf/proto"
 )
@@ -100,6 +101,21 @@ var handlers = map[string]apiHandler{
 	"/computeStats": {msg: func() googleProto.Message { return &proto.ComputeStatsRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
 		return core.ComputeStats(msg.(*proto.ComputeStatsRequest))
 	}},
+	"/gearSearch": {msg: func() googleProto.Message { return &proto.GearSearchRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunGearSearch(msg.(*proto.GearSearchRequest))
+	}},
+	"/bossLoot": {msg: func() googleProto.Message { return &proto.BossLootRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.RunBossLoot(msg.(*proto.BossLootRequest))
+	}},
+	"/validateRequest": {msg: func() googleProto.Message { return &proto.RaidSimRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.ValidateRequest(msg.(*proto.RaidSimRequest))
+	}},
+	"/convertSettings": {msg: func() googleProto.Message { return &proto.ConvertSettingsRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return simsettings.Convert(msg.(*proto.ConvertSettingsRequest))
+	}},
+	"/effectCoverage": {msg: func() googleProto.Message { return &proto.EffectCoverageRequest{} }, handle: func(msg googleProto.Message) googleProto.Message {
+		return core.EffectCoverage(msg.(*proto.EffectCoverageRequest))
+	}},
 }
 
 var asyncAPIHandlers = map[string]asyncAPIHandler{
@@ -4,6 +4,7 @@ import {
 	APLAction,
 	APLActionActivateAura,
 	APLActionAutocastOtherCooldowns,
+	APLActionCallActionList,
 	APLActionCancelAura,
 	APLActionCastFriendlySpell,
 	APLActionCastSpell,
@@ -18,12 +19,15 @@ import {
 	APLActionMultidot,
 	APLActionMultishield,
 	APLActionResetSequence,
+	APLActionRunActionList,
 	APLActionSchedule,
 	APLActionSequence,
 	APLActionStrictSequence,
 	APLActionTriggerICD,
 	APLActionWait,
 	APLActionWaitUntil,
+	APLTargetIf,
+	APLTargetIf_Mode as TargetIfMode,
 	APLValue,
 } from '../../proto/apl.js';
 import { Spec } from '../../proto/common.js';
@@ -269,6 +273,39 @@ function itemSwapSetFieldConfig(field: string): AplHelpers.APLPickerBuilderField
 	};
 }
 
+function targetIfFieldConfig(field: string): AplHelpers.APLPickerBuilderFieldConfig<any, any> {
+	return {
+		field: field,
+		label: 'Target If',
+		labelTooltip: 'Chooses the target among all candidates: the first for which the value is true, or the one with the lowest or highest value. Use the Candidate unit to refer to the target being considered.',
+		newValue: APLTargetIf.create,
+		factory: (parent, player, config) =>
+			new AplHelpers.APLPickerBuilder(parent, player, {
+				...config,
+				newValue: APLTargetIf.create,
+				fields: [
+					{
+						field: 'mode',
+						newValue: () => TargetIfMode.ModeFirst,
+						factory: (parent, player, config) =>
+							new TextDropdownPicker(parent, player, {
+								id: randomUUID(),
+								...config,
+								defaultLabel: 'None',
+								equals: (a, b) => a == b,
+								values: [
+									{ value: TargetIfMode.ModeFirst, label: 'First' },
+									{ value: TargetIfMode.ModeMin, label: 'Min' },
+									{ value: TargetIfMode.ModeMax, label: 'Max' },
+								],
+							}),
+					},
+					AplValues.valueFieldConfig('value'),
+				],
+			}),
+	};
+}
+
 function actionFieldConfig(field: string): AplHelpers.APLPickerBuilderFieldConfig<any, any> {
 	return {
 		field: field,
@@ -336,13 +373,21 @@ const actionKindFactories: { [f in NonNullable<APLActionKind>]: ActionKindConfig
 		label: 'Cast',
 		shortDescription: 'Casts the spell if possible, i.e. resource/cooldown/GCD/etc requirements are all met.',
 		newValue: APLActionCastSpell.create,
-		fields: [AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', ''), AplHelpers.unitFieldConfig('target', 'targets')],
+		fields: [
+			AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', ''),
+			AplHelpers.unitFieldConfig('target', 'targets'),
+			targetIfFieldConfig('targetIf'),
+		],
 	}),
 	['castFriendlySpell']: inputBuilder({
 		label: 'Cast at Player',
 		shortDescription: 'Casts a friendly spell if possible, i.e. resource/cooldown/GCD/etc requirements are all met.',
 		newValue: APLActionCastFriendlySpell.create,
-		fields: [AplHelpers.actionIdFieldConfig('spellId', 'friendly_spells', ''), AplHelpers.unitFieldConfig('target', 'players')],
+		fields: [
+			AplHelpers.actionIdFieldConfig('spellId', 'friendly_spells', ''),
+			AplHelpers.unitFieldConfig('target', 'players'),
+			targetIfFieldConfig('targetIf'),
+		],
 		includeIf: (player: Player<any>, _isPrepull: boolean) => player.getRaid()!.size() > 1,
 	}),
 	['multidot']: inputBuilder({
@@ -533,6 +578,22 @@ const actionKindFactories: { [f in NonNullable<APLActionKind>]: ActionKindConfig
 		newValue: APLActionStrictSequence.create,
 		fields: [actionListFieldConfig('actions')],
 	}),
+	['callActionList']: inputBuilder({
+		label: 'Call Action List',
+		submenu: ['Action Lists'],
+		shortDescription: 'Executes the first ready action of the named action list, or moves on to the next action if none are ready.',
+		includeIf: (player: Player<any>, isPrepull: boolean) => !isPrepull,
+		newValue: APLActionCallActionList.create,
+		fields: [AplHelpers.stringFieldConfig('listName')],
+	}),
+	['runActionList']: inputBuilder({
+		label: 'Run Action List',
+		submenu: ['Action Lists'],
+		shortDescription: 'Executes the first ready action of the named action list. Actions after this one are never evaluated.',
+		includeIf: (player: Player<any>, isPrepull: boolean) => !isPrepull,
+		newValue: APLActionRunActionList.create,
+		fields: [AplHelpers.stringFieldConfig('listName')],
+	}),
 	['changeTarget']: inputBuilder({
 		label: 'Change Target',
 		submenu: ['Misc'],
@@ -567,9 +628,18 @@ const actionKindFactories: { [f in NonNullable<APLActionKind>]: ActionKindConfig
 		label: 'Item Swap',
 		submenu: ['Misc'],
 		shortDescription: 'Swaps items, using the swap set specified in Settings.',
+		fullDescription: `
+			<p>If a <b>Set Name</b> is given, swaps to the named set instead. Named sets may swap any slot, but sets with items other than weapons can only be swapped to before combat.</p>
+		`,
 		includeIf: (player: Player<any>, _isPrepull: boolean) => itemSwapEnabledSpecs.includes(player.getSpec()),
 		newValue: () => APLActionItemSwap.create(),
-		fields: [itemSwapSetFieldConfig('swapSet')],
+		fields: [
+			itemSwapSetFieldConfig('swapSet'),
+			AplHelpers.stringFieldConfig('setName', {
+				label: 'Set Name',
+				labelTooltip: 'Name of a swap set from Settings. Overrides the set above.',
+			}),
+		],
 	}),
 	['move']: inputBuilder({
 		label: 'Move',
@@ -323,6 +323,7 @@ const unitSets: Record<
 					.asList()
 					.map((petMetadata, i) => UnitReference.create({ type: UnitType.Pet, index: i, owner: UnitReference.create({ type: UnitType.Self }) })),
 				UnitReference.create({ type: UnitType.CurrentTarget }),
+				UnitReference.create({ type: UnitType.Candidate }),
 				player.sim.raid
 					.getActivePlayers()
 					.filter(filter => filter != player)
@@ -336,6 +337,7 @@ const unitSets: Record<
 		getUnits: player => {
 			return [
 				undefined,
+				UnitReference.create({ type: UnitType.Candidate }),
 				player.sim.encounter.targetsMetadata.asList().map((targetMetadata, i) => UnitReference.create({ type: UnitType.Target, index: i })),
 				UnitReference.create({ type: UnitType.Self }),
 				player
@@ -350,6 +352,7 @@ const unitSets: Record<
 		getUnits: player => {
 			return [
 				undefined,
+				UnitReference.create({ type: UnitType.Candidate }),
 				player.sim.encounter.targetsMetadata.asList().map((_targetMetadata, i) => UnitReference.create({ type: UnitType.Target, index: i })),
 			].flat();
 		},
@@ -410,6 +413,12 @@ export class APLUnitPicker extends UnitPicker<Player<any>> {
 				iconUrl: 'fa-bullseye',
 				text: 'Current Target',
 			};
+		} else if (ref.type == UnitType.Candidate) {
+			return {
+				value: ref,
+				iconUrl: 'fa-bullseye',
+				text: 'Candidate',
+			};
 		} else if (ref.type == UnitType.Player) {
 			const player = thisPlayer.sim.raid.getPlayer(ref.index);
 			if (player) {
@@ -6,6 +6,7 @@ import { ActionId } from '../../proto_utils/action_id';
 import { SimUI } from '../../sim_ui';
 import { EventID, TypedEvent } from '../../typed_event';
 import { existsInDOM, randomUUID } from '../../utils';
+import { BooleanPicker } from '../boolean_picker';
 import { Component } from '../component';
 import { Input, InputConfig } from '../input';
 import { ListItemPickerConfig, ListPicker } from '../list_picker';
@@ -17,6 +18,19 @@ export class APLRotationPicker extends Component {
 	constructor(parent: HTMLElement, simUI: SimUI, modPlayer: Player<any>) {
 		super(parent, 'apl-rotation-picker-root');
 
+		new BooleanPicker<Player<any>>(this.rootElem, modPlayer, {
+			id: 'apl-auto-prepull-timing',
+			label: 'Auto Prepull Timing',
+			labelTooltip:
+				"Times prepull actions with an empty 'Do At' automatically from their cast times, GCDs and travel times, so they happen back to back in order and the last one lands at pull.",
+			changedEvent: (player: Player<any>) => player.rotationChangeEmitter,
+			getValue: (player: Player<any>) => player.aplRotation.autoPrepullTiming,
+			setValue: (eventID: EventID, player: Player<any>, newValue: boolean) => {
+				player.aplRotation.autoPrepullTiming = newValue;
+				player.rotationChangeEmitter.emit(eventID);
+			},
+		});
+
 		new ListPicker<Player<any>, APLPrepullAction>(this.rootElem, modPlayer, {
 			extraCssClasses: ['apl-prepull-action-picker'],
 			title: 'Prepull Actions',
@@ -97,6 +111,7 @@ class APLPrepullActionPicker extends Input<Player<any>, APLPrepullAction> {
 
 		const itemHeaderElem = ListPicker.getItemHeaderElem(this);
 		makeListItemWarnings(itemHeaderElem, player, player => player.getCurrentStats().rotationStats?.prepullActions[index]?.warnings || []);
+		makePrepullScheduledTime(itemHeaderElem, player, index);
 
 		this.hidePicker = new HidePicker(itemHeaderElem, player, {
 			changedEvent: () => this.player.rotationChangeEmitter,
@@ -110,7 +125,8 @@ class APLPrepullActionPicker extends Input<Player<any>, APLPrepullAction> {
 		this.doAtPicker = new AdaptiveStringPicker(this.rootElem, this.player, {
 			id: randomUUID(),
 			label: 'Do At',
-			labelTooltip: "Time before pull to do the action. Should be negative, and formatted like, '-1s' or '-2500ms'.",
+			labelTooltip:
+				"Time before pull to do the action. Should be negative, and formatted like, '-1s' or '-2500ms'. Leave empty to time the action automatically when Auto Prepull Timing is enabled.",
 			extraCssClasses: ['apl-prepull-actions-doat'],
 			changedEvent: () => this.player.rotationChangeEmitter,
 			getValue: () => (this.getItem().doAtValue?.value as APLValueImplStruct<'const'> | undefined)?.const.val || '',
@@ -278,6 +294,25 @@ function makeListItemWarnings(itemHeaderElem: HTMLElement, player: Player<any>,
 	player.currentStatsEmitter.on(updateWarnings);
 }
 
+// Shows the time computed for an auto timed prepull action.
+function makePrepullScheduledTime(itemHeaderElem: HTMLElement, player: Player<any>, index: number) {
+	const timeElem = document.createElement('span');
+	timeElem.classList.add('apl-prepull-scheduled-time');
+	itemHeaderElem.appendChild(timeElem);
+
+	const updateTime = () => {
+		if (!existsInDOM(timeElem)) {
+			timeElem?.remove();
+			player.currentStatsEmitter.off(updateTime);
+			return;
+		}
+		const entry = player.getCurrentStats().rotationStats?.prepullSchedule.find(entry => entry.prepullActionIdx == index);
+		timeElem.textContent = entry?.autoTimed ? `Auto: ${entry.doAtSeconds.toFixed(2)}s` : '';
+	};
+	updateTime();
+	player.currentStatsEmitter.on(updateTime);
+}
+
 class HidePicker extends Input<Player<any>, boolean> {
 	private readonly inputElem: HTMLElement;
 	private readonly iconElem: HTMLElement;
@@ -46,6 +46,7 @@ import {
 	APLValueGCDTimeToReady,
 	APLValueInputDelay,
 	APLValueIsExecutePhase,
+	APLValueIsExecutePhaseCustom,
 	APLValueIsExecutePhase_ExecutePhaseThreshold as ExecutePhaseThreshold,
 	APLValueMath,
 	APLValueMath_MathOperator as MathOperator,
@@ -57,6 +58,10 @@ import {
 	APLValueOr,
 	APLValueRemainingTime,
 	APLValueRemainingTimePercent,
+	APLValueResourceAtTime,
+	APLValueResourceDeficit,
+	APLValueResourceTimeToAmount,
+	APLValueResourceType as ResourceType,
 	APLValueRuneCooldown,
 	APLValueRuneSlotCooldown,
 	APLValueSequenceIsComplete,
@@ -67,13 +72,17 @@ import {
 	APLValueSpellChanneledTicks,
 	APLValueSpellCPM,
 	APLValueSpellCurrentCost,
+	APLValueSpellDpet,
+	APLValueSpellExpectedDamage,
 	APLValueSpellIsChanneling,
 	APLValueSpellIsKnown,
 	APLValueSpellIsReady,
 	APLValueSpellTimeToReady,
 	APLValueSpellTravelTime,
+	APLValueTimeToDie,
 	APLValueTotemRemainingTime,
 	APLValueUnitIsMoving,
+	APLValueVariable,
 	APLValueWarlockShouldRecastDrainSoul,
 	APLValueWarlockShouldRefreshCorruption,
 } from '../../proto/apl.js';
@@ -381,6 +390,30 @@ function totemTypeFieldConfig(field: string): AplHelpers.APLPickerBuilderFieldCo
 	};
 }
 
+function resourceTypeFieldConfig(field: string): AplHelpers.APLPickerBuilderFieldConfig<any, any> {
+	return {
+		field: field,
+		newValue: () => ResourceType.ResourceUnknown,
+		factory: (parent, player, config) =>
+			new TextDropdownPicker(parent, player, {
+				id: randomUUID(),
+				...config,
+				defaultLabel: 'None',
+				equals: (a, b) => a == b,
+				values: [
+					{ value: ResourceType.ResourceMana, label: 'Mana' },
+					{ value: ResourceType.ResourceEnergy, label: 'Energy' },
+					{ value: ResourceType.ResourceRage, label: 'Rage' },
+					{ value: ResourceType.ResourceFocus, label: 'Focus' },
+					{ value: ResourceType.ResourceRunicPower, label: 'Runic Power' },
+					{ value: ResourceType.ResourceBloodRunes, label: 'Blood Runes' },
+					{ value: ResourceType.ResourceFrostRunes, label: 'Frost Runes' },
+					{ value: ResourceType.ResourceUnholyRunes, label: 'Unholy Runes' },
+				],
+			}),
+	};
+}
+
 export function valueFieldConfig(
 	field: string,
 	options?: Partial<AplHelpers.APLPickerBuilderFieldConfig<any, any>>,
@@ -552,6 +585,35 @@ const valueKindFactories: { [f in NonNullable<APLValueKind>]: ValueKindConfig<AP
 		newValue: APLValueIsExecutePhase.create,
 		fields: [executePhaseThresholdFieldConfig('threshold')],
 	}),
+	isExecutePhaseCustom: inputBuilder({
+		label: 'Is Execute Phase (Custom)',
+		submenu: ['Encounter'],
+		shortDescription: "<b>True</b> if the given target's health is at or below the given percentage, otherwise <b>False</b>.",
+		fullDescription: `
+		<p>In fights using target health, this uses the damage taken by the target. Otherwise the health is interpolated from the encounter's execute proportions.</p>
+		`,
+		newValue: () =>
+			APLValueIsExecutePhaseCustom.create({
+				threshold: 20,
+			}),
+		fields: [AplHelpers.unitFieldConfig('targetUnit', 'targets'), AplHelpers.numberFieldConfig('threshold', true, { label: 'Threshold (%)' })],
+	}),
+	timeToDie: inputBuilder({
+		label: 'Time to Die',
+		submenu: ['Encounter'],
+		shortDescription: 'Estimated time until the given target dies.',
+		fullDescription: `
+		<p>The estimate is extrapolated from the target's health over the last 15 seconds, the same way time-to-die addons work. Until there is enough data, or if the target's health isn't dropping, this is the remaining fight duration.</p>
+		<p>With <b>Use Oracle</b> the sim's knowledge of when the fight ends is used instead, which is exact in fights using a fixed duration.</p>
+		`,
+		newValue: APLValueTimeToDie.create,
+		fields: [
+			AplHelpers.unitFieldConfig('targetUnit', 'targets'),
+			AplHelpers.booleanFieldConfig('useOracle', 'Use Oracle', {
+				labelTooltip: "Use the sim's knowledge of when the fight ends instead of estimating from recent damage.",
+			}),
+		],
+	}),
 	numberTargets: inputBuilder({
 		label: 'Number of Targets',
 		submenu: ['Encounter'],
@@ -660,6 +722,43 @@ const valueKindFactories: { [f in NonNullable<APLValueKind>]: ValueKindConfig<AP
 		includeIf: (player: Player<any>, _isPrepull: boolean) => player.getClass() == Class.ClassDeathKnight,
 		fields: [],
 	}),
+	resourceTimeToAmount: inputBuilder({
+		label: 'Time to Resource',
+		submenu: ['Resources', 'Forecast'],
+		shortDescription: 'Amount of time until regen brings the resource to the given amount, or 0 if there is already enough.',
+		fullDescription: `
+		<p>Regen includes haste-scaled Energy and Focus ticks, Mana ticks and Rune regen. Rage is forecast from upcoming auto attacks, scaled by their chance to not be missed, dodged or parried. Runic Power has no passive regen.</p>
+		<p>For Runes, the amount is the number of Runes of that type, including any converted to Death Runes.</p>
+		`,
+		newValue: () =>
+			APLValueResourceTimeToAmount.create({
+				resource: ResourceType.ResourceEnergy,
+			}),
+		fields: [resourceTypeFieldConfig('resource'), valueFieldConfig('amount')],
+	}),
+	resourceAtTime: inputBuilder({
+		label: 'Resource After Time',
+		submenu: ['Resources', 'Forecast'],
+		shortDescription: 'Amount of the resource after the given time, from regen alone.',
+		fullDescription: `
+		<p>Regen includes haste-scaled Energy and Focus ticks, Mana ticks and Rune regen. Rage is forecast from upcoming auto attacks, scaled by their chance to not be missed, dodged or parried. Runic Power has no passive regen.</p>
+		`,
+		newValue: () =>
+			APLValueResourceAtTime.create({
+				resource: ResourceType.ResourceEnergy,
+			}),
+		fields: [resourceTypeFieldConfig('resource'), valueFieldConfig('time')],
+	}),
+	resourceDeficit: inputBuilder({
+		label: 'Resource Deficit',
+		submenu: ['Resources', 'Forecast'],
+		shortDescription: 'Amount of the resource missing from its maximum.',
+		newValue: () =>
+			APLValueResourceDeficit.create({
+				resource: ResourceType.ResourceEnergy,
+			}),
+		fields: [resourceTypeFieldConfig('resource')],
+	}),
 	currentLunarEnergy: inputBuilder({
 		label: 'Solar Energy',
 		submenu: ['Eclipse'],
@@ -791,6 +890,26 @@ const valueKindFactories: { [f in NonNullable<APLValueKind>]: ValueKindConfig<AP
 		newValue: APLValueSpellCurrentCost.create,
 		fields: [AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', '')],
 	}),
+	spellExpectedDamage: inputBuilder({
+		label: 'Expected Damage',
+		submenu: ['Spell'],
+		shortDescription: 'Expected damage of casting the spell on the target right now, with hit and crit chance averaged in.',
+		fullDescription: `
+		<p>Includes every tick of the spell's DoT or channel, at the current haste. Only supported for spells with an expected damage calculation.</p>
+		`,
+		newValue: APLValueSpellExpectedDamage.create,
+		fields: [AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', ''), AplHelpers.unitFieldConfig('targetUnit', 'targets')],
+	}),
+	spellDpet: inputBuilder({
+		label: 'Damage Per Execute Time',
+		submenu: ['Spell'],
+		shortDescription: 'Expected damage of casting the spell, divided by the seconds spent on the GCD, cast or channel.',
+		fullDescription: `
+		<p>Useful for choosing the best filler at the moment, for example between <b>Fire Blast</b> and <b>Scorch</b> while moving.</p>
+		`,
+		newValue: APLValueSpellDpet.create,
+		fields: [AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', ''), AplHelpers.unitFieldConfig('targetUnit', 'targets')],
+	}),
 	spellCanCast: inputBuilder({
 		label: 'Can Cast',
 		submenu: ['Spell'],
@@ -990,6 +1109,18 @@ const valueKindFactories: { [f in NonNullable<APLValueKind>]: ValueKindConfig<AP
 		fields: [AplHelpers.stringFieldConfig('sequenceName')],
 	}),
 
+	// Variables
+	variable: inputBuilder({
+		label: 'Variable',
+		submenu: ['Variables'],
+		shortDescription: 'Returns the value of the named rotation variable.',
+		fullDescription: `
+			<p>Variables are computed at most once per rotation decision, so a condition shared by many actions only needs to be written once.</p>
+		`,
+		newValue: APLValueVariable.create,
+		fields: [AplHelpers.stringFieldConfig('name')],
+	}),
+
 	// Class/spec specific values
 	totemRemainingTime: inputBuilder({
 		label: 'Totem Remaining Time',
@@ -127,6 +127,17 @@ export class ActionId {
 				baseName = 'Moving';
 				iconUrl = 'https://wow.zamimg.com/images/wow/icons/medium/inv_boots_cloth_03.jpg';
 				break;
+			case OtherAction.OtherActionItemSwap:
+				name = 'Item Swap';
+				iconUrl = 'https://wow.zamimg.com/images/wow/icons/large/inv_misc_bag_10.jpg';
+				if (tag == 0) {
+					name += ' (Main)';
+				} else if (tag == 1) {
+					name += ' (Swapped)';
+				} else {
+					name += ` (Set ${tag})`;
+				}
+				break;
 		}
 		this.baseName = baseName;
 		this.name = name || baseName;
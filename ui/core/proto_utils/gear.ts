@@ -1,4 +1,4 @@
-import { EquipmentSpec, GemColor, ItemSlot, ItemSpec, ItemSwap, Profession, SimDatabase, SimEnchant, SimGem, SimItem } from '../proto/common.js';
+import { EquipmentSpec, GemColor, ItemSlot, ItemSpec, ItemSwap, ItemSwapSet, Profession, SimDatabase, SimEnchant, SimGem, SimItem } from '../proto/common.js';
 import { UIEnchant as Enchant, UIGem as Gem, UIItem as Item } from '../proto/ui.js';
 import { isBluntWeaponType, isSharpWeaponType } from '../proto_utils/utils.js';
 import { distinct, equalsOrBothNull, getEnumValues } from '../utils.js';
@@ -350,8 +350,12 @@ export class Gear extends BaseGear {
  * This is an immutable type.
  */
 export class ItemSwapGear extends BaseGear {
-	constructor(gear: Partial<InternalGear>) {
+	// Sets from Settings which may swap any slot, kept as specs since they aren't edited here.
+	readonly namedSets: ItemSwapSet[];
+
+	constructor(gear: Partial<InternalGear>, namedSets: ItemSwapSet[] = []) {
 		super(gear);
+		this.namedSets = namedSets;
 	}
 
 	getItemSlots(): ItemSlot[] {
@@ -359,7 +363,7 @@ export class ItemSwapGear extends BaseGear {
 	}
 
 	withEquippedItem(newSlot: ItemSlot, newItem: EquippedItem | null, canDualWield2H: boolean): ItemSwapGear {
-		return new ItemSwapGear(this.withEquippedItemInternal(newSlot, newItem, canDualWield2H));
+		return new ItemSwapGear(this.withEquippedItemInternal(newSlot, newItem, canDualWield2H), this.namedSets);
 	}
 
 	toProto(): ItemSwap {
@@ -367,6 +371,7 @@ export class ItemSwapGear extends BaseGear {
 			mhItem: this.gear[ItemSlot.ItemSlotMainHand]?.asSpec(),
 			ohItem: this.gear[ItemSlot.ItemSlotOffHand]?.asSpec(),
 			rangedItem: this.gear[ItemSlot.ItemSlotRanged]?.asSpec(),
+			namedSets: this.namedSets,
 		});
 	}
 }
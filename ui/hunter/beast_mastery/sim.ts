@@ -148,6 +148,7 @@ const SPEC_CONFIG = registerSpecConfig(Spec.SpecBeastMasteryHunter, {
 	otherInputs: {
 		inputs: [
 			HunterInputs.PetUptime(),
+			HunterInputs.ModelPetHealth(),
 			OtherInputs.InputDelay,
 			OtherInputs.DistanceFromTarget,
 			OtherInputs.TankAssignment,
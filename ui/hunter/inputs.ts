@@ -16,3 +16,10 @@ export const PetUptime = <SpecType extends HunterSpecs>() =>
 		labelTooltip: 'Percent of the fight duration for which your pet will be alive.',
 		percent: true,
 	});
+
+export const ModelPetHealth = <SpecType extends HunterSpecs>() =>
+	InputHelpers.makeClassOptionsBooleanInput<SpecType>({
+		fieldName: 'modelPetHealth',
+		label: 'Model Pet Health',
+		labelTooltip: 'Boss abilities also damage your pet, which can die and must be revived.',
+	});
@@ -141,6 +141,7 @@ const SPEC_CONFIG = registerSpecConfig(Spec.SpecSurvivalHunter, {
 	otherInputs: {
 		inputs: [
 			HunterInputs.PetUptime(),
+			HunterInputs.ModelPetHealth(),
 			SVInputs.SniperTrainingUptime,
 			OtherInputs.InputDelay,
 			OtherInputs.DistanceFromTarget,